@@ -1,14 +1,20 @@
 package main
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
+	"math/rand/v2"
 	"net/http"
-	"time"
+	"sync"
+	"sync/atomic"
+	"wails-template/internal/cache"
+	"wails-template/internal/clock"
 	"wails-template/internal/config"
+	"wails-template/internal/logger"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+	"golang.org/x/sync/singleflight"
 )
 
 // LoginRequest represents the login request payload
@@ -37,7 +43,7 @@ type LoginResponse struct {
 
 // User represents the user object from API
 type User struct {
-	ID              string   `json:"id"`
+	ID              ID       `json:"id"`
 	Username        string   `json:"username"`
 	Name            string   `json:"name"`
 	Email           string   `json:"email"`
@@ -45,24 +51,190 @@ type User struct {
 	Roles           []string `json:"roles"`
 	Scopes          []string `json:"scopes"`
 	CreatedAt       string   `json:"created_at"`
-	CurrentTenantID string   `json:"current_tenant_id"`
+	CurrentTenantID ID       `json:"current_tenant_id"`
 }
 
 // App struct
 type App struct {
 	ctx    context.Context
-	config *config.Config
+	clock  clock.Clock
+	logger *logger.Logger
+	hooks  requestHooks
+
+	// configMu guards the live configuration and what is built from it:
+	// config, client, slots and messages. Reloads swap them together under
+	// the write lock; everything else reads them through currentConfig,
+	// currentClient, currentSlots and currentMessages. reloadMu serializes
+	// the reloads themselves.
+	configMu sync.RWMutex
+	reloadMu sync.Mutex
+	config   *config.Config
+	client   httpClient
+
+	// randInt64N returns a random number in [0, n) for retry jitter,
+	// rand.Int64N outside tests
+	randInt64N func(n int64) int64
+
+	// audit records runtime config changes. It is nil when log.audit_file
+	// is empty.
+	audit *logger.AuditLog
+
+	// emit sends events to the frontend, runtime.EventsEmit outside tests
+	emit func(ctx context.Context, name string, data ...any)
+
+	// cache holds GET responses for conditional revalidation. It is nil
+	// when caching is disabled.
+	cache *cache.Cache
+
+	// slots bounds the API requests in flight, guarded by configMu.
+	// inFlight and waiting count the requests holding and waiting for a
+	// slot.
+	slots    *requestSlots
+	inFlight atomic.Int64
+	waiting  atomic.Int64
+
+	// metrics records failed and retried API attempts
+	metrics apiMetrics
+
+	// endpoints balances the requests over api.base_urls
+	endpoints endpointPool
+
+	// limiter spaces the API requests when security.rate_limit_enabled is
+	// set
+	limiter rateLimiter
+
+	// messages resolves the user-facing messages of API error codes,
+	// guarded by configMu
+	messages config.MessageCatalog
+
+	// recorder writes the API exchanges to api.record_to and replay serves
+	// them from api.replay_from instead of the network. Each is nil when
+	// not configured.
+	recorder *requestRecorder
+	replay   *replaySession
+
+	// requests and downloads hold the cancel functions of operations the
+	// frontend can abort
+	requests  cancelRegistry
+	downloads cancelRegistry
+
+	// customClient is set when the client was injected and must not be
+	// rebuilt on reload
+	customClient bool
+	// certs holds the TLS certificates of the built client, reloaded with
+	// the config. It is nil for an injected client.
+	certs *certificateStore
+
+	// exitCode is returned from main once the app quits
+	exitCode int
+
+	// done is cancelled by Close to stop background goroutines
+	done      context.Context
+	stop      context.CancelFunc
+	workers   sync.WaitGroup
+	closeOnce sync.Once
+	closeErr  error
+
+	// closing is set once Close has begun, after which no new background
+	// goroutines are started
+	closeMu sync.Mutex
+	closing bool
+
+	sessionMu    sync.RWMutex
+	session      *session
+	stopRefresh  context.CancelFunc
+	refreshGroup singleflight.Group
+	requestGroup singleflight.Group
+}
+
+// httpClient sends HTTP requests. It is satisfied by *http.Client and lets
+// tests substitute the transport.
+type httpClient interface {
+	Do(req *http.Request) (*http.Response, error)
 }
 
 // NewApp creates a new App application struct
 func NewApp() *App {
+	return NewAppWithClient(nil)
+}
+
+// NewAppWithClient creates an App that sends its API requests through
+// client. A nil client uses the HTTP client built from the configuration.
+// A given client is kept across config reloads.
+func NewAppWithClient(client httpClient) *App {
 	cfg, err := config.LoadConfig()
 	if err != nil {
 		panic(fmt.Sprintf("Failed to load config: %v", err))
 	}
 
+	customClient := client != nil
+	var certs *certificateStore
+	if !customClient {
+		if certs, err = newCertificateStore(cfg.TLS); err != nil {
+			panic(fmt.Sprintf("Failed to load TLS certificates: %v", err))
+		}
+		if client, err = newHTTPClient(cfg, certs); err != nil {
+			panic(fmt.Sprintf("Failed to create HTTP client: %v", err))
+		}
+	}
+
+	log, err := logger.New(cfg.Log)
+	if err != nil {
+		panic(fmt.Sprintf("Failed to create logger: %v", err))
+	}
+
+	var audit *logger.AuditLog
+	if cfg.Log.AuditFile != "" {
+		if audit, err = logger.OpenAudit(cfg.Log.AuditFile); err != nil {
+			panic(fmt.Sprintf("Failed to create audit log: %v", err))
+		}
+	}
+
+	var recorder *requestRecorder
+	if cfg.API.RecordTo != "" {
+		if recorder, err = openRecorder(cfg.API.RecordTo, cfg.API, cfg.Auth); err != nil {
+			panic(fmt.Sprintf("Failed to create request recorder: %v", err))
+		}
+	}
+	var replay *replaySession
+	if cfg.API.ReplayFrom != "" {
+		if replay, err = loadReplay(cfg.API.ReplayFrom); err != nil {
+			panic(fmt.Sprintf("Failed to load request replay: %v", err))
+		}
+	}
+
+	clk := clock.New()
+	var responseCache *cache.Cache
+	if cfg.Cache.Enabled {
+		policy, err := cache.ParsePolicy(cfg.Cache.EvictionPolicy)
+		if err != nil {
+			panic(fmt.Sprintf("Failed to create response cache: %v", err))
+		}
+		opts := []cache.Option{cache.WithMaxItems(cfg.Cache.MaxItems), cache.WithPolicy(policy)}
+		if cfg.Cache.ServeStaleOnError {
+			opts = append(opts, cache.WithStaleFor(cfg.Cache.MaxStale))
+		}
+		responseCache = cache.New(cfg.Cache.TTL, clk, opts...)
+	}
+
+	done, stop := context.WithCancel(context.Background())
 	return &App{
-		config: cfg,
+		config:       cfg,
+		client:       client,
+		customClient: customClient,
+		certs:        certs,
+		clock:        clk,
+		randInt64N:   rand.Int64N,
+		logger:       log,
+		messages:     loadMessages(cfg, log),
+		audit:        audit,
+		recorder:     recorder,
+		replay:       replay,
+		emit:         runtime.EventsEmit,
+		cache:        responseCache,
+		slots:        newRequestSlots(cfg.API.MaxConcurrent),
+		done:         done,
+		stop:         stop,
 	}
 }
 
@@ -70,84 +242,180 @@ func NewApp() *App {
 // so we can call the runtime methods
 func (a *App) startup(ctx context.Context) {
 	a.ctx = ctx
+
+	if err := a.placeWindow(wailsWindow{ctx: ctx}, a.currentConfig().Window); err != nil {
+		a.logger.Warn("Failed to place window", "error", err)
+	}
+	a.watchConfig()
+	a.watchTheme()
+	if !a.runSelfTest() {
+		runtime.Quit(ctx)
+	}
 }
 
-// Greet returns a greeting for the given name
-func (a *App) Greet(name string) string {
-	return fmt.Sprintf("Hello %s, It's show time!", name)
+// startupContext returns the context bounding the remote startup checks,
+// which is done once app.startup_timeout has passed or the app is closed
+func (a *App) startupContext() (context.Context, context.CancelFunc) {
+	cfg := a.currentConfig()
+	if cfg.App.StartupTimeout <= 0 {
+		return context.WithCancel(a.done)
+	}
+	return context.WithTimeout(a.done, cfg.App.StartupTimeout)
 }
 
-// Login performs authentication with the external API
-func (a *App) Login(username, password string) (*LoginResponse, error) {
-	// Create login request payload
-	loginReq := LoginRequest{
-		Username: username,
-		Password: password,
+// domReady is called once the frontend has loaded and can receive events.
+// It pushes the public configuration as config:initial, sparing the
+// frontend a GetConfig call at startup.
+func (a *App) domReady(ctx context.Context) {
+	if a.currentConfig().App.PushInitialConfig {
+		a.emitEvent("config:initial", a.GetConfig())
 	}
+}
 
-	// Convert to JSON
-	jsonData, err := json.Marshal(loginReq)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal login request: %v", err)
+// runSelfTest runs the self-test within the startup budget and logs failed
+// checks. It reports false and sets a non-zero exit code when a check
+// failed and fail-fast is enabled, in which case startup must be aborted.
+// Abandoned checks don't abort startup. Remote checks that can't abort it
+// run in the background so they don't hold up the window.
+func (a *App) runSelfTest() bool {
+	cfg := a.currentConfig()
+	var blocking, background []selfTestCheck
+	for _, c := range a.selfTestChecks() {
+		if c.remote && !(c.critical && cfg.App.FailFast) {
+			background = append(background, c)
+		} else {
+			blocking = append(blocking, c)
+		}
 	}
+	a.runBackgroundChecks(background)
 
-	// Build login URL from config
-	loginURL := fmt.Sprintf("%s/identity/login", a.config.API.BaseURL)
+	ctx, cancel := a.startupContext()
+	defer cancel()
 
-	// Create HTTP request
-	req, err := http.NewRequest("POST", loginURL, bytes.NewBuffer(jsonData))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %v", err)
+	result := a.selfTest(ctx, blocking)
+	if result.Passed {
+		return true
 	}
 
-	// Set headers
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("User-Agent", a.config.API.UserAgent)
+	for _, check := range result.Checks {
+		if check.Critical && !check.Passed && !check.Abandoned {
+			a.logger.Error("Self-test failed", "check", check.Name, "message", check.Message)
+		}
+	}
+	if cfg.App.FailFast {
+		a.exitCode = 1
+		return false
+	}
+	return true
+}
 
-	// Create HTTP client with timeout from config
-	client := &http.Client{
-		Timeout: a.config.API.Timeout,
+// shutdown is called when the app is about to quit
+func (a *App) shutdown(ctx context.Context) {
+	if err := a.Close(); err != nil {
+		fmt.Printf("Failed to close app: %v\n", err)
 	}
+}
 
-	// Send request with retry logic
-	var resp *http.Response
-	var lastErr error
+// Close stops all background goroutines started by the app and releases
+// its resources. It is safe to call multiple times.
+func (a *App) Close() error {
+	a.closeOnce.Do(func() {
+		a.closeMu.Lock()
+		a.closing = true
+		a.closeMu.Unlock()
 
-	for attempt := 0; attempt <= a.config.API.RetryCount; attempt++ {
-		resp, lastErr = client.Do(req)
-		if lastErr == nil && resp.StatusCode < 500 {
-			break // Success or client error (don't retry)
+		a.stop()
+		a.workers.Wait()
+		if a.cache != nil {
+			a.cache.Close()
 		}
+		a.closeErr = errors.Join(a.logger.Close(), a.audit.Close(), a.recorder.Close())
+	})
+	return a.closeErr
+}
 
-		if attempt < a.config.API.RetryCount {
-			// Wait before retry
-			time.Sleep(a.config.API.RetryDelay)
-		}
+// goBackground runs fn in a goroutine that is tracked by Close. The context
+// passed to fn is cancelled when the app is closed. It reports false without
+// running fn once Close has begun.
+func (a *App) goBackground(fn func(ctx context.Context)) bool {
+	a.closeMu.Lock()
+	defer a.closeMu.Unlock()
+	if a.closing {
+		return false
 	}
 
-	if lastErr != nil {
-		return nil, fmt.Errorf("failed to send request after %d attempts: %v", a.config.API.RetryCount+1, lastErr)
-	}
-	defer resp.Body.Close()
+	a.workers.Add(1)
+	go func() {
+		defer a.workers.Done()
+		fn(a.done)
+	}()
+	return true
+}
 
-	// Read response body
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %v", err)
-	}
+// requestContext returns the context for requests issued by bound methods,
+// carrying a new request ID. It is cancelled on shutdown so in-flight
+// requests abort instead of holding up the exit.
+func (a *App) requestContext() context.Context {
+	return a.requestContextWithID(newRequestID())
+}
+
+// requestContextWithID returns a request context carrying the request ID
+// supplied by the frontend
+func (a *App) requestContextWithID(id string) context.Context {
+	return withRequestID(a.done, id)
+}
 
-	// Parse response
-	var loginResp LoginResponse
-	if err := json.Unmarshal(body, &loginResp); err != nil {
-		return nil, fmt.Errorf("failed to parse response: %v", err)
+// emitEvent emits a Wails event once the runtime context is available
+func (a *App) emitEvent(name string, data ...any) {
+	if a.ctx != nil {
+		a.emit(a.ctx, name, data...)
 	}
+}
+
+// Greet returns a greeting for the given name
+func (a *App) Greet(name string) string {
+	return fmt.Sprintf("Hello %s, It's show time!", name)
+}
+
+// Login performs authentication with the external API
+func (a *App) Login(username, password string) (_ *LoginResponse, err error) {
+	defer a.recoverPanic("Login", &err)
+
+	return a.login(a.requestContext(), username, password)
+}
+
+// LoginWithID performs authentication like Login. The login can be aborted
+// with CancelRequest(requestID), in which case ErrRequestCancelled is
+// returned. requestID is also sent as the X-Request-ID of the login call.
+func (a *App) LoginWithID(requestID, username, password string) (_ *LoginResponse, err error) {
+	defer a.recoverPanic("LoginWithID", &err)
+
+	ctx, release := a.requests.register(a.requestContextWithID(requestID), requestID)
+	defer release()
+
+	resp, err := a.login(ctx, username, password)
+	return resp, cancelledError(ctx, err)
+}
+
+// CancelRequest aborts the request started with the given ID, reporting
+// whether it was still in progress
+func (a *App) CancelRequest(requestID string) bool {
+	return a.requests.cancel(requestID)
+}
 
-	// Check if login was successful
-	if !loginResp.Success {
-		return nil, fmt.Errorf("login failed: %s", loginResp.Message)
+// login authenticates with the configured strategy and starts a session
+func (a *App) login(ctx context.Context, username, password string) (*LoginResponse, error) {
+	strategy, err := a.authStrategy()
+	if err != nil {
+		return nil, err
+	}
+	tokens, err := strategy.Authenticate(ctx, Credentials{Username: username, Password: password})
+	if err != nil {
+		return nil, err
 	}
 
-	return &loginResp, nil
+	a.startSession(tokens)
+	return &LoginResponse{Success: true, StatusCode: http.StatusOK, Data: *tokens}, nil
 }
 
 // GetConfig returns the public configuration for frontend
@@ -155,37 +423,181 @@ func (a *App) GetConfig() *config.PublicConfig {
 	return config.GetPublicConfig()
 }
 
-// GetAPIBaseURL returns the API base URL
+// GetConfigValue returns a single configuration value by dotted path, such
+// as "api.timeout" or "window.width". Durations are returned in seconds and
+// secrets are denied.
+func (a *App) GetConfigValue(path string) (_ any, err error) {
+	defer a.recoverPanic("GetConfigValue", &err)
+
+	return config.LookupValue(a.currentConfig(), path)
+}
+
+// ExportConfigAsEnv returns the configuration as "export NAME=value" lines
+// named like the environment overrides, with prefix or the override prefix
+// in effect. Secrets are masked unless includeSecrets is set, which is
+// ignored in production.
+func (a *App) ExportConfigAsEnv(prefix string, includeSecrets bool) string {
+	cfg := a.currentConfig()
+	return config.ExportEnv(cfg, prefix, includeSecrets && !cfg.App.Environment.IsProduction())
+}
+
+// ValidateConfigSection validates the values of a single config section,
+// such as the fields of one settings tab, without applying them
+func (a *App) ValidateConfigSection(name string, values map[string]any) []config.FieldError {
+	return config.ValidateSection(name, values)
+}
+
+// GetConfigConstraints returns the validation constraints of every config
+// value by dotted path, for building settings forms
+func (a *App) GetConfigConstraints() map[string]config.Constraint {
+	return config.FieldConstraints()
+}
+
+// GetConfigHealth returns the validation errors and the security and
+// environment warnings of the current configuration with an overall status
+func (a *App) GetConfigHealth() *config.ConfigHealthReport {
+	return config.CheckHealth(a.currentConfig())
+}
+
+// GetAPIBaseURL returns the API base URL, resolved for the current tenant
 func (a *App) GetAPIBaseURL() string {
-	return a.config.API.BaseURL
+	return a.baseURL()
+}
+
+// IsOriginAllowed reports whether origin is allowed by the configured CORS
+// rules, using the same matcher as the CORS middleware
+func (a *App) IsOriginAllowed(origin string) bool {
+	return a.currentConfig().Security.IsOriginAllowed(origin)
+}
+
+// GetRecentLogs returns the log records kept in memory at or above the given
+// level, oldest first. An empty or unknown level returns all records.
+func (a *App) GetRecentLogs(level string) []logger.Record {
+	minLevel, _ := logger.ParseLevel(level)
+	return a.logger.Recent(minLevel)
 }
 
 // GetEnvironment returns the current environment
 func (a *App) GetEnvironment() string {
-	return string(a.config.App.Environment)
+	return string(a.currentConfig().App.Environment)
 }
 
 // IsDebugMode returns whether debug mode is enabled
 func (a *App) IsDebugMode() bool {
-	return a.config.App.Debug
+	return a.currentConfig().App.Debug
 }
 
-// GetAppInfo returns basic app information
-func (a *App) GetAppInfo() map[string]any {
-	return map[string]any{
-		"name":        a.config.App.Name,
-		"version":     a.config.App.Version,
-		"environment": a.config.App.Environment,
-		"debug":       a.config.App.Debug,
-	}
+// ReloadConfig reloads the configuration (useful for development) and
+// emits config:reloaded. A configuration that fails to load is rejected
+// with config:reload-failed and the current one stays in effect.
+func (a *App) ReloadConfig() (err error) {
+	defer a.recoverPanic("ReloadConfig", &err)
+
+	return a.reloadConfig(auditReload, triggerUser)
+}
+
+// SwitchProfile reloads the configuration with the overrides of the named
+// [profile.<name>] section applied, like ReloadConfig. An empty name
+// switches back to the base configuration. Profiles can't be switched in
+// production.
+func (a *App) SwitchProfile(name string) (err error) {
+	defer a.recoverPanic("SwitchProfile", &err)
+
+	return a.replaceConfig(auditSwitchProfile, triggerUser, func() (*config.Config, error) {
+		return config.SwitchProfile(name)
+	})
+}
+
+// GetProfiles returns the names of the profiles defined in the config file
+func (a *App) GetProfiles() []string {
+	return config.Profiles()
+}
+
+// reloadConfig reloads the configuration like ReloadConfig and records the
+// outcome in the audit log as action, started by trigger. The audit file
+// itself is only opened at startup.
+func (a *App) reloadConfig(action, trigger string) error {
+	return a.replaceConfig(action, trigger, config.ReloadConfig)
+}
+
+// currentConfig returns the configuration in effect. A reload replaces it
+// with a new one rather than changing it, so the result stays consistent
+// while it is used.
+func (a *App) currentConfig() *config.Config {
+	a.configMu.RLock()
+	defer a.configMu.RUnlock()
+	return a.config
 }
 
-// ReloadConfig reloads the configuration (useful for development)
-func (a *App) ReloadConfig() error {
-	cfg, err := config.ReloadConfig()
+// currentClient returns the client built for the configuration in effect
+func (a *App) currentClient() httpClient {
+	a.configMu.RLock()
+	defer a.configMu.RUnlock()
+	return a.client
+}
+
+// currentSlots returns the request slots of the configuration in effect
+func (a *App) currentSlots() *requestSlots {
+	a.configMu.RLock()
+	defer a.configMu.RUnlock()
+	return a.slots
+}
+
+// currentMessages returns the message catalog of the configuration in
+// effect
+func (a *App) currentMessages() config.MessageCatalog {
+	a.configMu.RLock()
+	defer a.configMu.RUnlock()
+	return a.messages
+}
+
+// replaceConfig makes the configuration returned by load the live one, like
+// reloadConfig
+func (a *App) replaceConfig(action, trigger string, load func() (*config.Config, error)) error {
+	a.reloadMu.Lock()
+	defer a.reloadMu.Unlock()
+
+	cfg, err := load()
 	if err != nil {
+		a.auditConfigChange(action, trigger, nil, err)
+		a.reloadFailed(err)
 		return err
 	}
-	a.config = cfg
+
+	// Only reloads change the fields guarded by configMu, so they can be
+	// read without it while reloadMu is held
+	client, slots := a.client, a.slots
+	if !a.customClient {
+		// The store is shared with the previous client, so its requests
+		// still in flight use the reloaded certificates too
+		if err := a.certs.load(cfg.TLS); err != nil {
+			a.auditConfigChange(action, trigger, nil, err)
+			return err
+		}
+		client, err = newHTTPClient(cfg, a.certs)
+		if err != nil {
+			a.auditConfigChange(action, trigger, nil, err)
+			return err
+		}
+	}
+	if cfg.API.MaxConcurrent != slots.limit {
+		// Requests in flight release the slots they acquired
+		slots = newRequestSlots(cfg.API.MaxConcurrent)
+	}
+	messages := loadMessages(cfg, a.logger)
+	changes := config.Diff(a.config, cfg)
+	a.auditConfigChange(action, trigger, changes, nil)
+	a.logConfigChanges(changes)
+
+	a.configMu.Lock()
+	previous := a.client
+	a.config, a.client, a.slots, a.messages = cfg, client, slots, messages
+	a.configMu.Unlock()
+
+	// Idle connections were made with the previous certificates
+	if previous, ok := previous.(*http.Client); ok && previous != client {
+		previous.CloseIdleConnections()
+	}
+	a.emitEvent("config:reloaded")
 	return nil
 }
@@ -0,0 +1,371 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+	"wails-template/internal/config"
+)
+
+// newTestApp creates an App whose API requests go to a test server running
+// handler. The config is a copy of the loaded one, so tests may change it.
+func newTestApp(t testing.TB, handler http.Handler) (*App, *httptest.Server) {
+	t.Helper()
+
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	app := NewAppWithClient(srv.Client())
+	t.Cleanup(func() { app.Close() })
+
+	cfg := *app.config
+	cfg.API.BaseURL = srv.URL
+	cfg.API.Timeout = 5 * time.Second
+	cfg.API.RetryCount = 0
+	cfg.API.RetryDelay = 0
+	cfg.API.DefaultHeaders = nil
+	cfg.API.TenantURLTemplate = ""
+	app.config = &cfg
+	return app, srv
+}
+
+// blockingHandler returns a handler that holds every request until the
+// test ends or the client goes away. started receives a value once a
+// request has arrived.
+func blockingHandler(t *testing.T) (http.Handler, <-chan struct{}) {
+	t.Helper()
+
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	t.Cleanup(func() { close(release) })
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The server only notices a client going away once the body has
+		// been read
+		io.Copy(io.Discard, r.Body)
+
+		select {
+		case started <- struct{}{}:
+		default:
+		}
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}), started
+}
+
+func TestLoginWithIDCancelBeforeResponse(t *testing.T) {
+	handler, started := blockingHandler(t)
+	app, _ := newTestApp(t, handler)
+
+	go func() {
+		<-started
+		app.CancelRequest("login-1")
+	}()
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := app.LoginWithID("login-1", "admin", "secret")
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, ErrRequestCancelled) {
+			t.Fatalf("LoginWithID error = %v, want ErrRequestCancelled", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("LoginWithID did not return after CancelRequest")
+	}
+
+	if app.CancelRequest("login-1") {
+		t.Error("CancelRequest found an entry after the login returned")
+	}
+}
+
+func TestCancelRequestUnknownID(t *testing.T) {
+	app, _ := newTestApp(t, http.NotFoundHandler())
+	if app.CancelRequest("missing") {
+		t.Error("CancelRequest reported an unknown request as cancelled")
+	}
+}
+
+// loginResponseJSON is a successful login response with a one hour token
+const loginResponseJSON = `{"success":true,"data":{"access_token":"access-1","expires_in":3600,"token_type":"Bearer","refresh_token":"refresh-1","user":{"id":"u1","username":"admin"}}}`
+
+// loginHandler answers /identity/login with loginResponseJSON
+func loginHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/identity/login", func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, loginResponseJSON)
+	})
+	return mux
+}
+
+func TestCloseStopsBackgroundGoroutines(t *testing.T) {
+	srv := httptest.NewServer(loginHandler())
+	defer srv.Close()
+	client := srv.Client()
+
+	before := runtime.NumGoroutine()
+	for range 10 {
+		app := NewAppWithClient(client)
+		cfg := *app.config
+		cfg.API.BaseURL = srv.URL
+		app.config = &cfg
+
+		if _, err := app.Login("admin", "secret"); err != nil {
+			t.Fatalf("Login: %v", err)
+		}
+		if err := app.Close(); err != nil {
+			t.Fatalf("Close: %v", err)
+		}
+		if err := app.Close(); err != nil {
+			t.Fatalf("second Close: %v", err)
+		}
+	}
+	client.CloseIdleConnections()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if after := runtime.NumGoroutine(); after > before {
+		t.Errorf("goroutines before = %d, after Close = %d", before, after)
+	}
+}
+
+func TestLoginAfterCloseStartsNoWorker(t *testing.T) {
+	app, _ := newTestApp(t, loginHandler())
+	app.Close()
+
+	// The request context is cancelled, but a session started anyway must
+	// not register a worker with the closed app
+	app.startSession(&LoginData{AccessToken: "access", RefreshToken: "refresh"})
+	if app.stopRefresh != nil {
+		t.Error("refresh scheduler started after Close")
+	}
+}
+
+func TestCloseCancelsInFlightRequests(t *testing.T) {
+	calls := map[string]func(app *App) error{
+		"login": func(app *App) error {
+			_, err := app.Login("admin", "secret")
+			return err
+		},
+		"shared GET": func(app *App) error {
+			_, err := app.Request(APIRequest{Method: http.MethodGet, Path: "/items"})
+			return err
+		},
+	}
+	for name, call := range calls {
+		t.Run(name, func(t *testing.T) {
+			handler, started := blockingHandler(t)
+			app, _ := newTestApp(t, handler)
+			app.config.API.Timeout = time.Minute
+
+			done := make(chan error, 1)
+			go func() { done <- call(app) }()
+			<-started
+
+			closed := time.Now()
+			app.Close()
+			select {
+			case err := <-done:
+				if !errors.Is(err, context.Canceled) {
+					t.Errorf("error = %v, want context.Canceled", err)
+				}
+				if elapsed := time.Since(closed); elapsed > time.Second {
+					t.Errorf("request returned %v after Close", elapsed)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("request kept running after Close")
+			}
+		})
+	}
+}
+
+// stubClient records the requests it receives and answers each with a
+// canned response
+type stubClient struct {
+	requests []*http.Request
+	bodies   []string
+	status   int
+	body     string
+}
+
+func (c *stubClient) Do(req *http.Request) (*http.Response, error) {
+	c.requests = append(c.requests, req)
+	var body []byte
+	if req.Body != nil {
+		body, _ = io.ReadAll(req.Body)
+	}
+	c.bodies = append(c.bodies, string(body))
+	return &http.Response{
+		StatusCode: c.status,
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(c.body)),
+		Request:    req,
+	}, nil
+}
+
+func TestLoginWithStubClient(t *testing.T) {
+	stub := &stubClient{status: http.StatusOK, body: loginResponseJSON}
+	app := NewAppWithClient(stub)
+	t.Cleanup(func() { app.Close() })
+
+	cfg := *app.config
+	cfg.API.BaseURL = "https://api.example.test/v1"
+	cfg.API.UserAgent = "Test-Agent/1.0"
+	cfg.API.DefaultHeaders = nil
+	app.config = &cfg
+
+	resp, err := app.Login("admin", "secret")
+	if err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	if resp.Data.AccessToken != "access-1" {
+		t.Errorf("access token = %q", resp.Data.AccessToken)
+	}
+
+	if len(stub.requests) != 1 {
+		t.Fatalf("requests = %d, want 1", len(stub.requests))
+	}
+	req := stub.requests[0]
+	if req.Method != http.MethodPost || req.URL.String() != "https://api.example.test/v1/identity/login" {
+		t.Errorf("request = %s %s", req.Method, req.URL)
+	}
+	if req.Header.Get("Content-Type") != "application/json" || req.Header.Get("User-Agent") != "Test-Agent/1.0" {
+		t.Errorf("headers = %v", req.Header)
+	}
+	if req.Header.Get("Authorization") != "" {
+		t.Error("login request carries an Authorization header")
+	}
+	if want := `{"username":"admin","password":"secret"}`; stub.bodies[0] != want {
+		t.Errorf("body = %s, want %s", stub.bodies[0], want)
+	}
+}
+
+func TestLoginWithStubClientError(t *testing.T) {
+	stub := &stubClient{status: http.StatusUnauthorized, body: `{"success":false,"message":"invalid credentials"}`}
+	app := NewAppWithClient(stub)
+	t.Cleanup(func() { app.Close() })
+
+	_, err := app.Login("admin", "wrong")
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized {
+		t.Errorf("error = %v, want an APIError with status 401", err)
+	}
+}
+
+func TestReloadConfigKeepsInjectedClient(t *testing.T) {
+	stub := &stubClient{status: http.StatusOK, body: `{}`}
+	app := NewAppWithClient(stub)
+	t.Cleanup(func() { app.Close() })
+
+	if err := app.ReloadConfig(); err != nil {
+		t.Fatalf("ReloadConfig: %v", err)
+	}
+	if app.client != stub {
+		t.Error("ReloadConfig replaced the injected client")
+	}
+}
+
+// emittedEvent is an event sent to the frontend
+type emittedEvent struct {
+	name string
+	data []any
+}
+
+// recordEvents makes app record the events it emits instead of sending
+// them to the frontend
+func recordEvents(app *App) *[]emittedEvent {
+	var events []emittedEvent
+	app.ctx = context.Background()
+	app.emit = func(ctx context.Context, name string, data ...any) {
+		events = append(events, emittedEvent{name: name, data: data})
+	}
+	return &events
+}
+
+func TestDomReadyPushesInitialConfig(t *testing.T) {
+	app, _ := newTestApp(t, http.NotFoundHandler())
+	events := recordEvents(app)
+
+	app.config.App.PushInitialConfig = true
+	app.domReady(app.ctx)
+	if len(*events) != 1 || (*events)[0].name != "config:initial" {
+		t.Fatalf("events = %+v, want config:initial", *events)
+	}
+	if data := (*events)[0].data; len(data) != 1 || !reflect.DeepEqual(data[0], config.GetPublicConfig()) {
+		t.Errorf("payload = %+v, want %+v", data, config.GetPublicConfig())
+	}
+
+	*events = nil
+	app.config.App.PushInitialConfig = false
+	app.domReady(app.ctx)
+	if len(*events) != 0 {
+		t.Errorf("events with push_initial_config disabled = %+v, want none", *events)
+	}
+}
+
+func TestSwitchProfile(t *testing.T) {
+	path := useConfigCopy(t)
+	profiles := "\n[profile.local]\napi.base_url = http://localhost:8080\nwindow.width = 900\n"
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteString(profiles); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	if _, err := config.ReloadConfig(); err != nil {
+		t.Fatalf("ReloadConfig: %v", err)
+	}
+	// Runs before useConfigCopy restores the repository config
+	t.Cleanup(func() { config.SwitchProfile("") })
+
+	app, _ := newTestApp(t, http.NotFoundHandler())
+	events := recordEvents(app)
+	baseURL := config.GetConfig().API.BaseURL
+
+	if got := app.GetProfiles(); !reflect.DeepEqual(got, []string{"local"}) {
+		t.Errorf("GetProfiles = %q, want [local]", got)
+	}
+	if err := app.SwitchProfile("local"); err != nil {
+		t.Fatalf("SwitchProfile: %v", err)
+	}
+	if app.config.API.BaseURL != "http://localhost:8080" || app.config.Window.Width != 900 {
+		t.Errorf("base URL %q, window width %d, want the profile values", app.config.API.BaseURL, app.config.Window.Width)
+	}
+	if got := app.GetConfig().App.Profile; got != "local" {
+		t.Errorf("public profile = %q, want local", got)
+	}
+	if len(*events) != 1 || (*events)[0].name != "config:reloaded" {
+		t.Errorf("events = %+v, want config:reloaded", *events)
+	}
+
+	if err := app.SwitchProfile("missing"); !errors.Is(err, config.ErrUnknownProfile) {
+		t.Errorf("unknown profile error = %v, want ErrUnknownProfile", err)
+	}
+	if app.config.API.BaseURL != "http://localhost:8080" || app.GetConfig().App.Profile != "local" {
+		t.Errorf("unknown profile changed the config: base URL %q, profile %q", app.config.API.BaseURL, app.GetConfig().App.Profile)
+	}
+
+	if err := app.SwitchProfile(""); err != nil {
+		t.Fatalf("SwitchProfile back to base: %v", err)
+	}
+	if app.config.API.BaseURL != baseURL || app.GetConfig().App.Profile != "" {
+		t.Errorf("base URL %q, profile %q, want the base configuration", app.config.API.BaseURL, app.GetConfig().App.Profile)
+	}
+}
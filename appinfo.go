@@ -0,0 +1,78 @@
+package main
+
+import (
+	"runtime"
+	"wails-template/internal/config"
+)
+
+// Build metadata, set at build time with -ldflags "-X main.BuildCommit=..."
+var (
+	// BuildCommit is the commit the binary was built from
+	BuildCommit = "unknown"
+	// BuildTime is the time the binary was built
+	BuildTime = "unknown"
+	// BuildVersion is the released version. When set it is displayed
+	// instead of app.version from the config.
+	BuildVersion = ""
+)
+
+// BuildInfo describes how the binary was built
+type BuildInfo struct {
+	Commit    string `json:"commit"`
+	Time      string `json:"time"`
+	Version   string `json:"version"`
+	GoVersion string `json:"goVersion"`
+}
+
+// AppInfo describes the running application
+type AppInfo struct {
+	Name        string             `json:"name"`
+	Version     string             `json:"version"`
+	Environment config.Environment `json:"environment"`
+	Debug       bool               `json:"debug"`
+	BuildCommit string             `json:"buildCommit"`
+	BuildTime   string             `json:"buildTime"`
+	GoVersion   string             `json:"goVersion"`
+	OS          string             `json:"os"`
+	Arch        string             `json:"arch"`
+}
+
+// GetAppInfo returns basic app information
+func (a *App) GetAppInfo() *AppInfo {
+	cfg := a.currentConfig()
+	return &AppInfo{
+		Name:        cfg.App.Name,
+		Version:     a.displayVersion(),
+		Environment: cfg.App.Environment,
+		Debug:       cfg.App.Debug,
+		BuildCommit: BuildCommit,
+		BuildTime:   BuildTime,
+		GoVersion:   runtime.Version(),
+		OS:          runtime.GOOS,
+		Arch:        runtime.GOARCH,
+	}
+}
+
+// GetBuildInfo returns the build metadata of the binary. The version is
+// "dev" for builds without BuildVersion.
+func (a *App) GetBuildInfo() *BuildInfo {
+	version := BuildVersion
+	if version == "" {
+		version = "dev"
+	}
+	return &BuildInfo{
+		Commit:    BuildCommit,
+		Time:      BuildTime,
+		Version:   version,
+		GoVersion: runtime.Version(),
+	}
+}
+
+// displayVersion returns the version shown to users, preferring the build
+// version over the configured one
+func (a *App) displayVersion() string {
+	if BuildVersion != "" {
+		return BuildVersion
+	}
+	return a.currentConfig().App.Version
+}
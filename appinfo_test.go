@@ -0,0 +1,80 @@
+package main
+
+import (
+	"net/http"
+	"reflect"
+	"runtime"
+	"testing"
+	"wails-template/internal/config"
+)
+
+func TestGetAppInfo(t *testing.T) {
+	app, _ := newTestApp(t, http.NotFoundHandler())
+	app.config.App.Name = "Test App"
+	app.config.App.Version = "1.2.3"
+	app.config.App.Environment = config.Staging
+	app.config.App.Debug = true
+
+	info := app.GetAppInfo()
+
+	value := reflect.ValueOf(*info)
+	for i := range value.NumField() {
+		if value.Field(i).IsZero() {
+			t.Errorf("AppInfo.%s is not populated", value.Type().Field(i).Name)
+		}
+	}
+	want := AppInfo{
+		Name:        "Test App",
+		Version:     "1.2.3",
+		Environment: config.Staging,
+		Debug:       true,
+		BuildCommit: BuildCommit,
+		BuildTime:   BuildTime,
+		GoVersion:   runtime.Version(),
+		OS:          runtime.GOOS,
+		Arch:        runtime.GOARCH,
+	}
+	if *info != want {
+		t.Errorf("GetAppInfo = %+v, want %+v", *info, want)
+	}
+}
+
+// setBuildVars sets the build metadata for the rest of the test
+func setBuildVars(t *testing.T, commit, buildTime, version string) {
+	t.Helper()
+	savedCommit, savedTime, savedVersion := BuildCommit, BuildTime, BuildVersion
+	t.Cleanup(func() { BuildCommit, BuildTime, BuildVersion = savedCommit, savedTime, savedVersion })
+	BuildCommit, BuildTime, BuildVersion = commit, buildTime, version
+}
+
+func TestBuildInfoDefaults(t *testing.T) {
+	setBuildVars(t, "unknown", "unknown", "")
+	app, _ := newTestApp(t, http.NotFoundHandler())
+	app.config.App.Version = "1.0.0"
+
+	build := app.GetBuildInfo()
+	want := BuildInfo{Commit: "unknown", Time: "unknown", Version: "dev", GoVersion: runtime.Version()}
+	if *build != want {
+		t.Errorf("GetBuildInfo = %+v, want %+v", *build, want)
+	}
+	if version := app.GetAppInfo().Version; version != "1.0.0" {
+		t.Errorf("AppInfo.Version = %q, want the configured version", version)
+	}
+}
+
+func TestBuildInfoOverrides(t *testing.T) {
+	setBuildVars(t, "abc1234", "2024-05-01T10:00:00Z", "1.4.0")
+	app, _ := newTestApp(t, http.NotFoundHandler())
+	app.config.App.Version = "1.0.0"
+
+	build := app.GetBuildInfo()
+	want := BuildInfo{Commit: "abc1234", Time: "2024-05-01T10:00:00Z", Version: "1.4.0", GoVersion: runtime.Version()}
+	if *build != want {
+		t.Errorf("GetBuildInfo = %+v, want %+v", *build, want)
+	}
+
+	info := app.GetAppInfo()
+	if info.Version != "1.4.0" || info.BuildCommit != "abc1234" || info.BuildTime != "2024-05-01T10:00:00Z" {
+		t.Errorf("GetAppInfo = %+v, want the build metadata and version", info)
+	}
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"os"
+	"strings"
+	"wails-template/internal/config"
+
+	"github.com/wailsapp/wails/v2/pkg/options/assetserver"
+)
+
+// devAssetsDir is served from disk when hot reload is enabled and ASSETS_DIR
+// is not set
+const devAssetsDir = "frontend/dist"
+
+// assetServerOptions configures where the frontend is served from.
+//
+// Precedence, outside production builds and the production environment:
+//  1. ASSETS_DIR set to an http(s) URL: requests are proxied to that server
+//     (e.g. a running Vite dev server)
+//  2. ASSETS_DIR set to a directory: files are served from that directory
+//  3. hot_reload enabled: files are served from frontend/dist on disk
+//  4. otherwise the embedded assets are served
+//
+// Production builds (built with the production tag) and the production
+// environment always serve the embedded assets.
+func assetServerOptions(cfg *config.Config) *assetserver.Options {
+	options := &assetserver.Options{
+		Assets:     assets,
+		Middleware: corsMiddleware(cfg),
+	}
+	if !allowAssetsOverride || cfg.App.Environment.IsProduction() {
+		return options
+	}
+
+	source := os.Getenv("ASSETS_DIR")
+	if source == "" && cfg.App.HotReload {
+		source = devAssetsDir
+	}
+	if source == "" {
+		return options
+	}
+
+	if strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") {
+		target, err := url.Parse(source)
+		if err != nil {
+			fmt.Printf("Invalid ASSETS_DIR URL %q, using embedded assets: %v\n", source, err)
+			return options
+		}
+		options.Assets = nil
+		options.Handler = httputil.NewSingleHostReverseProxy(target)
+		return options
+	}
+
+	if info, err := os.Stat(source); err != nil || !info.IsDir() {
+		fmt.Printf("Assets directory %q not found, using embedded assets\n", source)
+		return options
+	}
+	options.Assets = nil
+	options.Handler = http.FileServer(http.Dir(source))
+	return options
+}
@@ -0,0 +1,7 @@
+//go:build !production
+
+package main
+
+// allowAssetsOverride lets development builds serve assets from disk or a
+// dev server, see assetServerOptions
+const allowAssetsOverride = true
@@ -0,0 +1,7 @@
+//go:build production
+
+package main
+
+// allowAssetsOverride is false in production builds so the embedded assets
+// are always served
+const allowAssetsOverride = false
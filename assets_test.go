@@ -0,0 +1,86 @@
+//go:build !production
+
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"wails-template/internal/config"
+)
+
+// serveAsset requests path from handler and returns the response body
+func serveAsset(t *testing.T, handler http.Handler, path string) string {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+	body, _ := io.ReadAll(rec.Body)
+	return string(body)
+}
+
+func TestAssetServerOptionsDirectory(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "app.js"), []byte("from disk"), 0644)
+	t.Setenv("ASSETS_DIR", dir)
+
+	options := assetServerOptions(&config.Config{App: config.AppConfig{Environment: config.Development}})
+	if options.Assets != nil || options.Handler == nil {
+		t.Fatal("ASSETS_DIR directory did not replace the embedded assets")
+	}
+	if got := serveAsset(t, options.Handler, "/app.js"); got != "from disk" {
+		t.Errorf("served %q, want the file from ASSETS_DIR", got)
+	}
+}
+
+func TestAssetServerOptionsDevServer(t *testing.T) {
+	devServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "from dev server "+r.URL.Path)
+	}))
+	defer devServer.Close()
+	t.Setenv("ASSETS_DIR", devServer.URL)
+
+	options := assetServerOptions(&config.Config{App: config.AppConfig{Environment: config.Development}})
+	if options.Assets != nil || options.Handler == nil {
+		t.Fatal("ASSETS_DIR URL did not replace the embedded assets")
+	}
+	if got := serveAsset(t, options.Handler, "/src/main.tsx"); got != "from dev server /src/main.tsx" {
+		t.Errorf("served %q, want the dev server response", got)
+	}
+}
+
+func TestAssetServerOptionsEmbedded(t *testing.T) {
+	tests := []struct {
+		name      string
+		env       config.Environment
+		assetsDir string
+		hotReload bool
+	}{
+		{"default", config.Development, "", false},
+		{"production environment", config.Production, t.TempDir(), true},
+		{"missing directory", config.Development, filepath.Join(t.TempDir(), "missing"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("ASSETS_DIR", tt.assetsDir)
+			options := assetServerOptions(&config.Config{App: config.AppConfig{Environment: tt.env, HotReload: tt.hotReload}})
+			if options.Assets == nil || options.Handler != nil {
+				t.Error("embedded assets were not served")
+			}
+		})
+	}
+}
+
+func TestAssetServerOptionsHotReload(t *testing.T) {
+	if info, err := os.Stat(devAssetsDir); err != nil || !info.IsDir() {
+		t.Skip("frontend has not been built")
+	}
+	t.Setenv("ASSETS_DIR", "")
+
+	options := assetServerOptions(&config.Config{App: config.AppConfig{Environment: config.Development, HotReload: true}})
+	if options.Assets != nil || options.Handler == nil {
+		t.Error("hot reload did not serve the frontend from disk")
+	}
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"wails-template/internal/config"
+	"wails-template/internal/logger"
+)
+
+// Actions recorded in the audit log
+const (
+	auditReload           = "reload"
+	auditReset            = "reset"
+	auditResetSecrets     = "reset-secrets"
+	auditRotateCSRFSecret = "rotate-csrf-secret"
+	auditSwitchProfile    = "switch-profile"
+)
+
+// Triggers of audited config changes
+const (
+	triggerUser        = "user"
+	triggerFileWatcher = "file-watcher"
+)
+
+// auditConfigChange records a config change in the audit log. A rejected
+// change is recorded with its error and without changes. Failing to write
+// the record is logged but doesn't fail the change, which has already
+// happened.
+func (a *App) auditConfigChange(action, trigger string, changes []config.Change, err error) {
+	entry := logger.AuditEntry{
+		Time:    a.clock.Now(),
+		Action:  action,
+		Trigger: trigger,
+		Changes: changes,
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	if err := a.audit.Write(entry); err != nil {
+		a.logger.Error("Failed to write audit log", "action", action, "error", err)
+	}
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"wails-template/internal/config"
+	"wails-template/internal/logger"
+)
+
+// useAuditLog points the audit log of app to a temporary file and returns
+// its path
+func useAuditLog(t *testing.T, app *App) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "audit.log")
+	audit, err := logger.OpenAudit(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	app.audit = audit
+	return path
+}
+
+// auditEntries reads the entries written to the audit file at path
+func auditEntries(t *testing.T, path string) []logger.AuditEntry {
+	t.Helper()
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	var entries []logger.AuditEntry
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var entry logger.AuditEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatalf("audit line %q: %v", scanner.Text(), err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func TestAuditConfigEdit(t *testing.T) {
+	path := useConfigCopy(t)
+	app, _ := newTestApp(t, http.NotFoundHandler())
+	auditPath := useAuditLog(t, app)
+
+	const secret = "edited-csrf-secret-value"
+	values := config.Values{
+		"window":   {"width": "1300"},
+		"security": {"csrf_secret": secret},
+	}
+	if err := config.WriteValues(path, values); err != nil {
+		t.Fatal(err)
+	}
+	if err := app.ReloadConfig(); err != nil {
+		t.Fatalf("ReloadConfig: %v", err)
+	}
+
+	entries := auditEntries(t, auditPath)
+	if len(entries) != 1 {
+		t.Fatalf("audit entries = %d, want 1", len(entries))
+	}
+	entry := entries[0]
+	if entry.Action != auditReload || entry.Trigger != triggerUser || entry.Error != "" {
+		t.Errorf("entry = %s by %s, error %q, want a successful reload by the user", entry.Action, entry.Trigger, entry.Error)
+	}
+	var paths []string
+	for _, change := range entry.Changes {
+		paths = append(paths, change.Path)
+	}
+	for _, want := range []string{"window.width", "security.csrfSecret"} {
+		if !slices.Contains(paths, want) {
+			t.Errorf("changed paths = %q, missing %s", paths, want)
+		}
+	}
+
+	data, err := os.ReadFile(auditPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), secret) {
+		t.Errorf("audit log contains the secret: %s", data)
+	}
+}
+
+func TestAuditCSRFSecretRotation(t *testing.T) {
+	useConfigCopy(t)
+	app, _ := newTestApp(t, http.NotFoundHandler())
+	auditPath := useAuditLog(t, app)
+
+	secret, err := app.GenerateAndPersistCSRFSecret()
+	if err != nil {
+		t.Fatalf("GenerateAndPersistCSRFSecret: %v", err)
+	}
+
+	entries := auditEntries(t, auditPath)
+	if len(entries) != 1 || entries[0].Action != auditRotateCSRFSecret {
+		t.Fatalf("audit entries = %+v, want one rotate-csrf-secret entry", entries)
+	}
+	data, err := os.ReadFile(auditPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), secret) {
+		t.Errorf("audit log contains the secret: %s", data)
+	}
+}
+
+func TestAuditRejectedReload(t *testing.T) {
+	path := useConfigCopy(t)
+	app, _ := newTestApp(t, http.NotFoundHandler())
+	auditPath := useAuditLog(t, app)
+
+	setWindowWidth(t, path, "100")
+	if err := app.ReloadConfig(); err == nil {
+		t.Fatal("ReloadConfig accepted an invalid config")
+	}
+
+	entries := auditEntries(t, auditPath)
+	if len(entries) != 1 || !strings.Contains(entries[0].Error, "Window width must be at least 400") || len(entries[0].Changes) != 0 {
+		t.Errorf("audit entries = %+v, want the rejected reload without changes", entries)
+	}
+}
@@ -0,0 +1,235 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+)
+
+// ErrNotAuthenticated is returned when an operation requires a logged-in session
+var ErrNotAuthenticated = errors.New("not authenticated")
+
+// RefreshRequest represents the token refresh request payload
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// session holds the tokens issued for the current login
+type session struct {
+	accessToken  string
+	refreshToken string
+	tokenType    string
+	expiresAt    time.Time
+	user         User
+	userFetched  time.Time
+}
+
+// newSession creates a session from login data, falling back to the
+// configured token expiry when the server doesn't report one. The expiry is
+// moved forward by the clock skew tolerance so the token is refreshed
+// before the server considers it expired.
+func (a *App) newSession(data *LoginData) *session {
+	cfg := a.currentConfig()
+	expiresIn := time.Duration(data.ExpiresIn) * time.Second
+	if expiresIn <= 0 {
+		expiresIn = cfg.Auth.TokenExpiry
+	}
+	now := a.clock.Now()
+	return &session{
+		accessToken:  data.AccessToken,
+		refreshToken: data.RefreshToken,
+		tokenType:    data.TokenType,
+		expiresAt:    now.Add(expiresIn - cfg.Auth.ClockSkewTolerance),
+		user:         data.User,
+		userFetched:  now,
+	}
+}
+
+// startSession stores the tokens of a new login and starts the background
+// refresh scheduler if it isn't running yet
+func (a *App) startSession(data *LoginData) {
+	a.sessionMu.Lock()
+	defer a.sessionMu.Unlock()
+
+	a.session = a.newSession(data)
+	if a.stopRefresh == nil {
+		ctx, cancel := context.WithCancel(a.done)
+		started := a.goBackground(func(context.Context) {
+			a.runRefreshScheduler(ctx)
+		})
+		if started {
+			a.stopRefresh = cancel
+		} else {
+			cancel()
+		}
+	}
+}
+
+// updateSession stores refreshed tokens, keeping fields the refresh
+// response didn't include. It does nothing after logout.
+func (a *App) updateSession(data *LoginData) {
+	a.sessionMu.Lock()
+	defer a.sessionMu.Unlock()
+
+	if a.session == nil {
+		return
+	}
+	next := a.newSession(data)
+	if next.refreshToken == "" {
+		next.refreshToken = a.session.refreshToken
+	}
+	if next.user.ID == "" {
+		next.user = a.session.user
+		next.userFetched = a.session.userFetched
+	}
+	a.session = next
+}
+
+// endSession clears the tokens, stops the background refresh and purges the
+// responses cached for the session
+func (a *App) endSession() {
+	a.sessionMu.Lock()
+	ended := a.session
+	a.session = nil
+	if a.stopRefresh != nil {
+		a.stopRefresh()
+		a.stopRefresh = nil
+	}
+	a.sessionMu.Unlock()
+
+	if ended != nil {
+		a.purgeIdentity(sessionIdentity(ended))
+	}
+}
+
+// sessionExpiry returns when the current session expires
+func (a *App) sessionExpiry() (time.Time, bool) {
+	a.sessionMu.RLock()
+	defer a.sessionMu.RUnlock()
+
+	if a.session == nil {
+		return time.Time{}, false
+	}
+	return a.session.expiresAt, true
+}
+
+// setAuthHeader attaches the access token of the current session to req in
+// the header named by auth.auth_header_name
+func (a *App) setAuthHeader(req *http.Request) {
+	if value := a.authHeaderValue(); value != "" {
+		name := a.currentConfig().Auth.HeaderName
+		if name == "" {
+			name = "Authorization"
+		}
+		req.Header.Set(name, value)
+	}
+}
+
+// authHeaderValue returns the auth header value for the current session, or
+// an empty string when logged out. The token is prefixed with
+// auth.auth_scheme, or when it is empty with the token type reported at
+// login, such as ApiKey for the API key strategy, and else with Bearer.
+func (a *App) authHeaderValue() string {
+	a.sessionMu.RLock()
+	defer a.sessionMu.RUnlock()
+
+	if a.session == nil || a.session.accessToken == "" {
+		return ""
+	}
+	scheme := a.currentConfig().Auth.Scheme
+	if scheme == "" {
+		scheme = a.session.tokenType
+	}
+	if scheme == "" {
+		scheme = "Bearer"
+	}
+	return scheme + " " + a.session.accessToken
+}
+
+// Logout ends the current session
+func (a *App) Logout() {
+	a.endSession()
+}
+
+// RefreshToken refreshes the access token of the current session
+func (a *App) RefreshToken() (err error) {
+	defer a.recoverPanic("RefreshToken", &err)
+
+	return a.refreshSession(a.requestContext())
+}
+
+// refreshSession exchanges the refresh token for new tokens. Concurrent
+// callers share a single in-flight refresh request.
+func (a *App) refreshSession(ctx context.Context) error {
+	_, err, _ := a.refreshGroup.Do("refresh", func() (any, error) {
+		a.sessionMu.RLock()
+		var refreshToken string
+		if a.session != nil {
+			refreshToken = a.session.refreshToken
+		}
+		a.sessionMu.RUnlock()
+
+		if refreshToken == "" {
+			return nil, ErrNotAuthenticated
+		}
+
+		strategy, err := a.authStrategy()
+		if err != nil {
+			return nil, err
+		}
+		tokens, err := strategy.Refresh(ctx, refreshToken)
+		if err != nil {
+			return nil, err
+		}
+
+		a.updateSession(tokens)
+		return nil, nil
+	})
+	return err
+}
+
+// runRefreshScheduler refreshes the session RefreshThreshold before it
+// expires. Failed refreshes are retried with backoff until the token
+// expires, at which point the session ends and auth:session-expired is
+// emitted.
+func (a *App) runRefreshScheduler(ctx context.Context) {
+	cfg := a.currentConfig()
+	for {
+		expiresAt, ok := a.sessionExpiry()
+		if !ok {
+			return
+		}
+		if !a.sleep(ctx, expiresAt.Add(-cfg.Auth.RefreshThreshold).Sub(a.clock.Now())) {
+			return
+		}
+
+		backoff := cfg.API.RetryDelay
+		if backoff <= 0 {
+			backoff = time.Second
+		}
+		for {
+			err := a.refreshSession(ctx)
+			if err == nil {
+				break
+			}
+			if errors.Is(err, ErrNotAuthenticated) || ctx.Err() != nil {
+				return
+			}
+
+			// Give up once the next attempt would fall after expiry
+			if remaining := expiresAt.Sub(a.clock.Now()); backoff >= remaining {
+				if !a.sleep(ctx, remaining) {
+					return
+				}
+				a.endSession()
+				a.emitEvent("auth:session-expired")
+				return
+			}
+			if !a.sleep(ctx, backoff) {
+				return
+			}
+			backoff = min(backoff*2, cfg.Auth.RefreshThreshold)
+		}
+	}
+}
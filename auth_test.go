@@ -0,0 +1,202 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+	"wails-template/internal/clock"
+)
+
+// waitFor polls cond until it holds, failing the test after a few seconds
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// refreshHandler serves logins and answers refreshes with refresh, counting
+// the refresh requests in hits
+func refreshHandler(hits *atomic.Int32, refresh func(n int32, w http.ResponseWriter)) http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/identity/login", loginHandler())
+	mux.HandleFunc("/identity/refresh", func(w http.ResponseWriter, r *http.Request) {
+		refresh(hits.Add(1), w)
+	})
+	return mux
+}
+
+// newRefreshTestApp creates a logged-in app driven by a fake clock, with a
+// five minute refresh threshold and a one second refresh backoff
+func newRefreshTestApp(t *testing.T, handler http.Handler) (*App, *clock.Fake) {
+	t.Helper()
+
+	app, _ := newTestApp(t, handler)
+	fake := clock.NewFake(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	app.clock = fake
+	app.config.Auth.RefreshThreshold = 5 * time.Minute
+	app.config.API.RetryDelay = time.Second
+
+	if _, err := app.Login("admin", "secret"); err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	return app, fake
+}
+
+func TestRefreshSchedulerRecoversFromTransientFailure(t *testing.T) {
+	var hits atomic.Int32
+	app, fake := newRefreshTestApp(t, refreshHandler(&hits, func(n int32, w http.ResponseWriter) {
+		if n == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		io.WriteString(w, `{"success":true,"data":{"access_token":"access-2","expires_in":3600}}`)
+	}))
+
+	// Wake up RefreshThreshold before the one hour token expires
+	waitFor(t, "scheduler to sleep", func() bool { return fake.Waiters() == 1 })
+	fake.Advance(55 * time.Minute)
+
+	// The first refresh fails and the scheduler backs off
+	waitFor(t, "first refresh", func() bool { return hits.Load() == 1 && fake.Waiters() == 1 })
+	if got := app.authHeaderValue(); got != "Bearer access-1" {
+		t.Fatalf("token after failed refresh = %q, want the original token", got)
+	}
+	fake.Advance(time.Second)
+
+	waitFor(t, "second refresh", func() bool { return hits.Load() == 2 && fake.Waiters() == 1 })
+	if got := app.authHeaderValue(); got != "Bearer access-2" {
+		t.Errorf("token after retried refresh = %q, want Bearer access-2", got)
+	}
+	if _, ok := app.sessionExpiry(); !ok {
+		t.Error("session ended although the retry succeeded")
+	}
+}
+
+func TestRefreshSchedulerExpiresSession(t *testing.T) {
+	var hits atomic.Int32
+	app, fake := newRefreshTestApp(t, refreshHandler(&hits, func(n int32, w http.ResponseWriter) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+
+	expiresAt, _ := app.sessionExpiry()
+	for {
+		hasSession := func() bool { _, ok := app.sessionExpiry(); return ok }
+		waitFor(t, "scheduler", func() bool { return fake.Waiters() > 0 || !hasSession() })
+		if !hasSession() {
+			break
+		}
+		fake.Advance(time.Minute)
+	}
+
+	if fake.Now().Before(expiresAt) {
+		t.Errorf("session ended at %v, before its expiry at %v", fake.Now(), expiresAt)
+	}
+	if hits.Load() < 2 {
+		t.Errorf("refresh attempts = %d, want retries before expiry", hits.Load())
+	}
+}
+
+func TestLogoutStopsRefreshScheduler(t *testing.T) {
+	var hits atomic.Int32
+	app, fake := newRefreshTestApp(t, refreshHandler(&hits, func(n int32, w http.ResponseWriter) {}))
+
+	waitFor(t, "scheduler to sleep", func() bool { return fake.Waiters() == 1 })
+	app.Logout()
+	fake.Advance(time.Hour)
+
+	if err := app.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if hits.Load() != 0 {
+		t.Errorf("refresh attempts after logout = %d, want 0", hits.Load())
+	}
+}
+
+func TestClockSkewToleranceShiftsRefresh(t *testing.T) {
+	for _, skew := range []time.Duration{0, 30 * time.Second} {
+		t.Run(skew.String(), func(t *testing.T) {
+			var hits atomic.Int32
+			app, _ := newTestApp(t, refreshHandler(&hits, func(n int32, w http.ResponseWriter) {
+				io.WriteString(w, `{"success":true,"data":{"access_token":"access-2","expires_in":3600}}`)
+			}))
+			fake := clock.NewFake(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+			app.clock = fake
+			app.config.Auth.RefreshThreshold = 5 * time.Minute
+			app.config.Auth.ClockSkewTolerance = skew
+
+			start := fake.Now()
+			if _, err := app.Login("admin", "secret"); err != nil {
+				t.Fatalf("Login: %v", err)
+			}
+			if expiresAt, _ := app.sessionExpiry(); !expiresAt.Equal(start.Add(time.Hour - skew)) {
+				t.Errorf("expiry = %v, want %v", expiresAt, start.Add(time.Hour-skew))
+			}
+
+			// The refresh is due RefreshThreshold plus the skew before the
+			// one hour token expires
+			waitFor(t, "scheduler to sleep", func() bool { return fake.Waiters() == 1 })
+			fake.Advance(55*time.Minute - skew - time.Second)
+			if fake.Waiters() != 1 || hits.Load() != 0 {
+				t.Fatal("refresh started before it was due")
+			}
+			fake.Advance(time.Second)
+			waitFor(t, "refresh", func() bool { return hits.Load() == 1 })
+		})
+	}
+}
+
+func TestAuthHeaderScheme(t *testing.T) {
+	const withoutType = `{"success":true,"data":{"access_token":"access-1","expires_in":3600,"user":{"id":"u1"}}}`
+	tests := []struct {
+		name       string
+		login      string
+		headerName string
+		scheme     string
+		wantHeader string
+		wantValue  string
+	}{
+		{"defaults", withoutType, "", "", "Authorization", "Bearer access-1"},
+		{"scheme", withoutType, "Authorization", "Token", "Authorization", "Token access-1"},
+		{"header name", withoutType, "X-Auth-Token", "Token", "X-Auth-Token", "Token access-1"},
+		{"scheme over reported token type", loginResponseJSON, "Authorization", "Token", "Authorization", "Token access-1"},
+		{"reported token type", strings.Replace(loginResponseJSON, `"Bearer"`, `"MAC"`, 1), "Authorization", "", "Authorization", "MAC access-1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			headers := make(chan http.Header, 1)
+			mux := http.NewServeMux()
+			mux.HandleFunc("/identity/login", func(w http.ResponseWriter, r *http.Request) {
+				io.WriteString(w, tt.login)
+			})
+			mux.HandleFunc("/items", func(w http.ResponseWriter, r *http.Request) {
+				headers <- r.Header
+				io.WriteString(w, `[]`)
+			})
+			app, _ := newTestApp(t, mux)
+			app.config.Auth.HeaderName = tt.headerName
+			app.config.Auth.Scheme = tt.scheme
+
+			if _, err := app.Login("admin", "secret"); err != nil {
+				t.Fatalf("Login: %v", err)
+			}
+			if _, err := app.Request(APIRequest{Method: http.MethodGet, Path: "/items"}); err != nil {
+				t.Fatalf("Request: %v", err)
+			}
+			header := <-headers
+			if got := header.Get(tt.wantHeader); got != tt.wantValue {
+				t.Errorf("%s = %q, want %q", tt.wantHeader, got, tt.wantValue)
+			}
+			if tt.wantHeader != "Authorization" && header.Get("Authorization") != "" {
+				t.Errorf("Authorization = %q, want it unset", header.Get("Authorization"))
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+	"wails-template/internal/config"
+)
+
+// ErrInvalidInput is returned for login details that are rejected before
+// any request is sent, such as an empty username
+var ErrInvalidInput = errors.New("invalid input")
+
+// Credentials are the login details entered by the user. Strategies that
+// don't log in with a user, such as the API key strategy, ignore them.
+type Credentials struct {
+	Username string
+	Password string
+}
+
+// validate rejects an empty username or password and, when minPasswordLength
+// is set, a password with fewer characters
+func (c Credentials) validate(minPasswordLength int) error {
+	if strings.TrimSpace(c.Username) == "" {
+		return fmt.Errorf("%w: username is required", ErrInvalidInput)
+	}
+	if c.Password == "" {
+		return fmt.Errorf("%w: password is required", ErrInvalidInput)
+	}
+	if utf8.RuneCountInString(c.Password) < minPasswordLength {
+		return fmt.Errorf("%w: password must be at least %d characters", ErrInvalidInput, minPasswordLength)
+	}
+	return nil
+}
+
+// Tokens are the tokens issued by an AuthStrategy, in the shape of the data
+// of a login response
+type Tokens = LoginData
+
+// AuthStrategy obtains the tokens of a session from the auth server and
+// refreshes them. The strategy is selected with [auth] strategy.
+type AuthStrategy interface {
+	Authenticate(ctx context.Context, creds Credentials) (*Tokens, error)
+	Refresh(ctx context.Context, refreshToken string) (*Tokens, error)
+}
+
+// authStrategy returns the strategy selected by the configuration
+func (a *App) authStrategy() (AuthStrategy, error) {
+	cfg := a.currentConfig()
+	switch cfg.Auth.Strategy {
+	case config.AuthStrategyPassword, "":
+		return &passwordStrategy{app: a}, nil
+	case config.AuthStrategyAPIKey:
+		return &apiKeyStrategy{key: cfg.Auth.APIKey}, nil
+	}
+	return nil, fmt.Errorf("unknown auth strategy %q", cfg.Auth.Strategy)
+}
+
+// passwordStrategy logs in with a username and password against
+// /identity/login and refreshes through /identity/refresh
+type passwordStrategy struct {
+	app *App
+}
+
+func (s *passwordStrategy) Authenticate(ctx context.Context, creds Credentials) (*Tokens, error) {
+	if err := creds.validate(s.app.config.Auth.MinPasswordLength); err != nil {
+		return nil, err
+	}
+	loginReq := LoginRequest{
+		Username: creds.Username,
+		Password: creds.Password,
+	}
+
+	var loginResp LoginResponse
+	if err := s.app.doJSON(ctx, http.MethodPost, s.app.config.API.BaseURL+"/identity/login", loginReq, &loginResp); err != nil {
+		var apiErr *APIError
+		if errors.As(err, &apiErr) {
+			return nil, fmt.Errorf("login failed: %w", err)
+		}
+		return nil, err
+	}
+	if !loginResp.Success {
+		return nil, fmt.Errorf("login failed: %s", loginResp.Message)
+	}
+	return &loginResp.Data, nil
+}
+
+func (s *passwordStrategy) Refresh(ctx context.Context, refreshToken string) (*Tokens, error) {
+	var refreshResp LoginResponse
+	if err := s.app.doJSON(ctx, http.MethodPost, s.app.baseURL()+"/identity/refresh", RefreshRequest{RefreshToken: refreshToken}, &refreshResp); err != nil {
+		return nil, err
+	}
+	if !refreshResp.Success {
+		return nil, fmt.Errorf("token refresh failed: %s", refreshResp.Message)
+	}
+	return &refreshResp.Data, nil
+}
+
+// apiKeyStrategy sends a static API key as the access token. There is no
+// login request and the key never changes, so refreshing returns it again.
+type apiKeyStrategy struct {
+	key string
+}
+
+func (s *apiKeyStrategy) Authenticate(ctx context.Context, _ Credentials) (*Tokens, error) {
+	if s.key == "" {
+		return nil, errors.New("no API key configured")
+	}
+	// The key doubles as the refresh token so the session is kept alive
+	return &Tokens{AccessToken: s.key, RefreshToken: s.key, TokenType: "ApiKey"}, nil
+}
+
+func (s *apiKeyStrategy) Refresh(ctx context.Context, _ string) (*Tokens, error) {
+	return s.Authenticate(ctx, Credentials{})
+}
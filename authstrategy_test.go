@@ -0,0 +1,153 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"wails-template/internal/config"
+)
+
+func TestAuthStrategySelection(t *testing.T) {
+	app, _ := newTestApp(t, http.NotFoundHandler())
+
+	tests := []struct {
+		strategy string
+		want     string // strategy type, empty for an error
+	}{
+		{"", "*main.passwordStrategy"},
+		{config.AuthStrategyPassword, "*main.passwordStrategy"},
+		{config.AuthStrategyAPIKey, "*main.apiKeyStrategy"},
+		{"oauth", ""},
+	}
+	for _, tt := range tests {
+		app.config.Auth.Strategy = tt.strategy
+		strategy, err := app.authStrategy()
+		if got := fmt.Sprintf("%T", strategy); strategy != nil && got != tt.want {
+			t.Errorf("strategy %q = %s, want %s", tt.strategy, got, tt.want)
+		}
+		if (err != nil) != (tt.want == "") {
+			t.Errorf("strategy %q: error = %v", tt.strategy, err)
+		}
+	}
+}
+
+func TestPasswordStrategyRefresh(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/identity/refresh", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if string(body) != `{"refresh_token":"refresh-1"}` {
+			http.Error(w, "unexpected body", http.StatusBadRequest)
+			return
+		}
+		io.WriteString(w, `{"success":true,"data":{"access_token":"access-2","expires_in":3600}}`)
+	})
+	app, _ := newTestApp(t, mux)
+
+	tokens, err := (&passwordStrategy{app: app}).Refresh(context.Background(), "refresh-1")
+	if err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	if tokens.AccessToken != "access-2" || tokens.ExpiresIn != 3600 {
+		t.Errorf("tokens = %+v, want access-2 for an hour", tokens)
+	}
+}
+
+func TestPasswordStrategyLoginRejected(t *testing.T) {
+	app, _ := newTestApp(t, loginServer(`{"success":false,"message":"account locked"}`))
+
+	if _, err := app.Login("admin", "secret"); err == nil || err.Error() != "login failed: account locked" {
+		t.Errorf("Login error = %v, want the server message", err)
+	}
+	if _, ok := app.sessionExpiry(); ok {
+		t.Error("a rejected login started a session")
+	}
+}
+
+func TestLoginValidatesInput(t *testing.T) {
+	var logins atomic.Int32
+	app, _ := newTestApp(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		logins.Add(1)
+		io.WriteString(w, loginResponseJSON)
+	}))
+
+	tests := []struct {
+		name      string
+		username  string
+		password  string
+		minLength int
+		wantErr   bool
+	}{
+		{"empty username", "", "secret", 0, true},
+		{"blank username", "  ", "secret", 0, true},
+		{"empty password", "admin", "", 0, true},
+		{"short password allowed by default", "admin", "a", 0, false},
+		{"password too short", "admin", "secret", 8, true},
+		{"password long enough", "admin", "sécrète!", 8, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app.config.Auth.MinPasswordLength = tt.minLength
+			before := logins.Load()
+
+			_, err := app.Login(tt.username, tt.password)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidInput) {
+					t.Errorf("Login error = %v, want ErrInvalidInput", err)
+				}
+				if logins.Load() != before {
+					t.Error("invalid input was sent to the server")
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("Login: %v", err)
+			}
+		})
+	}
+}
+
+func TestAPIKeyStrategy(t *testing.T) {
+	tests := []struct {
+		scheme string
+		want   string
+	}{
+		{"", "ApiKey key-1"},
+		{"Token", "Token key-1"},
+	}
+	for _, tt := range tests {
+		var logins atomic.Int32
+		authorization := make(chan string, 1)
+		mux := http.NewServeMux()
+		mux.HandleFunc("/identity/login", func(w http.ResponseWriter, r *http.Request) {
+			logins.Add(1)
+		})
+		mux.HandleFunc("/items", func(w http.ResponseWriter, r *http.Request) {
+			authorization <- r.Header.Get("Authorization")
+			io.WriteString(w, `[]`)
+		})
+		app, _ := newTestApp(t, mux)
+		app.config.Auth.Strategy = config.AuthStrategyAPIKey
+		app.config.Auth.APIKey = "key-1"
+		app.config.Auth.Scheme = tt.scheme
+
+		if _, err := app.Login("", ""); err != nil {
+			t.Fatalf("Login: %v", err)
+		}
+		if err := app.RefreshToken(); err != nil {
+			t.Fatalf("RefreshToken: %v", err)
+		}
+		if _, err := app.Request(APIRequest{Method: http.MethodGet, Path: "/items"}); err != nil {
+			t.Fatalf("Request: %v", err)
+		}
+		if got := <-authorization; got != tt.want {
+			t.Errorf("scheme %q: Authorization = %q, want %s", tt.scheme, got, tt.want)
+		}
+		if logins.Load() != 0 {
+			t.Errorf("API key strategy sent %d login requests", logins.Load())
+		}
+	}
+}
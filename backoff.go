@@ -0,0 +1,30 @@
+package main
+
+import (
+	"time"
+	"wails-template/internal/config"
+)
+
+// retryDelay returns how long to wait before retrying a failed API request:
+// api.retry_delay randomized by api.jitter_strategy
+func (a *App) retryDelay() time.Duration {
+	cfg := a.currentConfig()
+	return jitteredDelay(cfg.API.JitterStrategy, cfg.API.RetryDelay, a.randInt64N)
+}
+
+// jitteredDelay spreads delay according to strategy. randN returns a random
+// number in [0, n). Unknown strategies use full jitter, the default.
+func jitteredDelay(strategy config.JitterStrategy, delay time.Duration, randN func(n int64) int64) time.Duration {
+	if delay <= 0 {
+		return 0
+	}
+	switch strategy {
+	case config.JitterNone:
+		return delay
+	case config.JitterEqual:
+		half := delay / 2
+		return half + time.Duration(randN(int64(delay-half)+1))
+	default:
+		return time.Duration(randN(int64(delay) + 1))
+	}
+}
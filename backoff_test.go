@@ -0,0 +1,67 @@
+package main
+
+import (
+	"math/rand/v2"
+	"net/http"
+	"testing"
+	"time"
+	"wails-template/internal/config"
+)
+
+func TestJitteredDelayBounds(t *testing.T) {
+	const delay = time.Second
+	tests := []struct {
+		strategy config.JitterStrategy
+		min, max time.Duration
+	}{
+		{config.JitterNone, delay, delay},
+		{config.JitterFull, 0, delay},
+		{config.JitterEqual, delay / 2, delay},
+		{"", 0, delay},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.strategy), func(t *testing.T) {
+			random := rand.New(rand.NewPCG(1, 2))
+			lowest, highest := time.Duration(1<<62), time.Duration(0)
+			for range 1000 {
+				d := jitteredDelay(tt.strategy, delay, random.Int64N)
+				if d < tt.min || d > tt.max {
+					t.Fatalf("delay = %v, want between %v and %v", d, tt.min, tt.max)
+				}
+				lowest, highest = min(lowest, d), max(highest, d)
+			}
+
+			// The delays spread over the whole range
+			spread := (tt.max - tt.min) / 10
+			if lowest > tt.min+spread || highest < tt.max-spread {
+				t.Errorf("delays between %v and %v, want them to cover %v to %v", lowest, highest, tt.min, tt.max)
+			}
+		})
+	}
+}
+
+func TestJitteredDelayWithoutDelay(t *testing.T) {
+	random := rand.New(rand.NewPCG(1, 2))
+	for _, strategy := range []config.JitterStrategy{config.JitterNone, config.JitterFull, config.JitterEqual} {
+		if d := jitteredDelay(strategy, 0, random.Int64N); d != 0 {
+			t.Errorf("%s: delay = %v, want 0", strategy, d)
+		}
+	}
+}
+
+func TestRetryDelayUsesStrategy(t *testing.T) {
+	app, _ := newTestApp(t, http.NotFoundHandler())
+	app.randInt64N = func(n int64) int64 { return 0 }
+	app.config.API.RetryDelay = time.Second
+
+	for strategy, want := range map[config.JitterStrategy]time.Duration{
+		config.JitterNone:  time.Second,
+		config.JitterFull:  0,
+		config.JitterEqual: time.Second / 2,
+	} {
+		app.config.API.JitterStrategy = strategy
+		if got := app.retryDelay(); got != want {
+			t.Errorf("%s: retryDelay = %v, want %v", strategy, got, want)
+		}
+	}
+}
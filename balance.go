@@ -0,0 +1,107 @@
+package main
+
+import (
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+	"wails-template/internal/config"
+)
+
+// endpointPool spreads the requests to the API base URL over the endpoints
+// of api.base_urls in proportion to their weights, trying endpoints that
+// recently failed to connect last. The zero value is ready to use.
+type endpointPool struct {
+	mu        sync.Mutex
+	current   map[string]int // smooth weighted round robin state
+	downUntil map[string]time.Time
+}
+
+// endpointTarget is a request URL on one endpoint. base is empty for URLs
+// that aren't balanced.
+type endpointTarget struct {
+	base string
+	url  string
+}
+
+// targets returns the URLs to try for url in order: url on every endpoint,
+// starting with the one whose turn it is by weight, healthy endpoints
+// first. URLs that don't start with baseURL, such as tenant URLs, and
+// configurations without endpoints have url as their only target.
+func (p *endpointPool) targets(url, baseURL string, entries []string, now time.Time) []endpointTarget {
+	rest, ok := strings.CutPrefix(url, baseURL)
+	if len(entries) == 0 || !ok || (rest != "" && !strings.HasPrefix(rest, "/") && !strings.HasPrefix(rest, "?")) {
+		return []endpointTarget{{url: url}}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	// Every endpoint gains its weight, the one ahead takes the turn and
+	// falls back by the total weight
+	if p.current == nil {
+		p.current = make(map[string]int)
+	}
+	endpoints := make([]string, len(entries))
+	start, total := 0, 0
+	for i, entry := range entries {
+		base, weight, err := config.ParseEndpoint(entry)
+		if err != nil {
+			base, weight = entry, 1
+		}
+		endpoints[i] = base
+		p.current[base] += weight
+		total += weight
+		if p.current[base] > p.current[endpoints[start]] {
+			start = i
+		}
+	}
+	p.current[endpoints[start]] -= total
+
+	healthy := make([]endpointTarget, 0, len(endpoints))
+	var down []endpointTarget
+	for i := range endpoints {
+		base := endpoints[(start+i)%len(endpoints)]
+		target := endpointTarget{base: base, url: base + rest}
+		if now.Before(p.downUntil[base]) {
+			down = append(down, target)
+		} else {
+			healthy = append(healthy, target)
+		}
+	}
+	return append(healthy, down...)
+}
+
+// markDown moves base to the end of the targets until cooldown has passed
+func (p *endpointPool) markDown(base string, cooldown time.Duration, now time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.downUntil == nil {
+		p.downUntil = make(map[string]time.Time)
+	}
+	p.downUntil[base] = now.Add(cooldown)
+}
+
+// markUp clears the cooldown of base once it has answered
+func (p *endpointPool) markUp(base string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.downUntil, base)
+}
+
+// isConnectionError reports whether err means the request never reached
+// the server: the host didn't resolve or the connection couldn't be made.
+// Such requests are safe to send to another endpoint whatever their method.
+func isConnectionError(err error) bool {
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return true
+	}
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" && !opErr.Timeout() {
+		return true
+	}
+	return errors.Is(err, syscall.ECONNREFUSED)
+}
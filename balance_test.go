@@ -0,0 +1,161 @@
+package main
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// closedURL returns the URL of a port that refuses connections
+func closedURL(t *testing.T) string {
+	t.Helper()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := l.Addr().String()
+	l.Close()
+	return "http://" + addr
+}
+
+func TestRequestFailsOverToNextEndpoint(t *testing.T) {
+	var hits atomic.Int64
+	app, srv := newTestApp(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		io.WriteString(w, `{"ok":true}`)
+	}))
+	down := closedURL(t)
+	app.config.API.BaseURL = "https://api.example.com"
+	app.config.API.BaseURLs = []string{down, srv.URL}
+	app.config.API.EndpointCooldown = 30 * time.Second
+
+	// Neither request counts a retry, the down endpoint was never reached.
+	// POST shows failover doesn't depend on the method.
+	for i := range 2 {
+		out, err := app.Request(APIRequest{Method: http.MethodPost, Path: "/items", Body: map[string]int{"n": i}})
+		if err != nil {
+			t.Fatalf("request %d: %v", i, err)
+		}
+		if out.(map[string]any)["ok"] != true {
+			t.Errorf("request %d: response = %v", i, out)
+		}
+	}
+	if got := hits.Load(); got != 2 {
+		t.Errorf("second endpoint served %d requests, want 2", got)
+	}
+
+	targets := app.endpoints.targets("https://api.example.com/items", app.config.API.BaseURL, app.config.API.BaseURLs, app.clock.Now())
+	if targets[len(targets)-1].base != down {
+		t.Errorf("targets = %v, want the down endpoint last", targets)
+	}
+}
+
+func TestRequestAllEndpointsDown(t *testing.T) {
+	app, _ := newTestApp(t, http.NotFoundHandler())
+	app.config.API.BaseURLs = []string{closedURL(t), closedURL(t)}
+
+	if _, err := app.Request(APIRequest{Method: http.MethodGet, Path: "/items"}); err == nil {
+		t.Fatal("Request succeeded with every endpoint down")
+	}
+}
+
+func TestEndpointPoolTargets(t *testing.T) {
+	const base = "https://api.example.com/v1"
+	endpoints := []string{"https://a.example.com/v1", "https://b.example.com/v1", "https://c.example.com/v1"}
+	now := time.Now()
+	var pool endpointPool
+
+	first := func(url string) string {
+		return pool.targets(url, base, endpoints, now)[0].url
+	}
+
+	// Round robin
+	var got []string
+	for range 4 {
+		got = append(got, first(base+"/items?page=2"))
+	}
+	want := []string{
+		"https://a.example.com/v1/items?page=2",
+		"https://b.example.com/v1/items?page=2",
+		"https://c.example.com/v1/items?page=2",
+		"https://a.example.com/v1/items?page=2",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("round robin = %v, want %v", got, want)
+	}
+
+	// A down endpoint is skipped until its cooldown has passed
+	const cooldown = 30 * time.Second
+	pool.markDown(endpoints[1], cooldown, now)
+	if got := first(base + "/items"); got != "https://c.example.com/v1/items" {
+		t.Errorf("after b is down, first target = %s, want c", got)
+	}
+	targets := pool.targets(base+"/items", base, endpoints, now)
+	if targets[len(targets)-1].base != endpoints[1] {
+		t.Errorf("targets = %v, want b last", targets)
+	}
+	var firsts []string
+	for range len(endpoints) {
+		firsts = append(firsts, pool.targets(base, base, endpoints, now.Add(cooldown))[0].base)
+	}
+	if !slices.Contains(firsts, endpoints[1]) {
+		t.Errorf("first targets after the cooldown = %v, want b in turn", firsts)
+	}
+
+	// URLs outside the base URL, such as tenant URLs, aren't balanced
+	for _, url := range []string{"https://tenant.example.com/items", base + "beta/items"} {
+		if got := pool.targets(url, base, endpoints, now); len(got) != 1 || got[0].url != url || got[0].base != "" {
+			t.Errorf("targets(%s) = %v, want the URL itself", url, got)
+		}
+	}
+	if got := pool.targets(base+"/items", base, nil, now); len(got) != 1 || got[0].url != base+"/items" {
+		t.Errorf("targets without endpoints = %v, want the URL itself", got)
+	}
+}
+
+func TestEndpointPoolWeights(t *testing.T) {
+	const base = "https://api.example.com"
+	entries := []string{"https://a.example.com;weight=3", "https://b.example.com", "https://c.example.com;weight=2"}
+	now := time.Now()
+	var pool endpointPool
+
+	// Each cycle of the total weight serves every endpoint its weight,
+	// interleaved rather than in runs
+	var got []string
+	counts := make(map[string]int)
+	for range 12 {
+		first := pool.targets(base+"/items", base, entries, now)[0].base
+		got = append(got, strings.TrimSuffix(strings.TrimPrefix(first, "https://"), ".example.com"))
+		counts[first]++
+	}
+	want := []string{"a", "c", "a", "b", "c", "a", "a", "c", "a", "b", "c", "a"}
+	if !slices.Equal(got, want) {
+		t.Errorf("rotation = %v, want %v", got, want)
+	}
+	if counts["https://a.example.com"] != 6 || counts["https://b.example.com"] != 2 || counts["https://c.example.com"] != 4 {
+		t.Errorf("requests per endpoint = %v, want 6, 2 and 4", counts)
+	}
+
+	// Every endpoint is still a target for failover
+	if targets := pool.targets(base, base, entries, now); len(targets) != 3 {
+		t.Errorf("targets = %v, want all 3 endpoints", targets)
+	}
+}
+
+func TestEndpointPoolNoCooldown(t *testing.T) {
+	const base = "https://api.example.com"
+	endpoints := []string{"https://a.example.com", "https://b.example.com"}
+	now := time.Now()
+	var pool endpointPool
+
+	pool.markDown(endpoints[0], 0, now)
+	if got := pool.targets(base, base, endpoints, now)[0].base; got != endpoints[0] {
+		t.Errorf("first target = %s, want a in turn without a cooldown", got)
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"errors"
+	"sync"
+)
+
+// BatchItem is one request of a BatchRequest. Its Timeout applies to the
+// item alone and failed attempts are retried like any other request.
+type BatchItem = APIRequest
+
+// BatchResult is the outcome of one BatchItem: the decoded response when OK
+// is set, otherwise the error and, for error responses, their status
+type BatchResult struct {
+	OK         bool   `json:"ok"`
+	Data       any    `json:"data,omitempty"`
+	Error      string `json:"error,omitempty"`
+	StatusCode int    `json:"statusCode,omitempty"`
+}
+
+// BatchRequest sends independent requests concurrently, at most
+// api.max_concurrent_requests at a time, and returns their results in input
+// order. A failed item doesn't affect the others. Items not yet started when
+// the app closes fail with the cancellation.
+func (a *App) BatchRequest(reqs []BatchItem) []BatchResult {
+	results := make([]BatchResult, len(reqs))
+	workers := a.currentConfig().API.MaxConcurrent
+	if workers <= 0 || workers > len(reqs) {
+		workers = len(reqs)
+	}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = a.batchItem(reqs[i])
+			}
+		}()
+	}
+
+feed:
+	for i := range reqs {
+		select {
+		case jobs <- i:
+		case <-a.done.Done():
+			for j := i; j < len(reqs); j++ {
+				results[j] = BatchResult{Error: a.done.Err().Error()}
+			}
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	return results
+}
+
+// batchItem sends a single item of a batch
+func (a *App) batchItem(req BatchItem) BatchResult {
+	data, err := a.Request(req)
+	if err != nil {
+		result := BatchResult{Error: err.Error()}
+		var apiErr *APIError
+		if errors.As(err, &apiErr) {
+			result.StatusCode = apiErr.StatusCode
+		}
+		return result
+	}
+	return BatchResult{OK: true, Data: data}
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// batchItems returns a GET item for each path
+func batchItems(paths ...string) []BatchItem {
+	items := make([]BatchItem, len(paths))
+	for i, path := range paths {
+		items[i] = BatchItem{Method: http.MethodGet, Path: path}
+	}
+	return items
+}
+
+func TestBatchRequestPreservesOrder(t *testing.T) {
+	app, _ := newTestApp(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Later items answer first
+		n, _ := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/items/"))
+		time.Sleep(time.Duration(5-n) * 5 * time.Millisecond)
+		io.WriteString(w, `{"id":`+strconv.Itoa(n)+`}`)
+	}))
+
+	results := app.BatchRequest(batchItems("/items/0", "/items/1", "/items/2", "/items/3", "/items/4"))
+	if len(results) != 5 {
+		t.Fatalf("results = %d, want 5", len(results))
+	}
+	for i, result := range results {
+		data, _ := result.Data.(map[string]any)
+		if !result.OK || fmt.Sprint(data["id"]) != strconv.Itoa(i) {
+			t.Errorf("result %d = %+v, want id %d", i, result, i)
+		}
+	}
+}
+
+func TestBatchRequestBoundsConcurrency(t *testing.T) {
+	var inFlight, peak atomic.Int32
+	app, _ := newTestApp(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		current := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			old := peak.Load()
+			if current <= old || peak.CompareAndSwap(old, current) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		io.WriteString(w, `{}`)
+	}))
+	app.config.API.MaxConcurrent = 2
+
+	results := app.BatchRequest(batchItems("/a", "/b", "/c", "/d", "/e", "/f"))
+	for i, result := range results {
+		if !result.OK {
+			t.Errorf("result %d = %+v, want success", i, result)
+		}
+	}
+	if got := peak.Load(); got != 2 {
+		t.Errorf("peak concurrency = %d, want 2", got)
+	}
+}
+
+func TestBatchRequestMixedResults(t *testing.T) {
+	app, _ := newTestApp(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ok":
+			io.WriteString(w, `{"ok":true}`)
+		case "/broken":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+
+	results := app.BatchRequest(batchItems("/ok", "/broken", "/missing", "/ok"))
+	wantStatus := []int{0, http.StatusInternalServerError, http.StatusNotFound, 0}
+	for i, result := range results {
+		wantOK := wantStatus[i] == 0
+		if result.OK != wantOK || result.StatusCode != wantStatus[i] {
+			t.Errorf("result %d = %+v, want ok %t and status %d", i, result, wantOK, wantStatus[i])
+		}
+		if !wantOK && result.Error == "" {
+			t.Errorf("result %d has no error message", i)
+		}
+	}
+}
+
+func TestBatchRequestCancelledOnClose(t *testing.T) {
+	handler, started := blockingHandler(t)
+	app, _ := newTestApp(t, handler)
+	app.config.API.MaxConcurrent = 1
+	app.config.API.Timeout = time.Minute
+
+	done := make(chan []BatchResult, 1)
+	go func() { done <- app.BatchRequest(batchItems("/a", "/b", "/c")) }()
+	<-started
+	app.Close()
+
+	select {
+	case results := <-done:
+		for i, result := range results {
+			if result.OK || !strings.Contains(result.Error, context.Canceled.Error()) {
+				t.Errorf("result %d = %+v, want cancelled", i, result)
+			}
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("batch kept running after Close")
+	}
+}
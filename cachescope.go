@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// anonymousIdentity scopes the responses cached while logged out
+const anonymousIdentity = "anonymous"
+
+// sessionIdentity returns the identity that responses cached during s are
+// scoped to: its user and tenant, or its access token when the login
+// response didn't include the user. Values are quoted so an identity never
+// contains a newline and can't be forged by a crafted ID.
+func sessionIdentity(s *session) string {
+	switch {
+	case s == nil:
+		return anonymousIdentity
+	case s.user.ID == "":
+		return fmt.Sprintf("token %q", s.accessToken)
+	default:
+		return fmt.Sprintf("user %q tenant %q", s.user.ID, s.user.CurrentTenantID)
+	}
+}
+
+// cacheIdentity returns the identity of the current session
+func (a *App) cacheIdentity() string {
+	a.sessionMu.RLock()
+	defer a.sessionMu.RUnlock()
+	return sessionIdentity(a.session)
+}
+
+// cacheKey returns the response cache key of a request made by identity.
+// Keys start with the identity, so one user's responses are never served to
+// another and can be purged at logout.
+func cacheKey(identity, request string) string {
+	return identity + "\n" + request
+}
+
+// ownsCacheKey reports whether key belongs to the current session. A
+// response is only stored when the session that requested it is still
+// active.
+func (a *App) ownsCacheKey(key string) bool {
+	return strings.HasPrefix(key, cacheKey(a.cacheIdentity(), ""))
+}
+
+// purgeIdentity removes the cached responses of identity
+func (a *App) purgeIdentity(identity string) {
+	if a.cache == nil {
+		return
+	}
+	prefix := cacheKey(identity, "")
+	removed := a.cache.DeleteFunc(func(key string) bool {
+		return strings.HasPrefix(key, prefix)
+	})
+	a.logger.Debug("Purged cached responses of the ended session", "entries", removed)
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// identityHandler logs in any username as the user of that name and serves
+// /profile with the name of the user whose token was sent. It answers every
+// If-None-Match with 304 as if all users shared one representation, so a
+// cache that leaked entries between users would serve the wrong profile.
+func identityHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/identity/login", func(w http.ResponseWriter, r *http.Request) {
+		var login LoginRequest
+		json.NewDecoder(r.Body).Decode(&login)
+		fmt.Fprintf(w, `{"success":true,"data":{"access_token":"token-%[1]s","expires_in":3600,"token_type":"Bearer","user":{"id":"%[1]s","username":"%[1]s"}}}`, login.Username)
+	})
+	mux.HandleFunc("/profile", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"profile"`)
+		if r.Header.Get("If-None-Match") != "" {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		fmt.Fprintf(w, `{"user":%q}`, strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer token-"))
+	})
+	return mux
+}
+
+// profileOf requests /profile and returns the user it was served for
+func profileOf(t *testing.T, app *App) string {
+	t.Helper()
+
+	out, err := app.Request(APIRequest{Method: http.MethodGet, Path: "/profile"})
+	if err != nil {
+		t.Fatalf("Request: %v", err)
+	}
+	profile, _ := out.(map[string]any)
+	user, _ := profile["user"].(string)
+	return user
+}
+
+func TestCachedResponsesIsolatedByUser(t *testing.T) {
+	app, _ := etagTestApp(t, identityHandler())
+
+	for _, user := range []string{"alice", "bob", "alice", "bob"} {
+		if _, err := app.Login(user, "secret"); err != nil {
+			t.Fatalf("Login as %s: %v", user, err)
+		}
+		if got := profileOf(t, app); got != user {
+			t.Errorf("profile while logged in as %s = %q", user, got)
+		}
+	}
+	if app.cache.Len() != 2 {
+		t.Errorf("cache holds %d entries, want one per user", app.cache.Len())
+	}
+}
+
+func TestLogoutPurgesCachedResponses(t *testing.T) {
+	app, _ := etagTestApp(t, identityHandler())
+
+	for _, user := range []string{"alice", "bob"} {
+		if _, err := app.Login(user, "secret"); err != nil {
+			t.Fatalf("Login as %s: %v", user, err)
+		}
+		profileOf(t, app)
+	}
+
+	app.Logout()
+	if _, ok := app.cachedResponse(cacheKey(`user "bob" tenant ""`, "GET "+app.config.API.BaseURL+"/profile")); ok {
+		t.Error("response of the logged out user is still cached")
+	}
+	if _, ok := app.cachedResponse(cacheKey(`user "alice" tenant ""`, "GET "+app.config.API.BaseURL+"/profile")); !ok {
+		t.Error("response of another user was purged")
+	}
+}
+
+func TestSessionIdentity(t *testing.T) {
+	tests := []struct {
+		name    string
+		session *session
+		want    string
+	}{
+		{"logged out", nil, anonymousIdentity},
+		{"user", &session{user: User{ID: "u1", CurrentTenantID: "t1"}}, `user "u1" tenant "t1"`},
+		{"tenant", &session{user: User{ID: "u1", CurrentTenantID: "t2"}}, `user "u1" tenant "t2"`},
+		{"no user", &session{accessToken: "access-1"}, `token "access-1"`},
+		{"crafted ID", &session{user: User{ID: "u1\nGET"}}, `user "u1\nGET" tenant ""`},
+	}
+	for _, tt := range tests {
+		if got := sessionIdentity(tt.session); got != tt.want {
+			t.Errorf("%s: sessionIdentity = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestResponseNotCachedAfterSessionChange(t *testing.T) {
+	app, _ := etagTestApp(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v1"`)
+		io.WriteString(w, `{}`)
+	}))
+
+	key := cacheKey(`user "someone else" tenant ""`, "GET "+app.config.API.BaseURL+"/profile")
+	opts := &requestOptions{}
+	if _, err := app.fetchConditional(app.requestContext(), key, app.config.API.BaseURL+"/profile", opts); err != nil {
+		t.Fatalf("fetchConditional: %v", err)
+	}
+	if app.cache.Len() != 0 {
+		t.Errorf("response of an ended session was cached")
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrRequestCancelled is returned by operations aborted from the frontend
+var ErrRequestCancelled = errors.New("request cancelled")
+
+// cancelRegistry tracks the cancel functions of operations the frontend can
+// abort, keyed by an ID chosen by the caller
+type cancelRegistry struct {
+	mu      sync.Mutex
+	entries map[string]*cancelEntry
+}
+
+type cancelEntry struct {
+	cancel context.CancelCauseFunc
+}
+
+// register derives a cancellable context from parent and stores its cancel
+// function under id, replacing any previous entry. The returned release
+// function removes the entry and must be called once the operation ends.
+// Cancelling the context through the registry sets its cause to
+// ErrRequestCancelled.
+func (r *cancelRegistry) register(parent context.Context, id string) (context.Context, func()) {
+	ctx, cancel := context.WithCancelCause(parent)
+	entry := &cancelEntry{cancel: cancel}
+
+	r.mu.Lock()
+	if r.entries == nil {
+		r.entries = make(map[string]*cancelEntry)
+	}
+	r.entries[id] = entry
+	r.mu.Unlock()
+
+	release := func() {
+		cancel(nil)
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		// Leave entries of later operations with the same ID alone
+		if r.entries[id] == entry {
+			delete(r.entries, id)
+		}
+	}
+	return ctx, release
+}
+
+// cancel aborts the operation registered under id, reporting whether one
+// was found
+func (r *cancelRegistry) cancel(id string) bool {
+	r.mu.Lock()
+	entry, ok := r.entries[id]
+	delete(r.entries, id)
+	r.mu.Unlock()
+
+	if ok {
+		entry.cancel(ErrRequestCancelled)
+	}
+	return ok
+}
+
+// cancelledError returns ErrRequestCancelled in place of err when ctx was
+// cancelled through the registry, so callers can tell a user abort from
+// other failures
+func cancelledError(ctx context.Context, err error) error {
+	if err != nil && errors.Is(context.Cause(ctx), ErrRequestCancelled) {
+		return ErrRequestCancelled
+	}
+	return err
+}
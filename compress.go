@@ -0,0 +1,26 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+)
+
+// compressBody gzips body when api.compress_requests is set and body is
+// larger than api.compress_min_bytes. It returns the body to send and its
+// Content-Encoding, which is empty when body is sent as is.
+func (a *App) compressBody(body []byte) ([]byte, string, error) {
+	api := a.currentConfig().API
+	if !api.CompressRequests || len(body) <= api.CompressMinBytes {
+		return body, "", nil
+	}
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(body); err != nil {
+		return nil, "", err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, "", err
+	}
+	return buf.Bytes(), "gzip", nil
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// compressTestApp creates an app compressing bodies over 64 bytes whose
+// server records the Content-Encoding and decoded body of each request.
+// The first failures requests are answered with 503.
+func compressTestApp(t *testing.T, failures int) (*App, *[]string, *[]string) {
+	t.Helper()
+
+	var encodings, bodies []string
+	app, _ := newTestApp(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		encoding := r.Header.Get("Content-Encoding")
+		var body io.Reader = r.Body
+		if encoding == "gzip" {
+			zr, err := gzip.NewReader(r.Body)
+			if err != nil {
+				t.Errorf("body is not gzip encoded: %v", err)
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			body = zr
+		}
+		data, err := io.ReadAll(body)
+		if err != nil {
+			t.Errorf("reading body: %v", err)
+		}
+		encodings = append(encodings, encoding)
+		bodies = append(bodies, string(data))
+
+		if len(bodies) <= failures {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{}`))
+	}))
+	app.config.API.CompressRequests = true
+	app.config.API.CompressMinBytes = 64
+	return app, &encodings, &bodies
+}
+
+func TestCompressRequests(t *testing.T) {
+	app, encodings, bodies := compressTestApp(t, 0)
+
+	name := strings.Repeat("x", 100)
+	if _, err := app.Request(APIRequest{Method: http.MethodPost, Path: "/items", Body: map[string]string{"name": name}}); err != nil {
+		t.Fatalf("Request: %v", err)
+	}
+	if _, err := app.Request(APIRequest{Method: http.MethodPost, Path: "/items", Body: map[string]string{"name": "x"}}); err != nil {
+		t.Fatalf("Request: %v", err)
+	}
+
+	if want := []string{"gzip", ""}; !slices.Equal(*encodings, want) {
+		t.Errorf("Content-Encoding = %q, want %q", *encodings, want)
+	}
+	if want := []string{`{"name":"` + name + `"}`, `{"name":"x"}`}; !slices.Equal(*bodies, want) {
+		t.Errorf("bodies = %q, want %q", *bodies, want)
+	}
+}
+
+func TestCompressRequestsDisabled(t *testing.T) {
+	app, encodings, _ := compressTestApp(t, 0)
+	app.config.API.CompressRequests = false
+
+	body := map[string]string{"name": strings.Repeat("x", 100)}
+	if _, err := app.Request(APIRequest{Method: http.MethodPost, Path: "/items", Body: body}); err != nil {
+		t.Fatalf("Request: %v", err)
+	}
+	if (*encodings)[0] != "" {
+		t.Errorf("Content-Encoding = %q, want none", (*encodings)[0])
+	}
+}
+
+func TestCompressRequestsOnRetry(t *testing.T) {
+	app, encodings, bodies := compressTestApp(t, 1)
+	app.config.API.RetryCount = 1
+
+	name := strings.Repeat("x", 100)
+	if _, err := app.Request(APIRequest{Method: http.MethodPost, Path: "/items", Body: map[string]string{"name": name}}); err != nil {
+		t.Fatalf("Request: %v", err)
+	}
+
+	want := `{"name":"` + name + `"}`
+	if len(*bodies) != 2 {
+		t.Fatalf("attempts = %d, want 2", len(*bodies))
+	}
+	for i := range *bodies {
+		if (*encodings)[i] != "gzip" || (*bodies)[i] != want {
+			t.Errorf("attempt %d: Content-Encoding %q, body %q; want gzip, %q", i+1, (*encodings)[i], (*bodies)[i], want)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+	"wails-template/internal/config"
+)
+
+// ConfigReloadFailedEvent is the payload of the config:reload-failed event,
+// emitted when a reload is rejected and the last valid config is kept
+type ConfigReloadFailedEvent struct {
+	Errors []string `json:"errors"`
+}
+
+// reloadFailed reports a rejected reload. The app keeps running on the
+// config it had.
+func (a *App) reloadFailed(err error) {
+	messages := []string{err.Error()}
+	var validationErr *config.ValidationError
+	if errors.As(err, &validationErr) {
+		messages = validationErr.Messages
+	}
+
+	a.logger.Warn("Config reload failed, keeping the last valid config", "error", err)
+	a.emitEvent("config:reload-failed", ConfigReloadFailedEvent{Errors: messages})
+}
+
+// logConfigChanges logs each changed config value as "key: old -> new".
+// Secrets are masked by config.Diff.
+func (a *App) logConfigChanges(changes []config.Change) {
+	for _, change := range changes {
+		a.logger.Info("Config value changed", "change", fmt.Sprintf("%s: %v -> %v", change.Path, change.Old, change.New))
+	}
+}
+
+// fileVersion identifies the contents of a file by its size and
+// modification time
+type fileVersion struct {
+	size    int64
+	modTime time.Time
+}
+
+// statVersion returns the current version of the file at path
+func statVersion(path string) (fileVersion, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileVersion{}, err
+	}
+	return fileVersion{size: info.Size(), modTime: info.ModTime()}, nil
+}
+
+// watchConfig starts polling the config file for changes every
+// app.watch_interval. It does nothing when watching is disabled or the
+// config isn't read from a file.
+func (a *App) watchConfig() {
+	interval := a.currentConfig().App.WatchInterval
+	path, ok := config.SourceFile()
+	if interval <= 0 || !ok {
+		return
+	}
+	a.goBackground(func(ctx context.Context) {
+		a.runConfigWatcher(ctx, path, interval)
+	})
+}
+
+// runConfigWatcher reloads the config whenever the file at path changes,
+// until ctx is done. A rejected change is retried with the next change, as
+// the file is usually saved again once the edit is complete.
+func (a *App) runConfigWatcher(ctx context.Context, path string, interval time.Duration) {
+	last, _ := statVersion(path)
+	for a.sleep(ctx, interval) {
+		current, err := statVersion(path)
+		if err != nil || current == last {
+			continue
+		}
+		last = current
+
+		err = func() (err error) {
+			defer a.recoverPanic("ReloadConfig", &err)
+			return a.reloadConfig(auditReload, triggerFileWatcher)
+		}()
+		if err == nil {
+			a.logger.Info("Config reloaded after a file change", "path", path)
+		}
+	}
+}
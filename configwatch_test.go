@@ -0,0 +1,227 @@
+package main
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"wails-template/internal/clock"
+	"wails-template/internal/config"
+)
+
+// setWindowWidth rewrites window.width in the config file at path
+func setWindowWidth(t *testing.T, path string, width string) {
+	t.Helper()
+	setConfigKey(t, path, "width", width)
+}
+
+// setConfigKey rewrites the first line setting key in the config file at
+// path
+func setConfigKey(t *testing.T, path, key, value string) {
+	t.Helper()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(string(data), "\n")
+	for i, line := range lines {
+		if strings.HasPrefix(line, key+" = ") {
+			lines[i] = key + " = " + value
+			break
+		}
+	}
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// reloadFailures returns the errors of the config:reload-failed events
+func reloadFailures(events []emittedEvent) [][]string {
+	var failures [][]string
+	for _, event := range events {
+		if event.name == "config:reload-failed" {
+			failures = append(failures, event.data[0].(ConfigReloadFailedEvent).Errors)
+		}
+	}
+	return failures
+}
+
+func TestReloadConfigKeepsLastValidConfig(t *testing.T) {
+	path := useConfigCopy(t)
+	app, _ := newTestApp(t, http.NotFoundHandler())
+	events := recordEvents(app)
+	good := app.config
+
+	setWindowWidth(t, path, "100")
+	if err := app.ReloadConfig(); err == nil {
+		t.Fatal("ReloadConfig accepted an invalid config")
+	}
+	if app.config != good || config.GetConfig().Window.Width != 1200 {
+		t.Errorf("window width = %d, global %d, want the last valid config", app.config.Window.Width, config.GetConfig().Window.Width)
+	}
+	failures := reloadFailures(*events)
+	if len(failures) != 1 || !slices.Contains(failures[0], "Window width must be at least 400") {
+		t.Errorf("reload failures = %q, want the validation message", failures)
+	}
+
+	setWindowWidth(t, path, "1300")
+	if err := app.ReloadConfig(); err != nil {
+		t.Fatalf("ReloadConfig: %v", err)
+	}
+	if app.config.Window.Width != 1300 || config.GetConfig().Window.Width != 1300 {
+		t.Errorf("window width = %d, global %d, want 1300", app.config.Window.Width, config.GetConfig().Window.Width)
+	}
+}
+
+func TestConfigWatcherRetriesAfterInvalidEdit(t *testing.T) {
+	path := useConfigCopy(t)
+	app, _ := newTestApp(t, http.NotFoundHandler())
+	events := recordEvents(app)
+	fake := clock.NewFake(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	app.clock = fake
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		app.runConfigWatcher(ctx, path, time.Second)
+		close(stopped)
+	}()
+	defer func() {
+		cancel()
+		<-stopped
+	}()
+
+	// poll lets the watcher check the file once
+	poll := func() {
+		t.Helper()
+		waitFor(t, "watcher to sleep", func() bool { return fake.Waiters() == 1 })
+		fake.Advance(time.Second)
+		waitFor(t, "watcher to check the file", func() bool { return fake.Waiters() == 1 })
+	}
+
+	poll()
+	if len(*events) != 0 {
+		t.Errorf("events without a change = %+v, want none", *events)
+	}
+
+	setWindowWidth(t, path, "100")
+	poll()
+	if failures := reloadFailures(*events); len(failures) != 1 || app.config.Window.Width != 1200 {
+		t.Errorf("after an invalid edit: %d failures, window width %d, want 1 and 1200", len(failures), app.config.Window.Width)
+	}
+
+	setWindowWidth(t, path, "1300")
+	poll()
+	if app.config.Window.Width != 1300 {
+		t.Errorf("window width after a valid edit = %d, want 1300", app.config.Window.Width)
+	}
+}
+
+func TestReloadConfigLogsMaskedChanges(t *testing.T) {
+	path := useConfigCopy(t)
+	app, _ := newTestApp(t, http.NotFoundHandler())
+
+	// loggedChanges returns the changes logged so far
+	loggedChanges := func() []string {
+		var changes []string
+		for _, record := range app.logger.Recent(slog.LevelInfo) {
+			if record.Message == "Config value changed" {
+				changes = append(changes, record.Attrs["change"].(string))
+			}
+		}
+		return changes
+	}
+
+	// The test app differs from the file, so the first reload has changes
+	// but the second one doesn't
+	if err := app.ReloadConfig(); err != nil {
+		t.Fatalf("ReloadConfig: %v", err)
+	}
+	before := len(loggedChanges())
+	if err := app.ReloadConfig(); err != nil {
+		t.Fatalf("ReloadConfig: %v", err)
+	}
+	if after := len(loggedChanges()); after != before {
+		t.Errorf("a reload without changes logged %d changes", after-before)
+	}
+
+	cfg := *app.config
+	cfg.Database.Password = "old-password"
+	app.config = &cfg
+	values := config.Values{"database": {"password": "new-password"}, "window": {"width": "1300"}}
+	if err := config.WriteValues(path, values); err != nil {
+		t.Fatal(err)
+	}
+	if err := app.ReloadConfig(); err != nil {
+		t.Fatalf("ReloadConfig: %v", err)
+	}
+
+	changes := loggedChanges()[before:]
+	for _, want := range []string{
+		"database.password: ***MASKED*** -> ***MASKED***",
+		"window.width: 1200 -> 1300",
+	} {
+		if !slices.Contains(changes, want) {
+			t.Errorf("logged changes = %q, missing %q", changes, want)
+		}
+	}
+	for _, change := range changes {
+		if strings.Contains(change, "old-password") || strings.Contains(change, "new-password") {
+			t.Errorf("logged change %q leaks the password", change)
+		}
+	}
+}
+
+// TestReloadConfigDuringRequests reloads the config while requests are in
+// flight, for the race detector
+func TestReloadConfigDuringRequests(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{}`)
+	}))
+	defer srv.Close()
+	path := useConfigCopy(t)
+	setConfigKey(t, path, "base_url", srv.URL)
+	if _, err := config.ReloadConfig(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The app builds its own client, which reloads replace
+	app := NewAppWithClient(nil)
+	t.Cleanup(func() { app.Close() })
+
+	var wg sync.WaitGroup
+	for worker := range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range 25 {
+				query := map[string]string{"worker": strconv.Itoa(worker), "n": strconv.Itoa(i)}
+				if _, err := app.Request(APIRequest{Method: http.MethodGet, Path: "/items", Query: query}); err != nil {
+					t.Errorf("Request: %v", err)
+					return
+				}
+				app.GetRequestQueueStats()
+				app.IsDebugMode()
+			}
+		}()
+	}
+
+	// Change the values behind the client, the slots and the messages
+	for i := range 10 {
+		setConfigKey(t, path, "max_concurrent_requests", strconv.Itoa(i%3+1))
+		setConfigKey(t, path, "width", strconv.Itoa(1200+i))
+		if err := app.ReloadConfig(); err != nil {
+			t.Fatalf("ReloadConfig: %v", err)
+		}
+	}
+	wg.Wait()
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"net/http"
+	"wails-template/internal/config"
+
+	"github.com/wailsapp/wails/v2/pkg/options/assetserver"
+)
+
+// corsMiddleware returns an asset server middleware that sets CORS headers
+// for the origins configured in the security config. It is only active
+// outside production and when CORS is enabled.
+func corsMiddleware(cfg *config.Config) assetserver.Middleware {
+	return func(next http.Handler) http.Handler {
+		if cfg.App.Environment.IsProduction() || !cfg.Security.CORSEnabled {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+			if !cfg.Security.IsOriginAllowed(origin) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+			w.Header().Set("Access-Control-Allow-Credentials", "true")
+			w.Header().Add("Vary", "Origin")
+
+			// Answer preflight requests directly
+			if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+				w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
+				if headers := r.Header.Get("Access-Control-Request-Headers"); headers != "" {
+					w.Header().Set("Access-Control-Allow-Headers", headers)
+				}
+				w.Header().Set("Access-Control-Max-Age", "600")
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
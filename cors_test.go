@@ -0,0 +1,140 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"wails-template/internal/config"
+)
+
+// newCORSHandler wraps a handler that answers 200 with the CORS middleware
+// for the given environment and origins
+func newCORSHandler(env config.Environment, origins ...string) http.Handler {
+	cfg := &config.Config{
+		App:      config.AppConfig{Environment: env},
+		Security: config.SecurityConfig{CORSEnabled: true, CORSOrigins: origins},
+	}
+	return corsMiddleware(cfg)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+}
+
+func TestCORSOriginReflection(t *testing.T) {
+	handler := newCORSHandler(config.Development, "http://localhost:5173", "https://*.example.com")
+
+	tests := []struct {
+		origin  string
+		allowed bool
+	}{
+		{"http://localhost:5173", true},
+		{"https://app.example.com", true},
+		{"https://a.b.example.com", true},
+		{"https://example.com", false},
+		{"http://app.example.com", false},
+		{"https://evil-example.com", false},
+		{"http://localhost:3000", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.origin, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.origin != "" {
+				req.Header.Set("Origin", tt.origin)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			got := rec.Header().Get("Access-Control-Allow-Origin")
+			if tt.allowed && got != tt.origin {
+				t.Errorf("Access-Control-Allow-Origin = %q, want %q", got, tt.origin)
+			}
+			if !tt.allowed && got != "" {
+				t.Errorf("disallowed origin got Access-Control-Allow-Origin = %q", got)
+			}
+			if rec.Code != http.StatusOK {
+				t.Errorf("status = %d, want the request passed through", rec.Code)
+			}
+		})
+	}
+}
+
+func TestCORSPreflight(t *testing.T) {
+	handler := newCORSHandler(config.Development, "http://localhost:5173")
+
+	preflight := func(origin string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodOptions, "/api/items", nil)
+		req.Header.Set("Origin", origin)
+		req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+		req.Header.Set("Access-Control-Request-Headers", "Content-Type, X-Requested-With")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := preflight("http://localhost:5173")
+	if rec.Code != http.StatusNoContent {
+		t.Errorf("allowed preflight status = %d, want 204", rec.Code)
+	}
+	if got := rec.Header().Get("Access-Control-Allow-Headers"); got != "Content-Type, X-Requested-With" {
+		t.Errorf("Access-Control-Allow-Headers = %q", got)
+	}
+	if rec.Header().Get("Access-Control-Allow-Methods") == "" {
+		t.Error("preflight response has no Access-Control-Allow-Methods")
+	}
+
+	rec = preflight("http://localhost:3000")
+	if rec.Code != http.StatusOK || rec.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Errorf("disallowed preflight was answered: status %d, headers %v", rec.Code, rec.Header())
+	}
+}
+
+func TestCORSInactiveInProduction(t *testing.T) {
+	handler := newCORSHandler(config.Production, "*")
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Origin", "http://localhost:5173")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "" {
+		t.Errorf("production set Access-Control-Allow-Origin = %q", got)
+	}
+}
+
+func TestIsOriginAllowed(t *testing.T) {
+	app, _ := newTestApp(t, http.NotFoundHandler())
+	app.config.Security = config.SecurityConfig{
+		CORSEnabled: true,
+		CORSOrigins: []string{"http://localhost:5173", "https://*.example.com"},
+	}
+
+	tests := []struct {
+		origin string
+		want   bool
+	}{
+		{"http://localhost:5173", true},
+		{"HTTP://LOCALHOST:5173", true},
+		{"https://app.example.com", true},
+		{"https://a.b.example.com", true},
+		{"https://example.com", false},
+		{"http://app.example.com", false},
+		{"https://app.example.com.evil.net", false},
+		{"http://localhost:3000", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := app.IsOriginAllowed(tt.origin); got != tt.want {
+			t.Errorf("IsOriginAllowed(%q) = %v, want %v", tt.origin, got, tt.want)
+		}
+	}
+
+	app.config.Security.CORSOrigins = []string{"*"}
+	if !app.IsOriginAllowed("https://anything.test") {
+		t.Error("wildcard origin did not allow an arbitrary origin")
+	}
+
+	app.config.Security.CORSEnabled = false
+	if app.IsOriginAllowed("http://localhost:5173") {
+		t.Error("origin allowed with CORS disabled")
+	}
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// decodeJSON decodes an API response body into out. Numbers decoded into
+// interface values become json.Number rather than float64, so integers
+// such as 64-bit IDs keep their precision.
+func decodeJSON(data []byte, out any) error {
+	return decodeJSONFrom(bytes.NewReader(data), out)
+}
+
+// decodeJSONFrom decodes a single JSON value from r into out like
+// decodeJSON, reading r as the value is decoded instead of buffering it
+func decodeJSONFrom(r io.Reader, out any) error {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	if err := dec.Decode(out); err != nil {
+		return err
+	}
+	if _, err := dec.Token(); !errors.Is(err, io.EOF) {
+		return errors.New("invalid character after top-level value")
+	}
+	return nil
+}
+
+// ID is an identifier the API may send as a JSON string or number. Numbers
+// are kept verbatim, so large integer IDs don't lose precision.
+type ID string
+
+// UnmarshalJSON accepts a JSON string or number
+func (id *ID) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	if len(data) > 0 && data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		*id = ID(s)
+		return nil
+	}
+
+	var n json.Number
+	if err := json.Unmarshal(data, &n); err != nil {
+		return fmt.Errorf("ID must be a string or number: %w", err)
+	}
+	*id = ID(n)
+	return nil
+}
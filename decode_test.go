@@ -0,0 +1,81 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// bigID doesn't fit in a float64 without rounding
+const bigID = "9007199254740993"
+
+func TestRequestKeepsLargeIntegers(t *testing.T) {
+	app, _ := newTestApp(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"id":`+bigID+`,"price":1.5}`)
+	}))
+
+	out, err := app.Request(APIRequest{Method: http.MethodGet, Path: "/items/1"})
+	if err != nil {
+		t.Fatalf("Request: %v", err)
+	}
+	item, _ := out.(map[string]any)
+	if id, ok := item["id"].(json.Number); !ok || id.String() != bigID {
+		t.Errorf("id = %#v, want json.Number %s", item["id"], bigID)
+	}
+
+	// The value is passed on to the frontend unchanged
+	data, err := json.Marshal(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"id":`+bigID) {
+		t.Errorf("marshaled response = %s, want id %s", data, bigID)
+	}
+}
+
+func TestLoginKeepsNumericUserID(t *testing.T) {
+	const body = `{"success":true,"data":{"access_token":"access-1","expires_in":3600,"token_type":"Bearer","user":{"id":` + bigID + `,"username":"admin","current_tenant_id":18446744073709551615}}}`
+	app, _ := newTestApp(t, loginServer(body))
+	app.config.API.ValidateResponses = true
+
+	resp, err := app.Login("admin", "secret")
+	if err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	if user := resp.Data.User; user.ID != bigID || user.CurrentTenantID != "18446744073709551615" {
+		t.Errorf("user ID = %s, tenant = %s, want %s and 18446744073709551615", user.ID, user.CurrentTenantID, bigID)
+	}
+}
+
+func TestIDUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		data    string
+		want    ID
+		wantErr bool
+	}{
+		{`"u1"`, "u1", false},
+		{bigID, bigID, false},
+		{`null`, "", false},
+		{`true`, "", true},
+		{`{}`, "", true},
+	}
+	for _, tt := range tests {
+		var id ID
+		err := json.Unmarshal([]byte(tt.data), &id)
+		if (err != nil) != tt.wantErr || id != tt.want {
+			t.Errorf("Unmarshal(%s) = %q, %v, want %q, error %v", tt.data, id, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestDecodeJSONRejectsTrailingData(t *testing.T) {
+	var out any
+	if err := decodeJSON([]byte(`{"a":1} {"b":2}`), &out); err == nil {
+		t.Error("decodeJSON accepted trailing data")
+	}
+	if err := decodeJSON([]byte(" {\"a\":1}\n"), &out); err != nil {
+		t.Errorf("decodeJSON with surrounding whitespace: %v", err)
+	}
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// errDiagnosticTimeout is reported for a check that didn't finish within
+// selfTestTimeout
+var errDiagnosticTimeout = errors.New("check timed out")
+
+// DiagnosticCheck is the result of a single diagnostics check
+type DiagnosticCheck struct {
+	Name      string `json:"name"`
+	Passed    bool   `json:"passed"`
+	LatencyMs int64  `json:"latencyMs"`
+	Message   string `json:"message,omitempty"`
+}
+
+// DiagnosticsResult aggregates the diagnostics checks. Passed is set when
+// every check passed.
+type DiagnosticsResult struct {
+	Passed bool              `json:"passed"`
+	Checks []DiagnosticCheck `json:"checks"`
+}
+
+// diagnostic is a named check run by runDiagnostics
+type diagnostic struct {
+	name string
+	run  func() error
+}
+
+// RunDiagnostics checks API and database connectivity and whether the log
+// file is writable, running the checks concurrently. Unlike SelfTest every
+// check runs regardless of the self-test settings.
+func (a *App) RunDiagnostics() *DiagnosticsResult {
+	return a.runDiagnostics([]diagnostic{
+		{"api", a.checkAPIReachable},
+		{"database", a.checkDatabaseReachable},
+		{"log", a.checkLogWritable},
+	})
+}
+
+// runDiagnostics runs checks concurrently and returns their results in
+// order. A check that panics or takes longer than selfTestTimeout fails
+// without affecting the others.
+func (a *App) runDiagnostics(checks []diagnostic) *DiagnosticsResult {
+	result := &DiagnosticsResult{Passed: true, Checks: make([]DiagnosticCheck, len(checks))}
+
+	var wg sync.WaitGroup
+	for i, d := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result.Checks[i] = a.runDiagnostic(d)
+		}()
+	}
+	wg.Wait()
+
+	for _, check := range result.Checks {
+		result.Passed = result.Passed && check.Passed
+	}
+	return result
+}
+
+// runDiagnostic runs d, giving up after selfTestTimeout. An abandoned check
+// keeps running in the background until its own timeout ends it.
+func (a *App) runDiagnostic(d diagnostic) DiagnosticCheck {
+	start := a.clock.Now()
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				a.logger.Error("Diagnostics check panicked", "check", d.name, "panic", fmt.Sprint(r))
+				done <- fmt.Errorf("check panicked: %v", r)
+			}
+		}()
+		done <- d.run()
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-a.clock.After(selfTestTimeout):
+		err = errDiagnosticTimeout
+	}
+
+	check := DiagnosticCheck{
+		Name:      d.name,
+		Passed:    err == nil,
+		LatencyMs: a.clock.Now().Sub(start).Milliseconds(),
+	}
+	if err != nil {
+		check.Message = err.Error()
+	}
+	return check
+}
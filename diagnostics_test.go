@@ -0,0 +1,106 @@
+package main
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"wails-template/internal/clock"
+	"wails-template/internal/config"
+)
+
+func TestRunDiagnosticsAggregates(t *testing.T) {
+	app, _ := newTestApp(t, http.NotFoundHandler())
+
+	result := app.runDiagnostics([]diagnostic{
+		{"pass", func() error { return nil }},
+		{"fail", func() error { return errors.New("unreachable") }},
+		{"panic", func() error {
+			var client *http.Client
+			_, err := client.Get("http://example.com")
+			return err
+		}},
+	})
+
+	if result.Passed {
+		t.Error("Passed = true with failing checks")
+	}
+	want := []struct {
+		name    string
+		passed  bool
+		message string
+	}{
+		{"pass", true, ""},
+		{"fail", false, "unreachable"},
+		{"panic", false, "check panicked"},
+	}
+	if len(result.Checks) != len(want) {
+		t.Fatalf("checks = %+v, want %d", result.Checks, len(want))
+	}
+	for i, w := range want {
+		check := result.Checks[i]
+		if check.Name != w.name || check.Passed != w.passed || !strings.Contains(check.Message, w.message) {
+			t.Errorf("check %d = %+v, want %s passed=%v with %q", i, check, w.name, w.passed, w.message)
+		}
+	}
+
+	passing := app.runDiagnostics([]diagnostic{{"pass", func() error { return nil }}})
+	if !passing.Passed {
+		t.Errorf("Passed = false with only passing checks: %+v", passing.Checks)
+	}
+}
+
+func TestRunDiagnosticsTimeout(t *testing.T) {
+	app, _ := newTestApp(t, http.NotFoundHandler())
+	fake := clock.NewFake(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	app.clock = fake
+
+	release := make(chan struct{})
+	defer close(release)
+	resultCh := make(chan *DiagnosticsResult)
+	go func() {
+		resultCh <- app.runDiagnostics([]diagnostic{{"hang", func() error { <-release; return nil }}})
+	}()
+
+	waitFor(t, "check to wait for its timeout", func() bool { return fake.Waiters() == 1 })
+	fake.Advance(selfTestTimeout)
+	check := (<-resultCh).Checks[0]
+
+	if check.Passed || check.Message != errDiagnosticTimeout.Error() {
+		t.Errorf("check = %+v, want a timeout", check)
+	}
+	if check.LatencyMs != selfTestTimeout.Milliseconds() {
+		t.Errorf("latency = %dms, want %dms", check.LatencyMs, selfTestTimeout.Milliseconds())
+	}
+}
+
+func TestRunDiagnostics(t *testing.T) {
+	app, _ := newTestApp(t, http.NotFoundHandler())
+	app.config.Log.Output = config.LogOutputFile
+	app.config.Log.FilePath = filepath.Join(t.TempDir(), "app.log")
+
+	// A port that was just released refuses connections
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := listener.Addr().(*net.TCPAddr)
+	listener.Close()
+	app.config.Database.Host = "127.0.0.1"
+	app.config.Database.Port = addr.Port
+
+	result := app.RunDiagnostics()
+	if result.Passed {
+		t.Error("Passed = true with the database down")
+	}
+	passed := map[string]bool{}
+	for _, check := range result.Checks {
+		passed[check.Name] = check.Passed
+	}
+	if !passed["api"] || passed["database"] || !passed["log"] {
+		t.Errorf("checks = %+v, want api and log to pass and database to fail on port %d", result.Checks, addr.Port)
+	}
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"time"
+	"wails-template/internal/clock"
+)
+
+// hostResolver looks up the addresses of a host. *net.Resolver satisfies it.
+type hostResolver interface {
+	LookupHost(ctx context.Context, host string) ([]string, error)
+}
+
+// dialFunc opens a connection, like net.Dialer.DialContext
+type dialFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
+// dnsCache is a DialContext that caches the addresses a host resolves to
+// for a fixed TTL, so repeated requests don't wait for DNS
+type dnsCache struct {
+	ttl      time.Duration
+	clock    clock.Clock
+	resolver hostResolver
+	dial     dialFunc
+
+	mu      sync.Mutex
+	entries map[string]dnsEntry
+}
+
+type dnsEntry struct {
+	addrs     []string
+	expiresAt time.Time
+}
+
+// newDNSCache creates a DNS cache resolving hosts with resolver and
+// connecting with dial
+func newDNSCache(ttl time.Duration, clk clock.Clock, resolver hostResolver, dial dialFunc) *dnsCache {
+	return &dnsCache{
+		ttl:      ttl,
+		clock:    clk,
+		resolver: resolver,
+		dial:     dial,
+		entries:  make(map[string]dnsEntry),
+	}
+}
+
+// DialContext connects to addr, resolving its host through the cache. When
+// no cached address accepts the connection, the host is looked up again in
+// case it moved.
+func (c *dnsCache) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil || net.ParseIP(host) != nil {
+		return c.dial(ctx, network, addr)
+	}
+
+	addrs, cached, err := c.lookup(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := c.dialAny(ctx, network, addrs, port)
+	if err == nil || !cached || ctx.Err() != nil {
+		return conn, err
+	}
+
+	c.forget(host)
+	if addrs, _, err = c.lookup(ctx, host); err != nil {
+		return nil, err
+	}
+	return c.dialAny(ctx, network, addrs, port)
+}
+
+// lookup returns the addresses of host, reporting whether they came from
+// the cache
+func (c *dnsCache) lookup(ctx context.Context, host string) ([]string, bool, error) {
+	c.mu.Lock()
+	entry, ok := c.entries[host]
+	c.mu.Unlock()
+	if ok && c.clock.Now().Before(entry.expiresAt) {
+		return entry.addrs, true, nil
+	}
+
+	addrs, err := c.resolver.LookupHost(ctx, host)
+	if err != nil {
+		return nil, false, err
+	}
+
+	c.mu.Lock()
+	c.entries[host] = dnsEntry{addrs: addrs, expiresAt: c.clock.Now().Add(c.ttl)}
+	c.mu.Unlock()
+	return addrs, false, nil
+}
+
+// forget removes the cached addresses of host
+func (c *dnsCache) forget(host string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, host)
+}
+
+// dialAny connects to the first address that accepts the connection
+func (c *dnsCache) dialAny(ctx context.Context, network string, addrs []string, port string) (net.Conn, error) {
+	var errs []error
+	for _, ip := range addrs {
+		conn, err := c.dial(ctx, network, net.JoinHostPort(ip, port))
+		if err == nil {
+			return conn, nil
+		}
+		if ctx.Err() != nil {
+			return nil, err
+		}
+		errs = append(errs, err)
+	}
+	if len(errs) == 0 {
+		return nil, errors.New("no addresses to dial")
+	}
+	return nil, errors.Join(errs...)
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+	"wails-template/internal/clock"
+)
+
+// stubResolver resolves every host to the current addrs and counts lookups
+type stubResolver struct {
+	mu      sync.Mutex
+	addrs   []string
+	lookups int
+}
+
+func (r *stubResolver) LookupHost(ctx context.Context, host string) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.lookups++
+	return r.addrs, nil
+}
+
+func (r *stubResolver) set(addrs ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.addrs = addrs
+}
+
+// stubDialer records the dialed addresses and refuses those in down
+type stubDialer struct {
+	dialed []string
+	down   map[string]bool
+}
+
+func (d *stubDialer) dial(ctx context.Context, network, addr string) (net.Conn, error) {
+	d.dialed = append(d.dialed, addr)
+	if d.down[addr] {
+		return nil, &net.OpError{Op: "dial", Net: network, Err: errors.New("connection refused")}
+	}
+	client, server := net.Pipe()
+	server.Close()
+	return client, nil
+}
+
+func newTestDNSCache() (*dnsCache, *stubResolver, *stubDialer, *clock.Fake) {
+	resolver := &stubResolver{addrs: []string{"10.0.0.1"}}
+	dialer := &stubDialer{down: map[string]bool{}}
+	fake := clock.NewFake(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	return newDNSCache(time.Minute, fake, resolver, dialer.dial), resolver, dialer, fake
+}
+
+func TestDNSCacheReusesLookups(t *testing.T) {
+	cache, resolver, dialer, fake := newTestDNSCache()
+	ctx := context.Background()
+
+	for range 3 {
+		conn, err := cache.DialContext(ctx, "tcp", "api.example.com:443")
+		if err != nil {
+			t.Fatalf("DialContext: %v", err)
+		}
+		conn.Close()
+	}
+	if resolver.lookups != 1 {
+		t.Errorf("lookups within the TTL = %d, want 1", resolver.lookups)
+	}
+
+	// After the TTL the host is resolved again
+	resolver.set("10.0.0.2")
+	fake.Advance(2 * time.Minute)
+	conn, err := cache.DialContext(ctx, "tcp", "api.example.com:443")
+	if err != nil {
+		t.Fatalf("DialContext: %v", err)
+	}
+	conn.Close()
+	if resolver.lookups != 2 {
+		t.Errorf("lookups after the TTL = %d, want 2", resolver.lookups)
+	}
+	if last := dialer.dialed[len(dialer.dialed)-1]; last != "10.0.0.2:443" {
+		t.Errorf("dialed %s, want the refreshed address", last)
+	}
+}
+
+func TestDNSCacheRefreshesOnDialFailure(t *testing.T) {
+	cache, resolver, dialer, _ := newTestDNSCache()
+	ctx := context.Background()
+
+	conn, err := cache.DialContext(ctx, "tcp", "api.example.com:443")
+	if err != nil {
+		t.Fatalf("DialContext: %v", err)
+	}
+	conn.Close()
+
+	// The host moved: the cached address refuses, a fresh lookup succeeds
+	dialer.down["10.0.0.1:443"] = true
+	resolver.set("10.0.0.2")
+	conn, err = cache.DialContext(ctx, "tcp", "api.example.com:443")
+	if err != nil {
+		t.Fatalf("DialContext after the move: %v", err)
+	}
+	conn.Close()
+	if resolver.lookups != 2 {
+		t.Errorf("lookups = %d, want 2", resolver.lookups)
+	}
+}
+
+func TestDNSCacheDialsIPDirectly(t *testing.T) {
+	cache, resolver, dialer, _ := newTestDNSCache()
+
+	conn, err := cache.DialContext(context.Background(), "tcp", "192.0.2.7:80")
+	if err != nil {
+		t.Fatalf("DialContext: %v", err)
+	}
+	conn.Close()
+	if resolver.lookups != 0 || dialer.dialed[0] != "192.0.2.7:80" {
+		t.Errorf("lookups = %d, dialed %v, want a direct dial", resolver.lookups, dialer.dialed)
+	}
+}
+
+func TestDNSCacheContextCancelled(t *testing.T) {
+	cache, _, dialer, _ := newTestDNSCache()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := cache.DialContext(ctx, "tcp", "api.example.com:443"); !errors.Is(err, context.Canceled) {
+		t.Errorf("DialContext error = %v, want context.Canceled", err)
+	}
+	if len(dialer.dialed) != 0 {
+		t.Errorf("dialed %v with a cancelled context", dialer.dialed)
+	}
+}
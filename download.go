@@ -0,0 +1,227 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// downloadProgressInterval limits how often download:progress is emitted
+const downloadProgressInterval = 100 * time.Millisecond
+
+// DownloadProgress is the payload of download:progress events
+type DownloadProgress struct {
+	URL        string `json:"url"`
+	Path       string `json:"path"`
+	Downloaded int64  `json:"downloaded"`
+	Total      int64  `json:"total"` // -1 when the size is unknown
+}
+
+// errStalePartial reports a partial download the server cannot resume
+var errStalePartial = errors.New("partial download does not match the remote file")
+
+// DownloadFile streams url to destPath, emitting download:progress events.
+// The body is written to destPath.part and renamed once complete. When the
+// server supports range requests, an interrupted download keeps the partial
+// file and the next call resumes it; otherwise the partial file is removed.
+// A partial file the server rejects is discarded and the download restarted.
+// The download is bounded by api.download_timeout and can be aborted with
+// CancelDownload.
+func (a *App) DownloadFile(url, destPath string) (err error) {
+	defer a.recoverPanic("DownloadFile", &err)
+
+	ctx, release := a.downloads.register(a.requestContext(), destPath)
+	defer release()
+	if timeout := a.currentConfig().API.DownloadTimeout; timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	err = a.download(ctx, url, destPath)
+	if errors.Is(err, errStalePartial) {
+		if err := os.Remove(destPath + ".part"); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove partial download: %w", err)
+		}
+		err = a.download(ctx, url, destPath)
+	}
+	return err
+}
+
+// download makes a single attempt at DownloadFile, resuming from the
+// partial file if there is one
+func (a *App) download(ctx context.Context, url, destPath string) error {
+	cfg := a.currentConfig()
+	partPath := destPath + ".part"
+	var offset int64
+	if info, err := os.Stat(partPath); err == nil {
+		offset = info.Size()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("User-Agent", cfg.API.UserAgent)
+	for key, value := range cfg.API.DefaultHeaders {
+		req.Header.Set(key, value)
+	}
+	if a.isAPIURL(req.URL) {
+		a.setAuthHeader(req)
+	}
+	if offset > 0 {
+		req.Header.Set("Range", "bytes="+strconv.FormatInt(offset, 10)+"-")
+	}
+	if err := a.hooks.beforeRequest(req); err != nil {
+		return fmt.Errorf("request hook failed: %w", err)
+	}
+
+	resp, err := a.currentClient().Do(req)
+	if err != nil {
+		return cancelledError(ctx, fmt.Errorf("failed to download %s: %w", url, err))
+	}
+	defer resp.Body.Close()
+	if err := a.hooks.afterResponse(resp); err != nil {
+		return fmt.Errorf("response hook failed: %w", err)
+	}
+
+	progress := &progressWriter{
+		app:      a,
+		progress: DownloadProgress{URL: url, Path: destPath, Downloaded: offset, Total: -1},
+	}
+
+	flags := os.O_CREATE | os.O_WRONLY
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		// A range starting anywhere but the end of the partial file would
+		// corrupt it
+		if rangeStart(resp) != offset {
+			return errStalePartial
+		}
+		flags |= os.O_APPEND
+	case http.StatusOK:
+		// The server ignored the range and sends the whole file
+		offset = 0
+		progress.progress.Downloaded = 0
+		flags |= os.O_TRUNC
+	case http.StatusRequestedRangeNotSatisfiable:
+		if offset == 0 {
+			return a.newAPIError(resp.StatusCode)
+		}
+		// The partial file already holds the whole file when its size
+		// matches the one reported by the server
+		if completeLength(resp) != offset {
+			return errStalePartial
+		}
+		progress.progress.Total = offset
+		return finishDownload(progress, partPath, destPath)
+	default:
+		return a.newAPIError(resp.StatusCode)
+	}
+
+	if resp.ContentLength >= 0 {
+		progress.progress.Total = offset + resp.ContentLength
+	}
+	resumable := resp.StatusCode == http.StatusPartialContent || resp.Header.Get("Accept-Ranges") == "bytes"
+
+	file, err := os.OpenFile(partPath, flags, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", partPath, err)
+	}
+
+	_, copyErr := io.Copy(file, io.TeeReader(resp.Body, progress))
+	closeErr := file.Close()
+	if err := errors.Join(copyErr, closeErr); err != nil {
+		if !resumable {
+			os.Remove(partPath)
+		}
+		return cancelledError(ctx, fmt.Errorf("failed to download %s: %w", url, err))
+	}
+	return finishDownload(progress, partPath, destPath)
+}
+
+// finishDownload emits the final progress event and moves the completed
+// partial file into place
+func finishDownload(progress *progressWriter, partPath, destPath string) error {
+	progress.emit()
+	if err := os.Rename(partPath, destPath); err != nil {
+		return fmt.Errorf("failed to move download into place: %w", err)
+	}
+	return nil
+}
+
+// isAPIURL reports whether u is served by the API, so the session token
+// may be sent to it. Scheme and host must match the API base URL exactly.
+func (a *App) isAPIURL(u *url.URL) bool {
+	base, err := url.Parse(a.baseURL())
+	if err != nil {
+		return false
+	}
+	return u.Scheme == base.Scheme && u.Host == base.Host
+}
+
+// completeLength returns the file size from a "bytes */size" Content-Range
+// header, or -1 when it is missing or unknown
+func completeLength(resp *http.Response) int64 {
+	size, ok := strings.CutPrefix(resp.Header.Get("Content-Range"), "bytes */")
+	if !ok {
+		return -1
+	}
+	n, err := strconv.ParseInt(size, 10, 64)
+	if err != nil {
+		return -1
+	}
+	return n
+}
+
+// rangeStart returns the first byte position from a "bytes start-end/size"
+// Content-Range header, or -1 when it is missing or malformed
+func rangeStart(resp *http.Response) int64 {
+	byteRange, ok := strings.CutPrefix(resp.Header.Get("Content-Range"), "bytes ")
+	if !ok {
+		return -1
+	}
+	start, _, ok := strings.Cut(byteRange, "-")
+	if !ok {
+		return -1
+	}
+	n, err := strconv.ParseInt(start, 10, 64)
+	if err != nil {
+		return -1
+	}
+	return n
+}
+
+// CancelDownload aborts the download to destPath, reporting whether one
+// was in progress
+func (a *App) CancelDownload(destPath string) bool {
+	return a.downloads.cancel(destPath)
+}
+
+// progressWriter counts the bytes written through it and emits throttled
+// download:progress events
+type progressWriter struct {
+	app      *App
+	progress DownloadProgress
+	lastEmit time.Time
+}
+
+func (w *progressWriter) Write(p []byte) (int, error) {
+	w.progress.Downloaded += int64(len(p))
+	if now := w.app.clock.Now(); now.Sub(w.lastEmit) >= downloadProgressInterval {
+		w.lastEmit = now
+		w.emit()
+	}
+	return len(p), nil
+}
+
+func (w *progressWriter) emit() {
+	w.app.emitEvent("download:progress", w.progress)
+}
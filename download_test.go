@@ -0,0 +1,231 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// downloadContent is the file served by the download tests
+var downloadContent = bytes.Repeat([]byte("0123456789"), 10_000)
+
+// rangeHandler serves downloadContent with range support, counting the
+// requests in hits
+func rangeHandler(hits *atomic.Int32) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		http.ServeContent(w, r, "file.bin", time.Time{}, bytes.NewReader(downloadContent))
+	})
+}
+
+// checkDownload fails the test unless path holds downloadContent and no
+// partial file is left behind
+func checkDownload(t *testing.T, path string) {
+	t.Helper()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if !bytes.Equal(data, downloadContent) {
+		t.Errorf("downloaded %d bytes, want %d matching bytes", len(data), len(downloadContent))
+	}
+	if _, err := os.Stat(path + ".part"); !os.IsNotExist(err) {
+		t.Errorf("partial file left behind: %v", err)
+	}
+}
+
+func TestDownloadFileWithContentLength(t *testing.T) {
+	var hits atomic.Int32
+	app, srv := newTestApp(t, rangeHandler(&hits))
+
+	dest := filepath.Join(t.TempDir(), "file.bin")
+	if err := app.DownloadFile(srv.URL+"/file.bin", dest); err != nil {
+		t.Fatalf("DownloadFile: %v", err)
+	}
+	checkDownload(t, dest)
+}
+
+func TestDownloadFileWithoutContentLength(t *testing.T) {
+	app, srv := newTestApp(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Flushing before the body is complete makes the response chunked
+		for chunk := range slices.Chunk(downloadContent, 4096) {
+			w.Write(chunk)
+			w.(http.Flusher).Flush()
+		}
+	}))
+
+	dest := filepath.Join(t.TempDir(), "file.bin")
+	if err := app.DownloadFile(srv.URL+"/file.bin", dest); err != nil {
+		t.Fatalf("DownloadFile: %v", err)
+	}
+	checkDownload(t, dest)
+}
+
+func TestDownloadFileResumes(t *testing.T) {
+	var ranges []string
+	app, srv := newTestApp(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ranges = append(ranges, r.Header.Get("Range"))
+		http.ServeContent(w, r, "file.bin", time.Time{}, bytes.NewReader(downloadContent))
+	}))
+
+	dest := filepath.Join(t.TempDir(), "file.bin")
+	half := len(downloadContent) / 2
+	if err := os.WriteFile(dest+".part", downloadContent[:half], 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := app.DownloadFile(srv.URL+"/file.bin", dest); err != nil {
+		t.Fatalf("DownloadFile: %v", err)
+	}
+	checkDownload(t, dest)
+	if want := "bytes=" + strconv.Itoa(half) + "-"; len(ranges) != 1 || ranges[0] != want {
+		t.Errorf("Range headers = %q, want [%q]", ranges, want)
+	}
+}
+
+func TestDownloadFileAlreadyComplete(t *testing.T) {
+	var hits atomic.Int32
+	app, srv := newTestApp(t, rangeHandler(&hits))
+
+	// The server answers 416 for a range starting at the end of the file
+	dest := filepath.Join(t.TempDir(), "file.bin")
+	if err := os.WriteFile(dest+".part", downloadContent, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := app.DownloadFile(srv.URL+"/file.bin", dest); err != nil {
+		t.Fatalf("DownloadFile: %v", err)
+	}
+	checkDownload(t, dest)
+	if n := hits.Load(); n != 1 {
+		t.Errorf("server hit %d times, want 1", n)
+	}
+}
+
+func TestDownloadFileRestartsStalePartial(t *testing.T) {
+	var hits atomic.Int32
+	app, srv := newTestApp(t, rangeHandler(&hits))
+
+	// A partial file longer than the remote file cannot be resumed
+	dest := filepath.Join(t.TempDir(), "file.bin")
+	if err := os.WriteFile(dest+".part", append(downloadContent, "stale"...), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := app.DownloadFile(srv.URL+"/file.bin", dest); err != nil {
+		t.Fatalf("DownloadFile: %v", err)
+	}
+	checkDownload(t, dest)
+	if n := hits.Load(); n != 2 {
+		t.Errorf("server hit %d times, want 2", n)
+	}
+}
+
+func TestDownloadFileRestartsShiftedRange(t *testing.T) {
+	var ranges []string
+	app, srv := newTestApp(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ranges = append(ranges, r.Header.Get("Range"))
+		if r.Header.Get("Range") == "" {
+			w.Write(downloadContent)
+			return
+		}
+		// Answer with a range other than the one requested
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes 0-%d/%d", len(downloadContent)-1, len(downloadContent)))
+		w.WriteHeader(http.StatusPartialContent)
+		w.Write(downloadContent)
+	}))
+
+	dest := filepath.Join(t.TempDir(), "file.bin")
+	half := len(downloadContent) / 2
+	if err := os.WriteFile(dest+".part", downloadContent[:half], 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := app.DownloadFile(srv.URL+"/file.bin", dest); err != nil {
+		t.Fatalf("DownloadFile: %v", err)
+	}
+	checkDownload(t, dest)
+	if want := []string{"bytes=" + strconv.Itoa(half) + "-", ""}; !slices.Equal(ranges, want) {
+		t.Errorf("Range headers = %q, want %q", ranges, want)
+	}
+}
+
+func TestDownloadFileRemovesPartialOnFailure(t *testing.T) {
+	app, srv := newTestApp(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Promise more than is sent, without range support
+		w.Header().Set("Content-Length", strconv.Itoa(len(downloadContent)))
+		w.Write(downloadContent[:100])
+	}))
+
+	dest := filepath.Join(t.TempDir(), "file.bin")
+	if err := app.DownloadFile(srv.URL+"/file.bin", dest); err == nil {
+		t.Fatal("DownloadFile succeeded on a truncated body")
+	}
+	for _, path := range []string{dest, dest + ".part"} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("%s exists after a failed download: %v", filepath.Base(path), err)
+		}
+	}
+}
+
+func TestDownloadFileTimeout(t *testing.T) {
+	handler, _ := blockingHandler(t)
+	app, srv := newTestApp(t, handler)
+	app.config.API.DownloadTimeout = 50 * time.Millisecond
+
+	dest := filepath.Join(t.TempDir(), "file.bin")
+	if err := app.DownloadFile(srv.URL+"/file.bin", dest); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("DownloadFile error = %v, want context.DeadlineExceeded", err)
+	}
+}
+
+func TestDownloadFileHeaders(t *testing.T) {
+	headers := make(chan http.Header, 1)
+	app, srv := newTestApp(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers <- r.Header.Clone()
+		io.WriteString(w, "ok")
+	}))
+	app.config.API.DefaultHeaders = map[string]string{"X-Client-Version": "1.0.0"}
+	app.startSession(&LoginData{AccessToken: "access-1", ExpiresIn: 3600, TokenType: "Bearer"})
+
+	// The test server also answers on localhost, which is a different host
+	// than the 127.0.0.1 base URL and must not receive the token
+	otherHost := strings.Replace(srv.URL, "127.0.0.1", "localhost", 1)
+
+	tests := []struct {
+		name     string
+		url      string
+		wantAuth bool
+	}{
+		{"API host", srv.URL + "/file.bin", true},
+		{"other host", otherHost + "/file.bin", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dest := filepath.Join(t.TempDir(), "file.bin")
+			if err := app.DownloadFile(tt.url, dest); err != nil {
+				t.Fatalf("DownloadFile: %v", err)
+			}
+
+			got := <-headers
+			if v := got.Get("X-Client-Version"); v != "1.0.0" {
+				t.Errorf("X-Client-Version = %q, want 1.0.0", v)
+			}
+			if auth := got.Get("Authorization"); (auth != "") != tt.wantAuth {
+				t.Errorf("Authorization = %q, want sent = %v", auth, tt.wantAuth)
+			}
+		})
+	}
+}
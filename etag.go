@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"maps"
+	"net/http"
+	"time"
+)
+
+// cachedResponse is a GET response kept for revalidation with its ETag, or
+// without one as a fallback when the API can't be reached
+type cachedResponse struct {
+	etag     string
+	body     []byte
+	storedAt time.Time
+}
+
+// StaleDataEvent is the payload of the api:stale event, emitted when a
+// request is answered with expired cached data because the API couldn't be
+// reached
+type StaleDataEvent struct {
+	URL       string    `json:"url"`
+	RequestID string    `json:"requestId"`
+	StoredAt  time.Time `json:"storedAt"`
+	Error     string    `json:"error"`
+}
+
+// fetchConditional sends a GET request. When caching is enabled, a response
+// that carried an ETag is cached under key and the next request for it
+// sends If-None-Match, so a 304 response is answered with the cached body.
+// Keys are scoped to the session identity, see cacheKey.
+// An entry lives for the cache TTL after it was stored or last revalidated,
+// after which the response is downloaded in full again.
+//
+// With cache.serve_stale_on_error, responses without an ETag are cached as
+// well and a request failing with a network error is answered with the
+// cached response, even an expired one, emitting api:stale.
+func (a *App) fetchConditional(ctx context.Context, key, url string, opts *requestOptions) (*rawResponse, error) {
+	if a.cache == nil {
+		return a.fetchOnce(ctx, http.MethodGet, url, nil, opts)
+	}
+
+	cached, ok := a.cachedResponse(key)
+	if ok && cached.etag != "" {
+		conditional := *opts
+		conditional.headers = maps.Clone(opts.headers)
+		if conditional.headers == nil {
+			conditional.headers = make(map[string]string)
+		}
+		conditional.headers["If-None-Match"] = cached.etag
+		opts = &conditional
+	}
+
+	resp, err := a.fetchOnce(ctx, http.MethodGet, url, nil, opts)
+	if err != nil {
+		if stale, ok := a.staleResponse(ctx, key, url, err); ok {
+			return stale, nil
+		}
+		return nil, err
+	}
+
+	// The session changed while the request was in flight, its response
+	// must not be stored for the new one
+	if !a.ownsCacheKey(key) {
+		return resp, nil
+	}
+
+	switch {
+	case ok && cached.etag != "" && resp.statusCode == http.StatusNotModified:
+		// Restart the TTL of the revalidated entry
+		a.cache.Set(key, cached)
+		return &rawResponse{statusCode: http.StatusOK, etag: cached.etag, body: cached.body}, nil
+	case resp.statusCode == http.StatusOK && (resp.etag != "" || a.currentConfig().Cache.ServeStaleOnError):
+		a.cache.Set(key, &cachedResponse{etag: resp.etag, body: resp.body, storedAt: a.clock.Now()})
+	case resp.statusCode == http.StatusOK:
+		a.cache.Delete(key)
+	}
+	return resp, nil
+}
+
+// cachedResponse returns the response cached under key
+func (a *App) cachedResponse(key string) (*cachedResponse, bool) {
+	value, ok := a.cache.Get(key)
+	if !ok {
+		return nil, false
+	}
+	cached, ok := value.(*cachedResponse)
+	return cached, ok
+}
+
+// staleResponse returns the response cached under key, even when it has
+// expired, if serving stale data is enabled and err is a network error
+func (a *App) staleResponse(ctx context.Context, key, url string, err error) (*rawResponse, bool) {
+	if !a.currentConfig().Cache.ServeStaleOnError || !isRetryableError(err) {
+		return nil, false
+	}
+	value, _, ok := a.cache.GetStale(key)
+	if !ok {
+		return nil, false
+	}
+	cached, ok := value.(*cachedResponse)
+	if !ok {
+		return nil, false
+	}
+
+	a.requestLogger(ctx).Warn("API unreachable, serving cached data", "url", url, "stored_at", cached.storedAt, "error", err)
+	a.emitEvent("api:stale", StaleDataEvent{
+		URL:       url,
+		RequestID: requestIDFrom(ctx),
+		StoredAt:  cached.storedAt,
+		Error:     err.Error(),
+	})
+	return &rawResponse{statusCode: http.StatusOK, etag: cached.etag, body: cached.body}, true
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+	"wails-template/internal/cache"
+	"wails-template/internal/clock"
+)
+
+// etagHandler serves body with a fixed ETag and answers a matching
+// If-None-Match with 304. conditional counts the conditional requests.
+func etagHandler(hits, conditional *atomic.Int32) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.Header().Set("ETag", `"v1"`)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			conditional.Add(1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		io.WriteString(w, `{"id":"u1"}`)
+	})
+}
+
+// etagTestApp creates an app whose response cache runs on a fake clock
+func etagTestApp(t *testing.T, handler http.Handler) (*App, *clock.Fake) {
+	t.Helper()
+
+	app, _ := newTestApp(t, handler)
+	fake := clock.NewFake(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	app.cache = cache.New(time.Minute, fake)
+	t.Cleanup(app.cache.Close)
+	return app, fake
+}
+
+func TestConditionalGETServesCachedBody(t *testing.T) {
+	var hits, conditional atomic.Int32
+	app, fake := etagTestApp(t, etagHandler(&hits, &conditional))
+
+	for i := range 2 {
+		out, err := app.Request(APIRequest{Method: http.MethodGet, Path: "/identity/me"})
+		if err != nil {
+			t.Fatalf("Request %d: %v", i, err)
+		}
+		if user, ok := out.(map[string]any); !ok || user["id"] != "u1" {
+			t.Errorf("Request %d = %v, want the cached body", i, out)
+		}
+	}
+	if hits.Load() != 2 || conditional.Load() != 1 {
+		t.Errorf("server saw %d requests, %d conditional, want 2 and 1", hits.Load(), conditional.Load())
+	}
+
+	// Once the entry expires the response is downloaded in full again
+	fake.Advance(2 * time.Minute)
+	if _, err := app.Request(APIRequest{Method: http.MethodGet, Path: "/identity/me"}); err != nil {
+		t.Fatalf("Request: %v", err)
+	}
+	if conditional.Load() != 1 {
+		t.Errorf("server saw %d conditional requests after expiry, want 1", conditional.Load())
+	}
+}
+
+func TestConditionalGETOnlyForGET(t *testing.T) {
+	var hits, conditional atomic.Int32
+	app, _ := etagTestApp(t, etagHandler(&hits, &conditional))
+
+	for range 2 {
+		if _, err := app.Request(APIRequest{Method: http.MethodPost, Path: "/items", Body: map[string]int{"n": 1}}); err != nil {
+			t.Fatalf("Request: %v", err)
+		}
+	}
+	if conditional.Load() != 0 || app.cache.Len() != 0 {
+		t.Errorf("POST was cached: %d conditional requests, %d entries", conditional.Load(), app.cache.Len())
+	}
+}
+
+func TestConditionalGETDisabled(t *testing.T) {
+	var hits, conditional atomic.Int32
+	app, _ := newTestApp(t, etagHandler(&hits, &conditional))
+	app.cache = nil
+
+	for range 2 {
+		if _, err := app.Request(APIRequest{Method: http.MethodGet, Path: "/identity/me"}); err != nil {
+			t.Fatalf("Request: %v", err)
+		}
+	}
+	if conditional.Load() != 0 {
+		t.Errorf("server saw %d conditional requests, want 0", conditional.Load())
+	}
+}
+
+// staleTestApp creates an app whose cache keeps expired responses for an
+// hour, caching /items from a server that the test can stop
+func staleTestApp(t *testing.T, serveStale bool) (*App, *clock.Fake, *httptest.Server) {
+	t.Helper()
+
+	app, srv := newTestApp(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"id":"u1"}`)
+	}))
+	fake := clock.NewFake(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	app.clock = fake
+	app.cache = cache.New(time.Minute, fake, cache.WithStaleFor(time.Hour))
+	t.Cleanup(app.cache.Close)
+	app.config.Cache.ServeStaleOnError = serveStale
+
+	if _, err := app.Request(APIRequest{Method: http.MethodGet, Path: "/items"}); err != nil {
+		t.Fatalf("Request: %v", err)
+	}
+	return app, fake, srv
+}
+
+func TestServeStaleOnNetworkError(t *testing.T) {
+	app, fake, srv := staleTestApp(t, true)
+	srv.Close()
+	fake.Advance(10 * time.Minute)
+
+	out, err := app.Request(APIRequest{Method: http.MethodGet, Path: "/items"})
+	if err != nil {
+		t.Fatalf("Request with the API offline: %v", err)
+	}
+	if user, ok := out.(map[string]any); !ok || user["id"] != "u1" {
+		t.Errorf("Request = %v, want the cached body", out)
+	}
+	if !loggedMessage(app, "API unreachable, serving cached data") {
+		t.Error("serving stale data was not logged")
+	}
+
+	// Past the stale period the error is returned
+	fake.Advance(time.Hour)
+	if _, err := app.Request(APIRequest{Method: http.MethodGet, Path: "/items"}); err == nil {
+		t.Error("Request succeeded with data past the stale period")
+	}
+}
+
+func TestServeStaleDisabled(t *testing.T) {
+	app, _, srv := staleTestApp(t, false)
+	srv.Close()
+
+	if _, err := app.Request(APIRequest{Method: http.MethodGet, Path: "/items"}); err == nil {
+		t.Error("Request succeeded with the API offline and serve_stale_on_error off")
+	}
+}
+
+func TestServeStaleNotOnAPIError(t *testing.T) {
+	var fail atomic.Bool
+	app, _ := newTestApp(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fail.Load() {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		io.WriteString(w, `{"id":"u1"}`)
+	}))
+	app.cache = cache.New(time.Minute, clock.New(), cache.WithStaleFor(time.Hour))
+	t.Cleanup(app.cache.Close)
+	app.config.Cache.ServeStaleOnError = true
+
+	if _, err := app.Request(APIRequest{Method: http.MethodGet, Path: "/items"}); err != nil {
+		t.Fatalf("Request: %v", err)
+	}
+	// The API is reachable, so its error is reported
+	fail.Store(true)
+	var apiErr *APIError
+	if _, err := app.Request(APIRequest{Method: http.MethodGet, Path: "/items"}); !errors.As(err, &apiErr) {
+		t.Errorf("Request error = %v, want the APIError", err)
+	}
+}
+
+// loggedMessage reports whether app logged a record with message
+func loggedMessage(app *App, message string) bool {
+	for _, record := range app.logger.Recent(slog.LevelDebug) {
+		if record.Message == message {
+			return true
+		}
+	}
+	return false
+}
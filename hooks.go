@@ -0,0 +1,64 @@
+package main
+
+import (
+	"net/http"
+	"sync"
+)
+
+// RequestHook runs before a request is sent. Returning an error aborts the
+// call.
+type RequestHook func(*http.Request) error
+
+// ResponseHook runs after a response is received, before it is read or
+// retried. Returning an error aborts the call.
+type ResponseHook func(*http.Response) error
+
+// requestHooks holds the hooks run around every API call, in the order
+// they were added
+type requestHooks struct {
+	mu       sync.RWMutex
+	request  []RequestHook
+	response []ResponseHook
+}
+
+// AddRequestHook registers a hook run before every request
+func (h *requestHooks) AddRequestHook(hook RequestHook) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.request = append(h.request, hook)
+}
+
+// AddResponseHook registers a hook run after every response
+func (h *requestHooks) AddResponseHook(hook ResponseHook) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.response = append(h.response, hook)
+}
+
+// beforeRequest runs the request hooks, stopping at the first error
+func (h *requestHooks) beforeRequest(req *http.Request) error {
+	h.mu.RLock()
+	hooks := h.request
+	h.mu.RUnlock()
+
+	for _, hook := range hooks {
+		if err := hook(req); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// afterResponse runs the response hooks, stopping at the first error
+func (h *requestHooks) afterResponse(resp *http.Response) error {
+	h.mu.RLock()
+	hooks := h.response
+	h.mu.RUnlock()
+
+	for _, hook := range hooks {
+		if err := hook(resp); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestHookOrder(t *testing.T) {
+	var traceHeader string
+	app, _ := newTestApp(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceHeader = r.Header.Get("X-Trace")
+		io.WriteString(w, `{}`)
+	}))
+
+	var calls []string
+	app.hooks.AddRequestHook(func(req *http.Request) error {
+		calls = append(calls, "request 1")
+		req.Header.Set("X-Trace", "first")
+		return nil
+	})
+	app.hooks.AddRequestHook(func(req *http.Request) error {
+		calls = append(calls, "request 2")
+		req.Header.Set("X-Trace", req.Header.Get("X-Trace")+",second")
+		return nil
+	})
+	app.hooks.AddResponseHook(func(resp *http.Response) error {
+		calls = append(calls, "response 1")
+		return nil
+	})
+	app.hooks.AddResponseHook(func(resp *http.Response) error {
+		calls = append(calls, "response 2")
+		return nil
+	})
+
+	if _, err := app.Request(APIRequest{Method: http.MethodPost, Path: "/items"}); err != nil {
+		t.Fatalf("Request: %v", err)
+	}
+	if got := strings.Join(calls, ", "); got != "request 1, request 2, response 1, response 2" {
+		t.Errorf("hook order = %s", got)
+	}
+	if traceHeader != "first,second" {
+		t.Errorf("X-Trace = %q, want both request hooks applied in order", traceHeader)
+	}
+}
+
+func TestRequestHookErrorAborts(t *testing.T) {
+	var hits atomic.Int32
+	app, _ := newTestApp(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+	}))
+
+	errHook := errors.New("blocked by hook")
+	var laterHookRan bool
+	app.hooks.AddRequestHook(func(*http.Request) error { return errHook })
+	app.hooks.AddRequestHook(func(*http.Request) error {
+		laterHookRan = true
+		return nil
+	})
+
+	_, err := app.Request(APIRequest{Method: http.MethodPost, Path: "/items"})
+	if !errors.Is(err, errHook) {
+		t.Errorf("error = %v, want the hook error", err)
+	}
+	if hits.Load() != 0 || laterHookRan {
+		t.Errorf("call continued after the hook failed: hits = %d, later hook ran = %v", hits.Load(), laterHookRan)
+	}
+}
+
+func TestResponseHookErrorAborts(t *testing.T) {
+	var hits atomic.Int32
+	app, _ := newTestApp(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	app.config.API.RetryCount = 2
+
+	errHook := errors.New("rejected by hook")
+	app.hooks.AddResponseHook(func(resp *http.Response) error {
+		if resp.StatusCode == http.StatusServiceUnavailable {
+			return errHook
+		}
+		return nil
+	})
+
+	_, err := app.Request(APIRequest{Method: http.MethodPost, Path: "/items"})
+	if !errors.Is(err, errHook) {
+		t.Errorf("error = %v, want the hook error", err)
+	}
+	if hits.Load() != 1 {
+		t.Errorf("hits = %d, a failing response hook must not be retried", hits.Load())
+	}
+}
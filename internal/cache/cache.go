@@ -0,0 +1,293 @@
+// Package cache provides a concurrency-safe in-memory cache whose entries
+// expire after a TTL.
+package cache
+
+import (
+	"container/list"
+	"hash/fnv"
+	"sync"
+	"time"
+	"wails-template/internal/clock"
+)
+
+// shardCount is the number of independently locked shards. Spreading keys
+// over shards keeps readers and writers from contending on a single lock,
+// including while the sweeper runs.
+const shardCount = 16
+
+// Sweep interval bounds. The sweeper runs at half the TTL within these.
+const (
+	minSweepInterval = time.Second
+	maxSweepInterval = time.Minute
+)
+
+// Cache is an in-memory key/value cache with a fixed TTL. Expired entries
+// are never returned and are removed by a background sweeper, so memory is
+// reclaimed even for keys that are no longer requested.
+type Cache struct {
+	ttl      time.Duration
+	clock    clock.Clock
+	policy   Policy
+	shardCap int           // 0 = unlimited
+	staleFor time.Duration // how long expired entries are kept for GetStale
+	shards   [shardCount]shard
+
+	done      chan struct{}
+	closeOnce sync.Once
+	sweeper   sync.WaitGroup
+}
+
+type shard struct {
+	mu      sync.RWMutex
+	entries map[string]*entry
+	// order holds the keys by the time they were set, oldest first. With a
+	// fixed TTL this is also the order in which they expire.
+	order list.List
+
+	// usage picks the key to evict, nil for FIFO. Get updates it under the
+	// read lock, so it is guarded by its own mutex there.
+	usageMu sync.Mutex
+	usage   tracker
+}
+
+type entry struct {
+	value     any
+	expiresAt time.Time
+	elem      *list.Element // in shard.order
+}
+
+// Option configures a Cache
+type Option func(*Cache)
+
+// WithMaxItems limits the cache to about n entries. Keys are spread over
+// shards that hold at most n/16 entries each, so a full shard may evict an
+// entry while the cache as a whole holds fewer than n.
+func WithMaxItems(n int) Option {
+	return func(c *Cache) {
+		if n > 0 {
+			c.shardCap = (n + shardCount - 1) / shardCount
+		}
+	}
+}
+
+// WithPolicy selects the entry evicted when the cache is full. LRU is the
+// default and is also used for unknown policies, see ParsePolicy.
+func WithPolicy(policy Policy) Option {
+	return func(c *Cache) {
+		c.policy = policy
+	}
+}
+
+// WithStaleFor keeps expired entries for d after they expire, so GetStale
+// can still return them. Get never returns expired entries.
+func WithStaleFor(d time.Duration) Option {
+	return func(c *Cache) {
+		c.staleFor = max(d, 0)
+	}
+}
+
+// New creates a cache whose entries expire ttl after they are set and
+// starts its sweeper. Close stops the sweeper.
+func New(ttl time.Duration, clk clock.Clock, opts ...Option) *Cache {
+	c := &Cache{
+		ttl:    ttl,
+		clock:  clk,
+		policy: LRU,
+		done:   make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	for i := range c.shards {
+		c.shards[i].entries = make(map[string]*entry)
+		c.shards[i].usage = newTracker(c.policy)
+	}
+
+	c.sweeper.Add(1)
+	go c.runSweeper(min(max(ttl/2, minSweepInterval), maxSweepInterval))
+	return c
+}
+
+// Get returns the value stored under key unless it has expired
+func (c *Cache) Get(key string) (any, bool) {
+	s := c.shard(key)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	e, ok := s.entries[key]
+	if !ok || !c.clock.Now().Before(e.expiresAt) {
+		return nil, false
+	}
+	s.touch(key)
+	return e.value, true
+}
+
+// GetStale returns the value stored under key like Get, but also returns
+// values that expired no longer than the stale period ago, reporting
+// whether the value is stale
+func (c *Cache) GetStale(key string) (value any, stale bool, ok bool) {
+	s := c.shard(key)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	e, ok := s.entries[key]
+	now := c.clock.Now()
+	if !ok || !now.Before(e.expiresAt.Add(c.staleFor)) {
+		return nil, false, false
+	}
+	s.touch(key)
+	return e.value, !now.Before(e.expiresAt), true
+}
+
+// Set stores value under key, replacing any previous value and restarting
+// its TTL. Replacing a value counts as a use. When the key is new and its
+// shard is full, an expired entry or else the entry chosen by the eviction
+// policy is removed first.
+func (c *Cache) Set(key string, value any) {
+	now := c.clock.Now()
+	s := c.shard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if e, ok := s.entries[key]; ok {
+		e.value = value
+		e.expiresAt = now.Add(c.ttl)
+		s.order.MoveToBack(e.elem)
+		if s.usage != nil {
+			s.usage.access(key)
+		}
+		return
+	}
+
+	if c.shardCap > 0 && len(s.entries) >= c.shardCap {
+		s.remove(c.victim(s, now))
+	}
+	s.entries[key] = &entry{value: value, expiresAt: now.Add(c.ttl), elem: s.order.PushBack(key)}
+	if s.usage != nil {
+		s.usage.add(key)
+	}
+}
+
+// victim returns the key to evict from the full shard s: the first entry to
+// expire if it has, else the one chosen by the policy. The caller must hold
+// the shard lock.
+func (c *Cache) victim(s *shard, now time.Time) string {
+	oldest := s.order.Front().Value.(string)
+	if s.usage == nil || !now.Before(s.entries[oldest].expiresAt) {
+		return oldest
+	}
+	if key, ok := s.usage.victim(); ok {
+		return key
+	}
+	return oldest
+}
+
+// Delete removes key from the cache
+func (c *Cache) Delete(key string) {
+	s := c.shard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.remove(key)
+}
+
+// DeleteFunc removes every entry whose key satisfies match and returns how
+// many were removed. Shards are locked one at a time.
+func (c *Cache) DeleteFunc(match func(key string) bool) int {
+	removed := 0
+	for i := range c.shards {
+		s := &c.shards[i]
+		s.mu.Lock()
+		for key := range s.entries {
+			if match(key) {
+				s.remove(key)
+				removed++
+			}
+		}
+		s.mu.Unlock()
+	}
+	return removed
+}
+
+// Len returns the number of stored entries, including expired entries the
+// sweeper hasn't removed yet
+func (c *Cache) Len() int {
+	n := 0
+	for i := range c.shards {
+		s := &c.shards[i]
+		s.mu.RLock()
+		n += len(s.entries)
+		s.mu.RUnlock()
+	}
+	return n
+}
+
+// Sweep removes all expired entries, after their stale period when one is
+// set, and returns how many were removed. Shards are locked one at a time.
+func (c *Cache) Sweep() int {
+	now := c.clock.Now()
+	removed := 0
+	for i := range c.shards {
+		s := &c.shards[i]
+		s.mu.Lock()
+		// Entries expire in the order they were set
+		for front := s.order.Front(); front != nil; front = s.order.Front() {
+			key := front.Value.(string)
+			if now.Before(s.entries[key].expiresAt.Add(c.staleFor)) {
+				break
+			}
+			s.remove(key)
+			removed++
+		}
+		s.mu.Unlock()
+	}
+	return removed
+}
+
+// Close stops the sweeper. The cache remains usable but expired entries are
+// only skipped, not removed. It is safe to call multiple times.
+func (c *Cache) Close() {
+	c.closeOnce.Do(func() {
+		close(c.done)
+		c.sweeper.Wait()
+	})
+}
+
+func (c *Cache) runSweeper(interval time.Duration) {
+	defer c.sweeper.Done()
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-c.clock.After(interval):
+			c.Sweep()
+		}
+	}
+}
+
+func (c *Cache) shard(key string) *shard {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return &c.shards[h.Sum32()%shardCount]
+}
+
+// remove deletes key from the shard. The caller must hold the shard lock.
+func (s *shard) remove(key string) {
+	e, ok := s.entries[key]
+	if !ok {
+		return
+	}
+	delete(s.entries, key)
+	s.order.Remove(e.elem)
+	if s.usage != nil {
+		s.usage.remove(key)
+	}
+}
+
+// touch counts a read of key. The caller must hold at least the read lock.
+func (s *shard) touch(key string) {
+	if s.usage != nil {
+		s.usageMu.Lock()
+		s.usage.access(key)
+		s.usageMu.Unlock()
+	}
+}
@@ -0,0 +1,376 @@
+package cache
+
+import (
+	"math/rand"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"wails-template/internal/clock"
+)
+
+// newTestCache creates a cache on a fake clock that is closed when the test
+// ends
+func newTestCache(t *testing.T, ttl time.Duration) (*Cache, *clock.Fake) {
+	t.Helper()
+
+	clk := clock.NewFake(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	c := New(ttl, clk)
+	t.Cleanup(c.Close)
+	return c, clk
+}
+
+// waitFor polls cond until it holds, failing the test after five seconds
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestGetExpires(t *testing.T) {
+	c, clk := newTestCache(t, time.Minute)
+	c.Set("key", "value")
+
+	if v, ok := c.Get("key"); !ok || v != "value" {
+		t.Fatalf("Get = %v, %v, want value, true", v, ok)
+	}
+
+	clk.Advance(time.Minute)
+	if v, ok := c.Get("key"); ok {
+		t.Errorf("Get after TTL = %v, want miss", v)
+	}
+}
+
+func TestSetRestartsTTL(t *testing.T) {
+	c, clk := newTestCache(t, time.Minute)
+	c.Set("key", 1)
+
+	clk.Advance(45 * time.Second)
+	c.Set("key", 2)
+	clk.Advance(45 * time.Second)
+
+	if v, ok := c.Get("key"); !ok || v != 2 {
+		t.Errorf("Get = %v, %v, want 2, true", v, ok)
+	}
+}
+
+func TestDeleteFunc(t *testing.T) {
+	c, _ := newTestCache(t, time.Minute)
+	for i := range 40 {
+		c.Set("user:"+strconv.Itoa(i%2)+"/"+strconv.Itoa(i), i)
+	}
+
+	removed := c.DeleteFunc(func(key string) bool { return strings.HasPrefix(key, "user:0/") })
+	if removed != 20 || c.Len() != 20 {
+		t.Errorf("removed %d entries, %d left, want 20 and 20", removed, c.Len())
+	}
+	if _, ok := c.Get("user:0/0"); ok {
+		t.Error("matching entry still stored")
+	}
+	if _, ok := c.Get("user:1/1"); !ok {
+		t.Error("other entry was removed")
+	}
+}
+
+func TestSweeperRemovesExpiredEntries(t *testing.T) {
+	c, clk := newTestCache(t, 10*time.Second)
+	for i := range 100 {
+		c.Set(strconv.Itoa(i), i)
+	}
+
+	// The sweeper runs every 5s. The first sweep removes nothing, the
+	// second removes the first entries but not the one set in between.
+	waitFor(t, "sweeper", func() bool { return clk.Waiters() == 1 })
+	clk.Advance(5 * time.Second)
+	c.Set("fresh", true)
+
+	waitFor(t, "sweeper", func() bool { return clk.Waiters() == 1 })
+	clk.Advance(5 * time.Second)
+
+	waitFor(t, "expired entries to be swept", func() bool { return c.Len() == 1 })
+	if _, ok := c.Get("fresh"); !ok {
+		t.Error("unexpired entry was swept")
+	}
+}
+
+func TestCloseStopsSweeper(t *testing.T) {
+	c, clk := newTestCache(t, 10*time.Second)
+	c.Set("key", "value")
+	c.Close()
+
+	clk.Advance(time.Minute)
+	if n := c.Len(); n != 1 {
+		t.Errorf("Len after Close = %d, want 1", n)
+	}
+	if _, ok := c.Get("key"); ok {
+		t.Error("Get returned an expired entry")
+	}
+}
+
+func TestConcurrentAccess(t *testing.T) {
+	c, clk := newTestCache(t, time.Second)
+
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range 1000 {
+				key := strconv.Itoa(i*1000 + j)
+				c.Set(key, j)
+				c.Get(key)
+				if j%100 == 0 {
+					c.Sweep()
+					c.Delete(key)
+				}
+			}
+		}()
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for range 10 {
+			clk.Advance(time.Second)
+		}
+	}()
+	wg.Wait()
+}
+
+// sameShardKeys returns n keys that are stored in the same shard of c
+func sameShardKeys(c *Cache, n int) []string {
+	var keys []string
+	want := c.shard("0")
+	for i := 0; len(keys) < n; i++ {
+		if key := strconv.Itoa(i); c.shard(key) == want {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+func TestMaxItemsEviction(t *testing.T) {
+	tests := []struct {
+		policy  Policy
+		evicted int
+	}{
+		// The first key is read after both were set, so LRU and LFU evict
+		// the second while FIFO evicts the first
+		{LRU, 1},
+		{LFU, 1},
+		{FIFO, 0},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.policy), func(t *testing.T) {
+			clk := clock.NewFake(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+			c := New(time.Hour, clk, WithMaxItems(2*shardCount), WithPolicy(tt.policy))
+			defer c.Close()
+
+			keys := sameShardKeys(c, 3)
+			c.Set(keys[0], 0)
+			clk.Advance(time.Second)
+			c.Set(keys[1], 1)
+			clk.Advance(time.Second)
+			c.Get(keys[0])
+			clk.Advance(time.Second)
+			c.Set(keys[2], 2)
+
+			for i, key := range keys {
+				if _, ok := c.Get(key); ok == (i == tt.evicted) {
+					t.Errorf("key %d cached = %v, want %v", i, ok, i != tt.evicted)
+				}
+			}
+		})
+	}
+}
+
+func TestMaxItemsEvictsExpiredFirst(t *testing.T) {
+	clk := clock.NewFake(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	c := New(time.Minute, clk, WithMaxItems(2*shardCount))
+	defer c.Close()
+
+	keys := sameShardKeys(c, 3)
+	c.Set(keys[0], 0)
+	clk.Advance(time.Minute)
+	c.Set(keys[1], 1)
+	c.Get(keys[1])
+	c.Set(keys[2], 2)
+
+	if _, ok := c.Get(keys[1]); !ok {
+		t.Error("unexpired entry was evicted instead of the expired one")
+	}
+	if n := c.Len(); n != 2 {
+		t.Errorf("Len = %d, want 2", n)
+	}
+}
+
+func TestMaxItemsReplaceDoesNotEvict(t *testing.T) {
+	clk := clock.NewFake(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	c := New(time.Hour, clk, WithMaxItems(shardCount))
+	defer c.Close()
+
+	key := sameShardKeys(c, 1)[0]
+	c.Set(key, 1)
+	c.Set(key, 2)
+	if v, ok := c.Get(key); !ok || v != 2 {
+		t.Errorf("Get = %v, %v, want 2, true", v, ok)
+	}
+}
+
+// stored reports whether key is in c without counting a use, unlike Get
+func stored(c *Cache, key string) bool {
+	s := c.shard(key)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, ok := s.entries[key]
+	return ok
+}
+
+func TestLFUEvictionOrder(t *testing.T) {
+	clk := clock.NewFake(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	c := New(time.Hour, clk, WithMaxItems(3*shardCount), WithPolicy(LFU))
+	defer c.Close()
+
+	keys := sameShardKeys(c, 5)
+	for _, key := range keys[:3] {
+		c.Set(key, key)
+	}
+	// Uses: key 0 three times, key 1 once, key 2 twice
+	for _, i := range []int{0, 0, 0, 1, 2, 2} {
+		c.Get(keys[i])
+	}
+
+	// The least frequently used key goes first, then among the new key
+	// and key 2 the less frequent new key
+	c.Set(keys[3], 3)
+	if stored(c, keys[1]) {
+		t.Error("key 1 was kept, want it evicted as the least frequently used")
+	}
+	c.Set(keys[4], 4)
+	if stored(c, keys[3]) {
+		t.Error("key 3 was kept, want it evicted as the least frequently used")
+	}
+	for _, i := range []int{0, 2, 4} {
+		if _, ok := c.Get(keys[i]); !ok {
+			t.Errorf("key %d was evicted", i)
+		}
+	}
+}
+
+func TestLFUTieEvictsLeastRecentlyUsed(t *testing.T) {
+	clk := clock.NewFake(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	c := New(time.Hour, clk, WithMaxItems(2*shardCount), WithPolicy(LFU))
+	defer c.Close()
+
+	keys := sameShardKeys(c, 3)
+	c.Set(keys[0], 0)
+	c.Set(keys[1], 1)
+	c.Get(keys[1])
+	c.Get(keys[0])
+	c.Set(keys[2], 2)
+
+	// Both were used twice, key 1 reached that count first
+	if _, ok := c.Get(keys[1]); ok {
+		t.Error("key 1 was kept, want it evicted")
+	}
+	if _, ok := c.Get(keys[0]); !ok {
+		t.Error("key 0 was evicted")
+	}
+}
+
+func TestLFUTrackerBuckets(t *testing.T) {
+	tr := newLFUTracker()
+	for _, key := range []string{"a", "b", "c"} {
+		tr.add(key)
+	}
+	tr.access("a")
+	tr.access("a")
+	tr.access("b")
+	tr.remove("c")
+
+	// Buckets for counts 2 and 3 remain, the emptied count 1 bucket is gone
+	var counts []int
+	for e := tr.buckets.Front(); e != nil; e = e.Next() {
+		counts = append(counts, e.Value.(*lfuBucket).count)
+	}
+	if len(counts) != 2 || counts[0] != 2 || counts[1] != 3 {
+		t.Errorf("bucket counts = %v, want [2 3]", counts)
+	}
+	if key, _ := tr.victim(); key != "b" {
+		t.Errorf("victim = %q, want b", key)
+	}
+}
+
+func TestParsePolicy(t *testing.T) {
+	for _, name := range []string{"lru", "lfu", "fifo"} {
+		if policy, err := ParsePolicy(name); err != nil || string(policy) != name {
+			t.Errorf("ParsePolicy(%q) = %q, %v", name, policy, err)
+		}
+	}
+	if _, err := ParsePolicy("arc"); err == nil {
+		t.Error("ParsePolicy accepted an unimplemented policy")
+	}
+}
+
+// benchmarkHitRate replays a Zipf-distributed access pattern, where a few
+// keys are requested far more often than the rest, against a cache holding
+// a tenth of the keys and reports the share of hits
+func benchmarkHitRate(b *testing.B, policy Policy) {
+	const keys = 10000
+	clk := clock.NewFake(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	c := New(time.Hour, clk, WithMaxItems(keys/10), WithPolicy(policy))
+	defer c.Close()
+
+	zipf := rand.NewZipf(rand.New(rand.NewSource(1)), 1.1, 1, keys-1)
+	hits := 0
+	b.ResetTimer()
+	for range b.N {
+		key := strconv.FormatUint(zipf.Uint64(), 10)
+		if _, ok := c.Get(key); ok {
+			hits++
+		} else {
+			c.Set(key, struct{}{})
+		}
+	}
+	b.ReportMetric(float64(hits)/float64(b.N)*100, "%hits")
+}
+
+func BenchmarkHitRateLRU(b *testing.B)  { benchmarkHitRate(b, LRU) }
+func BenchmarkHitRateLFU(b *testing.B)  { benchmarkHitRate(b, LFU) }
+func BenchmarkHitRateFIFO(b *testing.B) { benchmarkHitRate(b, FIFO) }
+
+func TestGetStale(t *testing.T) {
+	clk := clock.NewFake(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	c := New(time.Minute, clk, WithStaleFor(time.Hour))
+	defer c.Close()
+	c.Set("key", "value")
+
+	if v, stale, ok := c.GetStale("key"); !ok || stale || v != "value" {
+		t.Errorf("GetStale = %v, %v, %v, want a fresh value", v, stale, ok)
+	}
+
+	clk.Advance(30 * time.Minute)
+	if _, ok := c.Get("key"); ok {
+		t.Error("Get returned an expired value")
+	}
+	if n := c.Sweep(); n != 0 {
+		t.Errorf("Sweep removed %d entries within the stale period", n)
+	}
+	if v, stale, ok := c.GetStale("key"); !ok || !stale || v != "value" {
+		t.Errorf("GetStale = %v, %v, %v, want a stale value", v, stale, ok)
+	}
+
+	clk.Advance(time.Hour)
+	if _, _, ok := c.GetStale("key"); ok {
+		t.Error("GetStale returned a value past the stale period")
+	}
+	if n := c.Sweep(); n != 1 {
+		t.Errorf("Sweep removed %d entries, want 1", n)
+	}
+}
@@ -0,0 +1,153 @@
+package cache
+
+import (
+	"container/list"
+	"fmt"
+)
+
+// Policy selects the entry evicted when the cache is full
+type Policy string
+
+const (
+	// LRU evicts the least recently used entry
+	LRU Policy = "lru"
+	// LFU evicts the least frequently used entry, the least recently used
+	// one among equally frequent entries
+	LFU Policy = "lfu"
+	// FIFO evicts the entry that was set first
+	FIFO Policy = "fifo"
+)
+
+// ParsePolicy returns the policy named name
+func ParsePolicy(name string) (Policy, error) {
+	switch policy := Policy(name); policy {
+	case LRU, LFU, FIFO:
+		return policy, nil
+	}
+	return "", fmt.Errorf("unknown cache eviction policy %q, want lru, lfu or fifo", name)
+}
+
+// tracker records how the keys of a shard are used and picks the key to
+// evict. All operations are O(1).
+type tracker interface {
+	add(key string)
+	access(key string)
+	remove(key string)
+	victim() (string, bool)
+}
+
+// newTracker returns the tracker implementing policy. FIFO needs none, the
+// shard keeps its keys in insertion order anyway.
+func newTracker(policy Policy) tracker {
+	switch policy {
+	case LFU:
+		return newLFUTracker()
+	case FIFO:
+		return nil
+	}
+	return newLRUTracker()
+}
+
+// lruTracker keeps keys in a list ordered by their last use, least recent
+// first
+type lruTracker struct {
+	order *list.List
+	elems map[string]*list.Element
+}
+
+func newLRUTracker() *lruTracker {
+	return &lruTracker{order: list.New(), elems: make(map[string]*list.Element)}
+}
+
+func (t *lruTracker) add(key string) {
+	t.elems[key] = t.order.PushBack(key)
+}
+
+func (t *lruTracker) access(key string) {
+	if elem, ok := t.elems[key]; ok {
+		t.order.MoveToBack(elem)
+	}
+}
+
+func (t *lruTracker) remove(key string) {
+	if elem, ok := t.elems[key]; ok {
+		t.order.Remove(elem)
+		delete(t.elems, key)
+	}
+}
+
+func (t *lruTracker) victim() (string, bool) {
+	if front := t.order.Front(); front != nil {
+		return front.Value.(string), true
+	}
+	return "", false
+}
+
+// lfuTracker keeps keys in frequency buckets. The buckets form a list
+// ordered by ascending use count and each holds its keys least recently
+// used first, so both counting a use and finding the victim are O(1).
+type lfuTracker struct {
+	buckets *list.List // of *lfuBucket
+	items   map[string]*lfuItem
+}
+
+type lfuBucket struct {
+	count int
+	keys  *list.List // of string
+}
+
+type lfuItem struct {
+	bucket *list.Element // in lfuTracker.buckets
+	elem   *list.Element // in the bucket's keys
+}
+
+func newLFUTracker() *lfuTracker {
+	return &lfuTracker{buckets: list.New(), items: make(map[string]*lfuItem)}
+}
+
+func (t *lfuTracker) add(key string) {
+	bucket := t.buckets.Front()
+	if bucket == nil || bucket.Value.(*lfuBucket).count != 1 {
+		bucket = t.buckets.PushFront(&lfuBucket{count: 1, keys: list.New()})
+	}
+	t.items[key] = &lfuItem{bucket: bucket, elem: bucket.Value.(*lfuBucket).keys.PushBack(key)}
+}
+
+func (t *lfuTracker) access(key string) {
+	item, ok := t.items[key]
+	if !ok {
+		return
+	}
+
+	current := item.bucket.Value.(*lfuBucket)
+	next := item.bucket.Next()
+	if next == nil || next.Value.(*lfuBucket).count != current.count+1 {
+		next = t.buckets.InsertAfter(&lfuBucket{count: current.count + 1, keys: list.New()}, item.bucket)
+	}
+	t.detach(item)
+	item.bucket = next
+	item.elem = next.Value.(*lfuBucket).keys.PushBack(key)
+}
+
+func (t *lfuTracker) remove(key string) {
+	if item, ok := t.items[key]; ok {
+		t.detach(item)
+		delete(t.items, key)
+	}
+}
+
+func (t *lfuTracker) victim() (string, bool) {
+	if front := t.buckets.Front(); front != nil {
+		return front.Value.(*lfuBucket).keys.Front().Value.(string), true
+	}
+	return "", false
+}
+
+// detach removes item from its bucket, dropping the bucket once empty
+func (t *lfuTracker) detach(item *lfuItem) {
+	bucket := item.bucket.Value.(*lfuBucket)
+	bucket.keys.Remove(item.elem)
+	if bucket.keys.Len() == 0 {
+		t.buckets.Remove(item.bucket)
+	}
+}
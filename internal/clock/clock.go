@@ -0,0 +1,32 @@
+// Package clock provides an injectable source of time so time-dependent
+// logic can be tested deterministically.
+package clock
+
+import "time"
+
+// Clock abstracts the time functions used by the application
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+	Sleep(d time.Duration)
+}
+
+// realClock implements Clock using the time package
+type realClock struct{}
+
+// New returns a Clock backed by the system time
+func New() Clock {
+	return realClock{}
+}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+func (realClock) Sleep(d time.Duration) {
+	time.Sleep(d)
+}
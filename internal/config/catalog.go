@@ -0,0 +1,95 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+const (
+	// messagesSection holds the messages of every locale, and sections
+	// named messagesSection.<locale> those of a single locale
+	messagesSection = "messages"
+	// defaultLocale keys the messages of every locale in a catalog and in
+	// the messages file
+	defaultLocale = "default"
+)
+
+// MessageCatalog maps API error codes to user-facing messages, keyed by
+// locale and then by code. The messages of defaultLocale apply to every
+// locale.
+type MessageCatalog map[string]map[string]string
+
+// LoadMessageCatalog returns the messages of the [messages] and
+// [messages.<locale>] sections of the loaded config file, overridden by
+// those of cfg.App.MessagesFile when it is set. The file holds a JSON
+// object mapping locales, or "default", to objects of codes and messages.
+// When the file can't be read the section messages are returned with the
+// error.
+func LoadMessageCatalog(cfg *Config) (MessageCatalog, error) {
+	catalog := MessageCatalog{}
+	stateMu.Lock()
+	if iniConfig != nil {
+		for _, sec := range iniConfig.Sections() {
+			locale, ok := messagesLocale(sec.Name())
+			if !ok {
+				continue
+			}
+			for _, key := range sec.Keys() {
+				catalog.add(locale, key.Name(), key.String())
+			}
+		}
+	}
+	stateMu.Unlock()
+
+	if cfg.App.MessagesFile == "" {
+		return catalog, nil
+	}
+	data, err := os.ReadFile(cfg.App.MessagesFile)
+	if err != nil {
+		return catalog, fmt.Errorf("failed to read messages file: %w", err)
+	}
+	var file map[string]map[string]string
+	if err := json.Unmarshal(data, &file); err != nil {
+		return catalog, fmt.Errorf("failed to parse messages file %s: %w", cfg.App.MessagesFile, err)
+	}
+	for locale, messages := range file {
+		for code, message := range messages {
+			catalog.add(locale, code, message)
+		}
+	}
+	return catalog, nil
+}
+
+// messagesLocale returns the locale of a messages section, defaultLocale
+// for [messages]
+func messagesLocale(section string) (string, bool) {
+	if section == messagesSection {
+		return defaultLocale, true
+	}
+	locale, ok := strings.CutPrefix(section, messagesSection+".")
+	return locale, ok && locale != ""
+}
+
+func (c MessageCatalog) add(locale, code, message string) {
+	locale = strings.ToLower(locale)
+	if c[locale] == nil {
+		c[locale] = make(map[string]string)
+	}
+	c[locale][code] = message
+}
+
+// Message returns the message for code in locale. It tries the locale
+// itself, then its language, e.g. vi for vi-VN, then the messages of every
+// locale. Locales are matched regardless of case.
+func (c MessageCatalog) Message(locale, code string) (string, bool) {
+	locale = strings.ToLower(locale)
+	language, _, _ := strings.Cut(locale, "-")
+	for _, l := range []string{locale, language, defaultLocale} {
+		if message, ok := c[l][code]; ok && message != "" {
+			return message, true
+		}
+	}
+	return "", false
+}
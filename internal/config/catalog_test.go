@@ -0,0 +1,72 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const catalogINI = `
+[messages]
+NOT_FOUND = Not found
+LOCKED = Account locked
+
+[messages.vi]
+NOT_FOUND = Không tìm thấy
+
+[messages.vi-VN]
+LOCKED = Tài khoản bị khóa
+`
+
+func TestMessageCatalogLocales(t *testing.T) {
+	useINI(t, catalogINI)
+	catalog, err := LoadMessageCatalog(&Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		locale, code, want string
+		found              bool
+	}{
+		{"en", "NOT_FOUND", "Not found", true},
+		{"vi", "NOT_FOUND", "Không tìm thấy", true},
+		{"vi-VN", "NOT_FOUND", "Không tìm thấy", true},
+		{"vi-vn", "LOCKED", "Tài khoản bị khóa", true},
+		{"vi", "LOCKED", "Account locked", true},
+		{"en", "UNKNOWN", "", false},
+	}
+	for _, tt := range tests {
+		got, found := catalog.Message(tt.locale, tt.code)
+		if got != tt.want || found != tt.found {
+			t.Errorf("Message(%q, %q) = %q, %t, want %q, %t", tt.locale, tt.code, got, found, tt.want, tt.found)
+		}
+	}
+}
+
+func TestMessageCatalogFile(t *testing.T) {
+	useINI(t, catalogINI)
+	path := filepath.Join(t.TempDir(), "messages.json")
+	data := `{"default": {"NOT_FOUND": "Nothing here"}, "fr": {"NOT_FOUND": "Introuvable"}}`
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	catalog, err := LoadMessageCatalog(&Config{App: AppConfig{MessagesFile: path}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for locale, want := range map[string]string{"en": "Nothing here", "fr-CA": "Introuvable", "vi": "Không tìm thấy"} {
+		if got, _ := catalog.Message(locale, "NOT_FOUND"); got != want {
+			t.Errorf("Message(%q) = %q, want %q", locale, got, want)
+		}
+	}
+
+	catalog, err = LoadMessageCatalog(&Config{App: AppConfig{MessagesFile: filepath.Join(t.TempDir(), "missing.json")}})
+	if err == nil {
+		t.Error("missing messages file loaded without an error")
+	}
+	if got, _ := catalog.Message("en", "LOCKED"); got != "Account locked" {
+		t.Errorf("Message after a failed file load = %q, want the section message", got)
+	}
+}
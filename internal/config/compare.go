@@ -0,0 +1,56 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ConfigDiff lists the values that differ between two config files. Old
+// holds the value of PathA and New that of PathB, with secrets masked.
+type ConfigDiff struct {
+	PathA   string   `json:"pathA"`
+	PathB   string   `json:"pathB"`
+	Changes []Change `json:"changes"`
+}
+
+// CompareConfigFiles loads the config files at pathA and pathB and returns
+// the values that differ, such as staging and production settings. The
+// files are compared as written: environment overrides, APP_ENV, profiles
+// and the secrets file aren't applied, and the loaded configuration is
+// left untouched. Unset values compare as their defaults.
+func CompareConfigFiles(pathA, pathB string) (*ConfigDiff, error) {
+	stateMu.Lock()
+	defer stateMu.Unlock()
+
+	a, err := loadConfigFile(pathA)
+	if err != nil {
+		return nil, err
+	}
+	b, err := loadConfigFile(pathB)
+	if err != nil {
+		return nil, err
+	}
+	return &ConfigDiff{PathA: pathA, PathB: pathB, Changes: Diff(a, b)}, nil
+}
+
+// loadConfigFile reads the config file at path without validating it,
+// keeping the state of the loaded configuration
+func loadConfigFile(path string) (*Config, error) {
+	file, err := loadSource(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load configuration from %s: %w", path, err)
+	}
+
+	savedINI, savedErrors, savedWarnings := iniConfig, loadErrors, loadWarnings
+	defer func() { iniConfig, loadErrors, loadWarnings = savedINI, savedErrors, savedWarnings }()
+	iniConfig, loadErrors, loadWarnings = file, nil, nil
+
+	app := loadAppConfig()
+	// loadAppConfig prefers APP_ENV, which would hide the file's value
+	app.Environment = Environment(getConfigValue("app", "environment", "development"))
+	config := loadSections(app)
+	if len(loadErrors) > 0 {
+		return nil, fmt.Errorf("failed to resolve configuration values of %s: %w", path, errors.Join(loadErrors...))
+	}
+	return config, nil
+}
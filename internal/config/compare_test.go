@@ -0,0 +1,93 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const stagingINI = `
+[app]
+environment = staging
+debug = true
+
+[api]
+base_url = https://staging.example.com
+timeout = 30
+
+[database]
+password = staging-secret
+
+[window]
+width = 1024
+`
+
+const productionINI = `
+[app]
+environment = production
+debug = false
+
+[api]
+base_url = https://api.example.com
+timeout = 30
+
+[database]
+password = production-secret
+
+[window]
+width = 1024
+`
+
+// writeConfigFile writes data to a config file in a temporary directory
+func writeConfigFile(t *testing.T, name, data string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestCompareConfigFiles(t *testing.T) {
+	useINI(t, "[app]\nname = Loaded\n")
+	loaded := iniConfig
+	t.Setenv("APP_ENV", "development")
+
+	staging := writeConfigFile(t, "staging.ini", stagingINI)
+	production := writeConfigFile(t, "production.ini", productionINI)
+	diff, err := CompareConfigFiles(staging, production)
+	if err != nil {
+		t.Fatalf("CompareConfigFiles: %v", err)
+	}
+
+	want := []Change{
+		{Path: "app.environment", Old: Staging, New: Production},
+		{Path: "app.debug", Old: true, New: false},
+		{Path: "api.baseUrl", Old: "https://staging.example.com", New: "https://api.example.com"},
+		{Path: "database.password", Old: maskedSecret, New: maskedSecret},
+	}
+	if diff.PathA != staging || diff.PathB != production || len(diff.Changes) != len(want) {
+		t.Fatalf("diff = %+v, want %d changes", diff, len(want))
+	}
+	for i, change := range diff.Changes {
+		if change != want[i] {
+			t.Errorf("change %d = %+v, want %+v", i, change, want[i])
+		}
+	}
+
+	if iniConfig != loaded {
+		t.Error("CompareConfigFiles replaced the loaded config file")
+	}
+}
+
+func TestCompareConfigFilesErrors(t *testing.T) {
+	valid := writeConfigFile(t, "valid.ini", stagingINI)
+	if _, err := CompareConfigFiles(valid, filepath.Join(t.TempDir(), "missing.ini")); err == nil {
+		t.Error("missing file compared without an error")
+	}
+
+	unresolved := writeConfigFile(t, "unresolved.ini", "[api]\nbase_url = ${COMPARE_TEST_UNSET}\n")
+	if _, err := CompareConfigFiles(valid, unresolved); err == nil {
+		t.Error("unresolved variable compared without an error")
+	}
+}
@@ -0,0 +1,122 @@
+package config
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Constraint describes the validation rules of a config value, so forms can
+// be built that match server-side validation. Min and Max bound numbers,
+// and the length of strings and lists. Durations are given in seconds like
+// LookupValue returns them.
+type Constraint struct {
+	Type     string   `json:"type"`           // boolean, integer, number, string, duration, list or map
+	Unit     string   `json:"unit,omitempty"` // unit of durations, always "seconds"
+	Min      *float64 `json:"min,omitempty"`
+	Max      *float64 `json:"max,omitempty"`
+	Required bool     `json:"required"`
+	Options  []string `json:"options,omitempty"` // allowed values, from oneof
+}
+
+// FieldConstraints returns the constraints of every config value by JSON
+// path such as "window.width", taken from the validator tags of Config.
+// Cross-field rules such as ltfield and rules on list items aren't
+// represented.
+func FieldConstraints() map[string]Constraint {
+	constraints := make(map[string]Constraint)
+	collectConstraints(reflect.TypeOf(Config{}), "", constraints)
+	return constraints
+}
+
+func collectConstraints(t reflect.Type, prefix string, constraints map[string]Constraint) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		path := prefix + jsonName(field)
+		if field.Type.Kind() == reflect.Struct {
+			collectConstraints(field.Type, path+".", constraints)
+			continue
+		}
+		constraints[path] = fieldConstraint(field)
+	}
+}
+
+// fieldConstraint reads the constraint of a single field from its type and
+// validate tag
+func fieldConstraint(field reflect.StructField) Constraint {
+	isDuration := field.Type == reflect.TypeOf(time.Duration(0))
+	c := Constraint{Type: constraintType(field.Type)}
+	if isDuration {
+		c.Unit = "seconds"
+	}
+
+	tag := field.Tag.Get("validate")
+	if tag == "" {
+		return c
+	}
+	for _, rule := range strings.Split(tag, ",") {
+		name, param, _ := strings.Cut(rule, "=")
+		if name == "dive" {
+			// The remaining rules apply to the items of a list
+			break
+		}
+		switch name {
+		case "required":
+			c.Required = true
+		case "min":
+			c.Min = constraintBound(param, isDuration)
+		case "max":
+			c.Max = constraintBound(param, isDuration)
+		case "oneof":
+			c.Options = strings.Fields(param)
+		}
+	}
+	return c
+}
+
+func constraintType(t reflect.Type) string {
+	if t == reflect.TypeOf(time.Duration(0)) {
+		return "duration"
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice, reflect.Array:
+		return "list"
+	case reflect.Map:
+		return "map"
+	default:
+		return "string"
+	}
+}
+
+// constraintBound parses a min or max parameter, converting durations such
+// as "5m" to seconds. A bare number on a duration is in nanoseconds, as the
+// validator reads it.
+func constraintBound(param string, isDuration bool) *float64 {
+	var bound float64
+	if isDuration {
+		d, err := time.ParseDuration(param)
+		if err != nil {
+			n, err := strconv.ParseInt(param, 10, 64)
+			if err != nil {
+				return nil
+			}
+			d = time.Duration(n)
+		}
+		bound = d.Seconds()
+	} else {
+		f, err := strconv.ParseFloat(param, 64)
+		if err != nil {
+			return nil
+		}
+		bound = f
+	}
+	return &bound
+}
@@ -0,0 +1,62 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func bound(f float64) *float64 { return &f }
+
+// describeConstraint formats c with its bounds rather than their addresses
+func describeConstraint(c Constraint) string {
+	format := func(b *float64) string {
+		if b == nil {
+			return "none"
+		}
+		return fmt.Sprint(*b)
+	}
+	return fmt.Sprintf("{%s %s min=%s max=%s required=%t options=%q}", c.Type, c.Unit, format(c.Min), format(c.Max), c.Required, c.Options)
+}
+
+func TestFieldConstraints(t *testing.T) {
+	constraints := FieldConstraints()
+
+	tests := []struct {
+		path string
+		want Constraint
+	}{
+		{"window.width", Constraint{Type: "integer", Min: bound(400), Max: bound(4000), Required: true}},
+		{"log.level", Constraint{Type: "string", Required: true, Options: []string{"debug", "info", "warn", "error"}}},
+		{"auth.lockoutDuration", Constraint{Type: "duration", Unit: "seconds", Min: bound(60), Max: bound(86400)}},
+		{"auth.strategy", Constraint{Type: "string", Options: []string{"password", "api_key"}}},
+		{"app.debug", Constraint{Type: "boolean"}},
+		{"api.defaultHeaders", Constraint{Type: "map"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, ok := constraints[tt.path]
+			if !ok {
+				t.Fatalf("no constraint for %s", tt.path)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("constraint = %s, want %s", describeConstraint(got), describeConstraint(tt.want))
+			}
+		})
+	}
+}
+
+func TestFieldConstraintsCoversEveryValue(t *testing.T) {
+	constraints := FieldConstraints()
+	for _, path := range []string{"api.baseUrl", "database.password", "tls.minVersion"} {
+		if _, ok := constraints[path]; !ok {
+			t.Errorf("no constraint for %s", path)
+		}
+	}
+	for path := range constraints {
+		if _, err := LookupValue(&Config{}, path); err != nil && !errors.Is(err, ErrSensitiveConfigPath) {
+			t.Errorf("%s doesn't resolve to a config value: %v", path, err)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package config
+
+import (
+	"slices"
+	"strconv"
+	"time"
+
+	"gopkg.in/ini.v1"
+)
+
+// recordedDefaults collects the default of every value read while Defaults
+// builds the default configuration. It is nil during regular loads.
+var recordedDefaults Values
+
+// Defaults returns the built-in default configuration together with the
+// defaults as INI values, ready for WriteValues. The config file, the
+// secrets file and environment overrides other than APP_ENV are ignored.
+// The result is not validated; api.base_url has no usable default.
+func Defaults() (*Config, Values) {
+	stateMu.Lock()
+	defer stateMu.Unlock()
+	return defaults()
+}
+
+func defaults() (*Config, Values) {
+	savedINI, savedErrors, savedWarnings := iniConfig, loadErrors, loadWarnings
+	defer func() {
+		iniConfig, loadErrors, loadWarnings = savedINI, savedErrors, savedWarnings
+		recordedDefaults = nil
+	}()
+
+	iniConfig = ini.Empty()
+	recordedDefaults = Values{}
+	config := loadSections(loadAppConfig())
+	return config, recordedDefaults
+}
+
+// IsSecretKey reports whether section.key holds a secret
+func IsSecretKey(section, key string) bool {
+	return slices.Contains(secretKeys[section], key)
+}
+
+// recordDefault records the default of section.key while Defaults runs
+func recordDefault(section, key, value string) {
+	if recordedDefaults == nil {
+		return
+	}
+	if recordedDefaults[section] == nil {
+		recordedDefaults[section] = make(map[string]string)
+	}
+	recordedDefaults[section][key] = value
+}
+
+// formatDuration formats d the way config.ini writes durations: whole
+// seconds as a plain number, anything else as a duration string
+func formatDuration(d time.Duration) string {
+	if d%time.Second == 0 {
+		return strconv.FormatInt(int64(d/time.Second), 10)
+	}
+	return d.String()
+}
@@ -0,0 +1,69 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDefaults(t *testing.T) {
+	loaded := loadRepoConfig(t)
+	savedINI := iniConfig
+
+	defaults, values := Defaults()
+	if iniConfig != savedINI || recordedDefaults != nil {
+		t.Error("Defaults did not restore the loader state")
+	}
+
+	// The documented defaults, see docs/CONFIGURATION.md
+	if defaults.App.Name != "CSmart Wails App" || defaults.App.Version != "1.0.0" || !defaults.App.Debug {
+		t.Errorf("App = %+v, want the documented defaults", defaults.App)
+	}
+	if defaults.API.Timeout != 30*time.Second || defaults.API.RetryCount != 3 || defaults.API.RetryDelay != time.Second || defaults.API.UserAgent != "CSmart-Wails/1.0" {
+		t.Errorf("API = %+v, want the documented defaults", defaults.API)
+	}
+	if defaults.Auth.TokenExpiry != time.Hour || defaults.Auth.RefreshThreshold != 5*time.Minute || defaults.Auth.MaxLoginAttempts != 5 || defaults.Auth.LockoutDuration != 15*time.Minute {
+		t.Errorf("Auth = %+v, want the documented defaults", defaults.Auth)
+	}
+	if defaults.Log.Level != LogLevelDebug || defaults.Log.Format != "json" || defaults.Log.Output != "console" || defaults.Log.FilePath != "logs/app.log" {
+		t.Errorf("Log = %+v, want the documented defaults", defaults.Log)
+	}
+	if !defaults.Security.CORSEnabled || defaults.Security.RateLimitEnabled || defaults.Security.RateLimitRPS != 100 || defaults.Security.CSRFEnabled {
+		t.Errorf("Security = %+v, want the documented defaults", defaults.Security)
+	}
+	if defaults.Window.Width != 1200 || defaults.Window.Height != 800 || !defaults.Window.Resizable || defaults.Window.Fullscreen {
+		t.Errorf("Window = %+v, want the documented defaults", defaults.Window)
+	}
+	if loaded.API.BaseURL == "" || defaults.API.BaseURL != "" {
+		t.Errorf("base URL = %q, want the config file to be ignored", defaults.API.BaseURL)
+	}
+
+	// Values are written the way config.ini spells them
+	for key, want := range map[string]string{
+		"api.timeout":           "30",
+		"api.retry_delay":       "1",
+		"auth.lockout_duration": "900",
+		"window.width":          "1200",
+		"window.resizable":      "true",
+		"security.cors_origins": "",
+	} {
+		section, name, _ := strings.Cut(key, ".")
+		if got, ok := values[section][name]; !ok || got != want {
+			t.Errorf("values[%s] = %q, %v, want %q", key, got, ok, want)
+		}
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	tests := map[time.Duration]string{
+		0:                       "0",
+		30 * time.Second:        "30",
+		time.Hour:               "3600",
+		1500 * time.Millisecond: "1.5s",
+	}
+	for d, want := range tests {
+		if got := formatDuration(d); got != want {
+			t.Errorf("formatDuration(%v) = %q, want %q", d, got, want)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package config
+
+import (
+	"reflect"
+	"slices"
+	"time"
+)
+
+// Change is a config value that differs between two configurations. Old
+// and New are formatted like LookupValue returns them, with secrets
+// masked.
+type Change struct {
+	Path string `json:"path"`
+	Old  any    `json:"old"`
+	New  any    `json:"new"`
+}
+
+// Diff returns the values that differ between old and updated, by JSON path
+// such as "window.width", in field order
+func Diff(old, updated *Config) []Change {
+	return diffStruct(reflect.ValueOf(*old), reflect.ValueOf(*updated), "")
+}
+
+func diffStruct(old, updated reflect.Value, prefix string) []Change {
+	var changes []Change
+	for i := 0; i < old.NumField(); i++ {
+		path := prefix + jsonName(old.Type().Field(i))
+		oldField, newField := old.Field(i), updated.Field(i)
+		if oldField.Kind() == reflect.Struct {
+			changes = append(changes, diffStruct(oldField, newField, path+".")...)
+			continue
+		}
+		if reflect.DeepEqual(oldField.Interface(), newField.Interface()) {
+			continue
+		}
+
+		change := Change{Path: path, Old: changeValue(oldField), New: changeValue(newField)}
+		if slices.Contains(sensitivePaths, path) {
+			change.Old, change.New = maskedValue(oldField), maskedValue(newField)
+		}
+		changes = append(changes, change)
+	}
+	return changes
+}
+
+// changeValue formats a value like LookupValue, durations as seconds
+func changeValue(value reflect.Value) any {
+	if duration, ok := value.Interface().(time.Duration); ok {
+		return duration.Seconds()
+	}
+	return value.Interface()
+}
+
+// maskedValue hides a secret, keeping only whether it is set
+func maskedValue(value reflect.Value) string {
+	if value.IsZero() || (value.Kind() == reflect.Map && value.Len() == 0) {
+		return ""
+	}
+	return maskedSecret
+}
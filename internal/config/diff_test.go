@@ -0,0 +1,33 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDiff(t *testing.T) {
+	old := &Config{
+		Window:   WindowConfig{Width: 1200},
+		API:      APIConfig{Timeout: 30 * time.Second},
+		Security: SecurityConfig{CSRFSecret: "old-secret"},
+	}
+	updated := *old
+	updated.Window.Width = 1300
+	updated.API.Timeout = time.Minute
+	updated.API.DefaultHeaders = map[string]string{"X-Api-Key": "key"}
+	updated.Security.CSRFSecret = "new-secret"
+
+	want := []Change{
+		{Path: "api.timeout", Old: 30.0, New: 60.0},
+		{Path: "api.defaultHeaders", Old: "", New: "***MASKED***"},
+		{Path: "security.csrfSecret", Old: "***MASKED***", New: "***MASKED***"},
+		{Path: "window.width", Old: 1200, New: 1300},
+	}
+	if got := Diff(old, &updated); !reflect.DeepEqual(got, want) {
+		t.Errorf("Diff = %+v, want %+v", got, want)
+	}
+	if got := Diff(old, old); len(got) != 0 {
+		t.Errorf("Diff of equal configs = %+v, want none", got)
+	}
+}
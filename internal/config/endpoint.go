@@ -0,0 +1,34 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// MaxEndpointWeight is the largest weight of an api.base_urls endpoint
+const MaxEndpointWeight = 100
+
+// ParseEndpoint splits an api.base_urls entry of the form url;weight=N into
+// its URL and weight. Entries without a weight have weight 1.
+func ParseEndpoint(entry string) (string, int, error) {
+	rawURL, params, hasParams := strings.Cut(entry, ";")
+	rawURL = strings.TrimSpace(rawURL)
+	if u, err := url.Parse(rawURL); err != nil || u.Scheme == "" || u.Host == "" {
+		return "", 0, fmt.Errorf("endpoint %q is not an absolute URL", rawURL)
+	}
+	if !hasParams {
+		return rawURL, 1, nil
+	}
+
+	name, value, _ := strings.Cut(params, "=")
+	if strings.TrimSpace(name) != "weight" {
+		return "", 0, fmt.Errorf("endpoint %q: unknown parameter %q", rawURL, strings.TrimSpace(name))
+	}
+	weight, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || weight < 1 || weight > MaxEndpointWeight {
+		return "", 0, fmt.Errorf("endpoint %q: weight must be a number from 1 to %d", rawURL, MaxEndpointWeight)
+	}
+	return rawURL, weight, nil
+}
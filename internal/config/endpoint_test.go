@@ -0,0 +1,31 @@
+package config
+
+import "testing"
+
+func TestParseEndpoint(t *testing.T) {
+	tests := []struct {
+		entry   string
+		url     string
+		weight  int
+		wantErr bool
+	}{
+		{"https://a.example.com/api", "https://a.example.com/api", 1, false},
+		{"https://a.example.com/api;weight=3", "https://a.example.com/api", 3, false},
+		{"https://a.example.com/api ; weight = 100", "https://a.example.com/api", 100, false},
+		{"https://a.example.com/api;weight=0", "", 0, true},
+		{"https://a.example.com/api;weight=101", "", 0, true},
+		{"https://a.example.com/api;weight=heavy", "", 0, true},
+		{"https://a.example.com/api;priority=2", "", 0, true},
+		{"not a url;weight=2", "", 0, true},
+	}
+	for _, tt := range tests {
+		url, weight, err := ParseEndpoint(tt.entry)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseEndpoint(%q) error = %v, wantErr %v", tt.entry, err, tt.wantErr)
+			continue
+		}
+		if url != tt.url || weight != tt.weight {
+			t.Errorf("ParseEndpoint(%q) = %q, %d, want %q, %d", tt.entry, url, weight, tt.url, tt.weight)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// envKeyExceptions maps the JSON paths of values whose INI section or key
+// doesn't follow from their JSON name to "section.key"
+var envKeyExceptions = map[string]string{
+	"app.hotReload":   "development.hot_reload",
+	"app.devTools":    "development.dev_tools",
+	"app.mockApi":     "development.mock_api",
+	"app.readOnly":    "app.readonly",
+	"auth.headerName": "auth.auth_header_name",
+	"auth.scheme":     "auth.auth_scheme",
+}
+
+// spaceSeparatedLists are the JSON paths of lists loaded as
+// whitespace-separated values rather than comma-separated ones
+var spaceSeparatedLists = map[string]bool{
+	"log.redactPatterns": true,
+}
+
+var (
+	// wordBoundary matches where a camelCase name starts a new word
+	wordBoundary = regexp.MustCompile(`([a-z0-9])([A-Z])`)
+	// shellSafe matches values that need no quoting in a shell
+	shellSafe = regexp.MustCompile(`^[A-Za-z0-9_./:@%+=,-]+$`)
+)
+
+// ExportEnv returns cfg as "export NAME=value" lines for env-var based
+// deployments, named like applyEnvOverrides reads them:
+// <PREFIX><SECTION>_<KEY>, e.g. CSMART_API_BASE_URL. An empty prefix uses
+// the override prefix in effect. Empty values are left out, as they load
+// as the default. Secrets are masked and commented out unless
+// includeSecrets is set.
+func ExportEnv(cfg *Config, prefix string, includeSecrets bool) string {
+	if prefix == "" {
+		prefix = envPrefix()
+	}
+	prefix = strings.ToUpper(prefix)
+
+	var b strings.Builder
+	config := reflect.ValueOf(*cfg)
+	for i := 0; i < config.NumField(); i++ {
+		section := config.Field(i)
+		sectionName := jsonName(config.Type().Field(i))
+		for j := 0; j < section.NumField(); j++ {
+			field := section.Field(j)
+			path := sectionName + "." + jsonName(section.Type().Field(j))
+			value := envValue(field)
+			if spaceSeparatedLists[path] {
+				value = strings.Join(field.Interface().([]string), " ")
+			}
+			if value == "" {
+				continue
+			}
+
+			name := prefix + strings.ToUpper(strings.Replace(iniPath(path), ".", "_", 1))
+			if slices.Contains(sensitivePaths, path) && !includeSecrets {
+				fmt.Fprintf(&b, "# export %s=%s\n", name, maskedSecret)
+				continue
+			}
+			fmt.Fprintf(&b, "export %s=%s\n", name, shellQuote(value))
+		}
+	}
+	return b.String()
+}
+
+// iniPath returns the INI "section.key" of the value at a JSON path such
+// as "api.baseUrl", which is "api.base_url"
+func iniPath(path string) string {
+	if mapped, ok := envKeyExceptions[path]; ok {
+		return mapped
+	}
+	section, key, _ := strings.Cut(path, ".")
+	return section + "." + strings.ToLower(wordBoundary.ReplaceAllString(key, "${1}_${2}"))
+}
+
+// envValue formats a config value the way config.ini writes it
+func envValue(value reflect.Value) string {
+	if duration, ok := value.Interface().(time.Duration); ok {
+		return formatDuration(duration)
+	}
+
+	switch value.Kind() {
+	case reflect.Bool:
+		return strconv.FormatBool(value.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(value.Int(), 10)
+	case reflect.Slice:
+		items := make([]string, value.Len())
+		for i := range items {
+			items[i] = envValue(value.Index(i))
+		}
+		return strings.Join(items, ", ")
+	case reflect.Map:
+		// Headers, written as "Name: Value" entries
+		entries := make([]string, 0, value.Len())
+		for _, key := range value.MapKeys() {
+			entries = append(entries, key.String()+": "+value.MapIndex(key).String())
+		}
+		slices.Sort(entries)
+		return strings.Join(entries, ", ")
+	default:
+		return value.String()
+	}
+}
+
+// shellQuote quotes value for a POSIX shell when it contains anything but
+// plain characters
+func shellQuote(value string) string {
+	if shellSafe.MatchString(value) {
+		return value
+	}
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
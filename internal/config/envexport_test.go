@@ -0,0 +1,82 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// exportTestConfig returns the default config with values of every kind
+// changed, including ones whose INI names don't follow their JSON names
+func exportTestConfig() *Config {
+	cfg, _ := Defaults()
+	cfg.App.Name = "Exported App"
+	cfg.App.DevTools = false
+	cfg.App.ReadOnly = true
+	cfg.API.BaseURL = "https://api.example.com/v1"
+	cfg.API.Timeout = 45 * time.Second
+	cfg.API.DefaultHeaders = map[string]string{"X-Client": "desktop", "X-Team": "a b"}
+	cfg.API.HealthExpectedStatus = []int{200, 204}
+	cfg.Auth.Scheme = "Token"
+	cfg.Database.Password = "it's secret"
+	cfg.Security.CORSOrigins = []string{"https://a.example.com", "https://b.example.com"}
+	cfg.Log.RedactPatterns = []string{`[a-z]+@example\.com`, `tok_[0-9a-f]{8,}`}
+	return cfg
+}
+
+// unquoteShell reverses shellQuote
+func unquoteShell(value string) string {
+	if !strings.HasPrefix(value, "'") {
+		return value
+	}
+	return strings.ReplaceAll(strings.Trim(value, "'"), `'\''`, "'")
+}
+
+func TestExportEnvRoundTrip(t *testing.T) {
+	cfg := exportTestConfig()
+	exported := ExportEnv(cfg, "export_", true)
+
+	useINI(t, "")
+	loadErrors = nil
+	t.Cleanup(func() { loadErrors = nil })
+	t.Setenv("CONFIG_ENV_PREFIX", "EXPORT_")
+	t.Setenv("APP_ENV", "")
+	for _, line := range strings.Split(strings.TrimSpace(exported), "\n") {
+		name, value, ok := strings.Cut(strings.TrimPrefix(line, "export "), "=")
+		if !ok || !strings.HasPrefix(name, "EXPORT_") {
+			t.Fatalf("line %q is not an export of a prefixed variable", line)
+		}
+		t.Setenv(name, unquoteShell(value))
+	}
+
+	applyEnvOverrides()
+	loaded := loadSections(loadAppConfig())
+	if len(loadErrors) > 0 {
+		t.Fatalf("load errors: %v", loadErrors)
+	}
+	if changes := Diff(cfg, loaded); len(changes) > 0 {
+		t.Errorf("values lost in the round trip: %+v", changes)
+	}
+}
+
+func TestExportEnvMasksSecrets(t *testing.T) {
+	exported := ExportEnv(exportTestConfig(), "EXPORT_", false)
+
+	for _, want := range []string{
+		"export EXPORT_APP_NAME='Exported App'\n",
+		"export EXPORT_DEVELOPMENT_DEV_TOOLS=false\n",
+		"export EXPORT_AUTH_AUTH_SCHEME=Token\n",
+		"# export EXPORT_DATABASE_PASSWORD=***MASKED***\n",
+		"# export EXPORT_API_DEFAULT_HEADERS=***MASKED***\n",
+	} {
+		if !strings.Contains(exported, want) {
+			t.Errorf("export lacks %q", want)
+		}
+	}
+	if strings.Contains(exported, "secret") || strings.Contains(exported, "desktop") {
+		t.Errorf("export leaks a secret:\n%s", exported)
+	}
+	if strings.Contains(exported, "TENANT_URL_TEMPLATE") {
+		t.Errorf("export includes empty values:\n%s", exported)
+	}
+}
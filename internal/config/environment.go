@@ -0,0 +1,30 @@
+package config
+
+// environmentRanks orders the environments from least to most strict
+var environmentRanks = map[Environment]int{
+	Development: 1,
+	Staging:     2,
+	Production:  3,
+}
+
+// IsDevelopment reports whether e is the development environment
+func (e Environment) IsDevelopment() bool {
+	return e == Development
+}
+
+// IsStaging reports whether e is the staging environment
+func (e Environment) IsStaging() bool {
+	return e == Staging
+}
+
+// IsProduction reports whether e is the production environment
+func (e Environment) IsProduction() bool {
+	return e == Production
+}
+
+// AtLeast reports whether e is other or a stricter environment, in the
+// order development < staging < production. An unknown environment is
+// never at least a known one.
+func (e Environment) AtLeast(other Environment) bool {
+	return environmentRanks[e] >= environmentRanks[other]
+}
@@ -0,0 +1,43 @@
+package config
+
+import "testing"
+
+func TestEnvironmentPredicates(t *testing.T) {
+	tests := []struct {
+		env                              Environment
+		development, staging, production bool
+	}{
+		{Development, true, false, false},
+		{Staging, false, true, false},
+		{Production, false, false, true},
+		{"prod", false, false, false},
+	}
+	for _, tt := range tests {
+		if got := tt.env.IsDevelopment(); got != tt.development {
+			t.Errorf("%q.IsDevelopment() = %v", tt.env, got)
+		}
+		if got := tt.env.IsStaging(); got != tt.staging {
+			t.Errorf("%q.IsStaging() = %v", tt.env, got)
+		}
+		if got := tt.env.IsProduction(); got != tt.production {
+			t.Errorf("%q.IsProduction() = %v", tt.env, got)
+		}
+	}
+}
+
+func TestEnvironmentAtLeast(t *testing.T) {
+	order := []Environment{Development, Staging, Production}
+	for i, e := range order {
+		for j, other := range order {
+			if got, want := e.AtLeast(other), i >= j; got != want {
+				t.Errorf("%s.AtLeast(%s) = %v, want %v", e, other, got, want)
+			}
+		}
+	}
+	if Environment("prod").AtLeast(Development) {
+		t.Error("unknown environment is at least development")
+	}
+	if !Production.AtLeast("prod") {
+		t.Error("production is not at least an unknown environment")
+	}
+}
@@ -0,0 +1,94 @@
+package config
+
+import (
+	"os"
+	"strconv"
+	"strings"
+
+	"gopkg.in/ini.v1"
+)
+
+// defaultEnvPrefix is the prefix of environment variables that override
+// config values, unless changed with CONFIG_ENV_PREFIX
+const defaultEnvPrefix = "CSMART_"
+
+// envPrefix returns the prefix of override variables. CONFIG_ENV_PREFIX
+// replaces the default; setting it to an empty string disables prefixed
+// overrides.
+func envPrefix() string {
+	if prefix, ok := os.LookupEnv("CONFIG_ENV_PREFIX"); ok {
+		return strings.ToUpper(prefix)
+	}
+	return defaultEnvPrefix
+}
+
+// unprefixedEnvEnabled reports whether unprefixed variables such as
+// DATABASE_HOST may override config values. This is opt-in through
+// CONFIG_ENV_UNPREFIXED because generic names collide with unrelated
+// system variables.
+func unprefixedEnvEnabled() bool {
+	enabled, _ := strconv.ParseBool(os.Getenv("CONFIG_ENV_UNPREFIXED"))
+	return enabled
+}
+
+// applyEnvOverrides sets config values from environment variables named
+// <PREFIX><SECTION>_<KEY>, e.g. CSMART_DATABASE_HOST for [database] host.
+// Prefixed variables may set any key. When enabled, unprefixed variables
+// are consulted only for keys present in the config file, and a prefixed
+// variable always wins. It returns the overridden keys as "section.key".
+func applyEnvOverrides() map[string]bool {
+	overridden := make(map[string]bool)
+
+	if prefix := envPrefix(); prefix != "" {
+		for _, entry := range os.Environ() {
+			name, value, _ := strings.Cut(entry, "=")
+			rest, ok := strings.CutPrefix(name, prefix)
+			if !ok {
+				continue
+			}
+			section, key, ok := strings.Cut(strings.ToLower(rest), "_")
+			if !ok || section == "" || key == "" {
+				continue
+			}
+			iniConfig.Section(section).Key(key).SetValue(value)
+			overridden[section+"."+key] = true
+		}
+	}
+
+	if unprefixedEnvEnabled() {
+		for _, sec := range iniConfig.Sections() {
+			if sec.Name() == ini.DefaultSection {
+				continue
+			}
+			for _, key := range sec.Keys() {
+				path := sec.Name() + "." + key.Name()
+				if overridden[path] {
+					continue
+				}
+				if value, ok := os.LookupEnv(strings.ToUpper(sec.Name() + "_" + key.Name())); ok {
+					key.SetValue(value)
+					overridden[path] = true
+				}
+			}
+		}
+	}
+
+	return overridden
+}
+
+// envOverrideName returns the name of the environment variable that
+// overrides section.key, or an empty string when none is set
+func envOverrideName(section, key string) string {
+	name := strings.ToUpper(section + "_" + key)
+	if prefix := envPrefix(); prefix != "" {
+		if _, ok := os.LookupEnv(prefix + name); ok {
+			return prefix + name
+		}
+	}
+	if unprefixedEnvEnabled() {
+		if _, ok := os.LookupEnv(name); ok {
+			return name
+		}
+	}
+	return ""
+}
@@ -0,0 +1,99 @@
+package config
+
+import "testing"
+
+// overrideINI is a config file with a database section and a key that an
+// unrelated system variable could collide with
+const overrideINI = "[database]\nhost = localhost\nport = 5432\n[server]\nhost = from-file\n"
+
+func TestEnvOverridePrefixed(t *testing.T) {
+	useINI(t, overrideINI)
+	t.Setenv("CSMART_DATABASE_HOST", "db.internal")
+	t.Setenv("CSMART_API_BASE_URL", "https://api.example.com")
+
+	overridden := applyEnvOverrides()
+
+	if got := iniConfig.Section("database").Key("host").String(); got != "db.internal" {
+		t.Errorf("database.host = %q, want the prefixed override", got)
+	}
+	if got := iniConfig.Section("api").Key("base_url").String(); got != "https://api.example.com" {
+		t.Errorf("api.base_url = %q, keys with underscores must be overridable", got)
+	}
+	if !overridden["database.host"] || !overridden["api.base_url"] || overridden["database.port"] {
+		t.Errorf("overridden = %v", overridden)
+	}
+}
+
+func TestEnvOverrideCustomPrefix(t *testing.T) {
+	useINI(t, overrideINI)
+	t.Setenv("CONFIG_ENV_PREFIX", "myapp_")
+	t.Setenv("MYAPP_DATABASE_HOST", "custom")
+	t.Setenv("CSMART_DATABASE_PORT", "6543")
+
+	applyEnvOverrides()
+
+	if got := iniConfig.Section("database").Key("host").String(); got != "custom" {
+		t.Errorf("database.host = %q, want the custom prefix honored", got)
+	}
+	if got := iniConfig.Section("database").Key("port").String(); got != "5432" {
+		t.Errorf("database.port = %q, the default prefix must be ignored once replaced", got)
+	}
+}
+
+func TestEnvOverrideDisabledPrefix(t *testing.T) {
+	useINI(t, overrideINI)
+	t.Setenv("CONFIG_ENV_PREFIX", "")
+	t.Setenv("CSMART_DATABASE_HOST", "ignored")
+
+	if overridden := applyEnvOverrides(); len(overridden) != 0 {
+		t.Errorf("overridden = %v with prefixed overrides disabled", overridden)
+	}
+}
+
+func TestEnvOverrideNoCollision(t *testing.T) {
+	useINI(t, overrideINI)
+	t.Setenv("DATABASE_HOST", "unrelated")
+	t.Setenv("SERVER_HOST", "unrelated")
+
+	applyEnvOverrides()
+	if got := iniConfig.Section("database").Key("host").String(); got != "localhost" {
+		t.Errorf("database.host = %q, unprefixed variables must be ignored by default", got)
+	}
+}
+
+func TestEnvOverrideUnprefixedOptIn(t *testing.T) {
+	useINI(t, overrideINI)
+	t.Setenv("CONFIG_ENV_UNPREFIXED", "true")
+	t.Setenv("DATABASE_HOST", "unprefixed")
+	t.Setenv("DATABASE_PORT", "1111")
+	t.Setenv("CSMART_DATABASE_PORT", "2222")
+	t.Setenv("CACHE_TTL", "60")
+
+	overridden := applyEnvOverrides()
+
+	if got := iniConfig.Section("database").Key("host").String(); got != "unprefixed" {
+		t.Errorf("database.host = %q, want the opted-in unprefixed override", got)
+	}
+	if got := iniConfig.Section("database").Key("port").String(); got != "2222" {
+		t.Errorf("database.port = %q, the prefixed variable must win", got)
+	}
+	if overridden["cache.ttl"] || iniConfig.Section("cache").HasKey("ttl") {
+		t.Error("unprefixed variable set a key missing from the config file")
+	}
+}
+
+func TestEnvOverrideName(t *testing.T) {
+	useINI(t, overrideINI)
+	if name := envOverrideName("security", "csrf_secret"); name != "" {
+		t.Errorf("envOverrideName = %q with nothing set", name)
+	}
+
+	t.Setenv("SECURITY_CSRF_SECRET", "x")
+	if name := envOverrideName("security", "csrf_secret"); name != "" {
+		t.Errorf("envOverrideName = %q for an unprefixed variable that is not opted in", name)
+	}
+	t.Setenv("CSMART_SECURITY_CSRF_SECRET", "x")
+	if name := envOverrideName("security", "csrf_secret"); name != "CSMART_SECURITY_CSRF_SECRET" {
+		t.Errorf("envOverrideName = %q", name)
+	}
+}
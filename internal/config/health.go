@@ -0,0 +1,110 @@
+package config
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// HealthStatus summarizes a configuration health report
+type HealthStatus string
+
+const (
+	HealthHealthy  HealthStatus = "healthy"
+	HealthWarnings HealthStatus = "warnings"
+	HealthErrors   HealthStatus = "errors"
+)
+
+// Score deducted for each error and warning in a health report
+const (
+	healthErrorPenalty   = 20
+	healthWarningPenalty = 5
+)
+
+// ConfigHealthReport combines validation errors with security and
+// environment warnings
+type ConfigHealthReport struct {
+	Status HealthStatus  `json:"status"`
+	Score  int           `json:"score"` // 0-100, 100 without any findings
+	Items  []ReportEntry `json:"items"`
+}
+
+// CheckHealth validates config against its struct constraints, the security
+// rules and the requirements of its environment. Secrets are masked in the
+// messages of the returned items.
+func CheckHealth(config *Config) *ConfigHealthReport {
+	items := validationFindings(config)
+	items = append(items, NewSecurityValidator(config).Findings()...)
+	items = append(items, NewEnvironmentValidator(config.App.Environment).Findings(config)...)
+
+	report := &ConfigHealthReport{Status: HealthHealthy, Score: 100, Items: items}
+	secrets := secretValues(config)
+	for i := range report.Items {
+		item := &report.Items[i]
+		for _, secret := range secrets {
+			item.Message = strings.ReplaceAll(item.Message, secret, maskedSecret)
+		}
+
+		switch item.Severity {
+		case SeverityError:
+			report.Status = HealthErrors
+			report.Score -= healthErrorPenalty
+		case SeverityWarning:
+			if report.Status == HealthHealthy {
+				report.Status = HealthWarnings
+			}
+			report.Score -= healthWarningPenalty
+		}
+	}
+	report.Score = max(report.Score, 0)
+	return report
+}
+
+// validationFindings returns the struct validation errors of config as
+// report entries. Their IDs are derived from the field's JSON path, e.g.
+// "invalid-api-baseUrl".
+func validationFindings(config *Config) []ReportEntry {
+	var validationErrors validator.ValidationErrors
+	if err := validate.Struct(config); !errors.As(err, &validationErrors) {
+		return nil
+	}
+
+	findings := make([]ReportEntry, 0, len(validationErrors))
+	for _, fe := range validationErrors {
+		path := jsonPath(fe.StructNamespace())
+		section, _, _ := strings.Cut(path, ".")
+		findings = append(findings, ReportEntry{
+			ID:       "invalid-" + strings.ReplaceAll(path, ".", "-"),
+			Severity: SeverityError,
+			Section:  section,
+			Message:  ValidationMessage(fe),
+		})
+	}
+	return findings
+}
+
+// jsonPath converts a validator namespace such as "Config.API.BaseURL" to
+// the JSON path of the field, "api.baseUrl"
+func jsonPath(namespace string) string {
+	names := strings.Split(namespace, ".")[1:]
+	t := reflect.TypeOf(Config{})
+	for i, name := range names {
+		if t.Kind() != reflect.Struct {
+			break
+		}
+		field, ok := t.FieldByName(name)
+		if !ok {
+			break
+		}
+		names[i] = jsonName(field)
+		t = field.Type
+	}
+	return strings.Join(names, ".")
+}
+
+// secretValues returns the secret values set in config
+func secretValues(config *Config) []string {
+	return sensitiveValues(reflect.ValueOf(*config))
+}
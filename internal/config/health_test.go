@@ -0,0 +1,115 @@
+package config
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+// healthyConfig returns a copy of the repository config that passes every
+// health check
+func healthyConfig(t *testing.T) *Config {
+	t.Helper()
+
+	cfg := *loadRepoConfig(t)
+	cfg.App.Environment = Development
+	cfg.App.Debug = true
+	cfg.API.BaseURL = "http://localhost:8080/api"
+	cfg.Security.CORSOrigins = []string{"http://localhost:5173"}
+	cfg.Security.CSRFEnabled = false
+	cfg.Security.RateLimitEnabled = false
+	return &cfg
+}
+
+// healthIDs returns the IDs of the report items
+func healthIDs(report *ConfigHealthReport) []string {
+	ids := make([]string, 0, len(report.Items))
+	for _, item := range report.Items {
+		ids = append(ids, item.ID)
+	}
+	return ids
+}
+
+func TestCheckHealthHealthy(t *testing.T) {
+	report := CheckHealth(healthyConfig(t))
+	if report.Status != HealthHealthy || report.Score != 100 || len(report.Items) != 0 {
+		t.Errorf("report = %+v, want healthy with score 100 and no items", report)
+	}
+}
+
+func TestCheckHealthWarnings(t *testing.T) {
+	cfg := healthyConfig(t)
+	cfg.App.Environment = Production
+	cfg.API.BaseURL = "https://api.example.com"
+	cfg.Database.SSLMode = "require"
+	cfg.Security.RateLimitEnabled = true
+	cfg.Security.RateLimitRPS = 100
+	cfg.Security.RateLimitBurst = 200
+	cfg.Security.CORSEnabled = false
+
+	report := CheckHealth(cfg)
+	if report.Status != HealthWarnings {
+		t.Errorf("Status = %q, want %q", report.Status, HealthWarnings)
+	}
+
+	// Debug mode is flagged by both the security and environment checks
+	ids := healthIDs(report)
+	for _, want := range []string{WarningProductionDebug, EnvProductionDebug} {
+		if !slices.Contains(ids, want) {
+			t.Errorf("IDs = %q, missing %q", ids, want)
+		}
+	}
+	if want := 100 - len(report.Items)*healthWarningPenalty; report.Score != want {
+		t.Errorf("Score = %d, want %d", report.Score, want)
+	}
+}
+
+func TestCheckHealthErrors(t *testing.T) {
+	cfg := healthyConfig(t)
+	cfg.API.BaseURL = ""
+
+	report := CheckHealth(cfg)
+	if report.Status != HealthErrors {
+		t.Errorf("Status = %q, want %q", report.Status, HealthErrors)
+	}
+
+	i := slices.IndexFunc(report.Items, func(item ReportEntry) bool { return item.ID == "invalid-api-baseUrl" })
+	if i < 0 {
+		t.Fatalf("IDs = %q, missing invalid-api-baseUrl", healthIDs(report))
+	}
+	if item := report.Items[i]; item.Severity != SeverityError || item.Section != "api" {
+		t.Errorf("item = %+v, want an error in section api", item)
+	}
+}
+
+func TestCheckHealthScoreFloor(t *testing.T) {
+	cfg := healthyConfig(t)
+	cfg.API.BaseURL = ""
+	cfg.API.Timeout = 0
+	cfg.API.MaxIdleConn = 0
+	cfg.Window.Width = 0
+	cfg.Window.Height = 0
+	cfg.Auth.TokenExpiry = 0
+
+	if report := CheckHealth(cfg); report.Score != 0 {
+		t.Errorf("Score = %d, want 0", report.Score)
+	}
+}
+
+func TestCheckHealthMasksSecrets(t *testing.T) {
+	const secret = "not-a-valid-origin-secret"
+	cfg := healthyConfig(t)
+	cfg.Security.CORSOrigins = []string{secret}
+	cfg.Security.CSRFEnabled = true
+	cfg.Security.CSRFSecret = secret
+
+	report := CheckHealth(cfg)
+	if !slices.Contains(healthIDs(report), WarningCORSInvalidOrigin) {
+		t.Fatalf("IDs = %q, missing %q", healthIDs(report), WarningCORSInvalidOrigin)
+	}
+	for _, item := range report.Items {
+		if strings.Contains(item.Message, secret) {
+			t.Errorf("message %q exposes the secret", item.Message)
+		}
+	}
+}
@@ -1,32 +1,92 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/go-playground/validator/v10"
+	"golang.org/x/net/http/httpguts"
 	"gopkg.in/ini.v1"
 )
 
 var (
-	validate  *validator.Validate
+	validate *validator.Validate
+
+	// stateMu guards the loaded configuration: instance, iniConfig,
+	// activeProfile and the load state below. Exported functions that use
+	// them hold it; the unexported ones expect it held.
+	stateMu   sync.Mutex
 	instance  *Config
 	iniConfig *ini.File
+
+	// loadErrors and loadWarnings collect problems raised by the value
+	// helpers during a load
+	loadErrors   []error
+	loadWarnings []ReportEntry
+
+	// envVarPattern matches ${VAR} and ${VAR:-default} references
+	envVarPattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(:-([^}]*))?\}`)
 )
 
 func init() {
 	validate = validator.New()
 
+	// Keep "key = value" formatting without column alignment so write-back
+	// only changes the edited lines
+	ini.PrettyFormat = false
+	ini.PrettyEqual = true
+
 	// Register custom validators
 	validate.RegisterValidation("semver", validateSemver)
+	validationMessages["semver"] = func(fe validator.FieldError, label string) string {
+		return label + " must be a semantic version such as 1.2.3"
+	}
+	validate.RegisterValidation("http_token", validateHTTPToken)
+	validationMessages["http_token"] = func(fe validator.FieldError, label string) string {
+		return label + " may only contain letters, digits and !#$%&'*+-.^_`|~"
+	}
+	validate.RegisterValidation("relative_path", validateRelativePath)
+	validationMessages["relative_path"] = func(fe validator.FieldError, label string) string {
+		return label + " must be a path relative to the API base URL, such as /health"
+	}
+	validate.RegisterValidation("regexp", validateRegexp)
+	validationMessages["regexp"] = func(fe validator.FieldError, label string) string {
+		return label + " must be a valid regular expression"
+	}
+	validate.RegisterValidation("endpoint", validateEndpoint)
+	validationMessages["endpoint"] = func(fe validator.FieldError, label string) string {
+		return fmt.Sprintf("%s must be a URL, optionally followed by ;weight=N with N from 1 to %d", label, MaxEndpointWeight)
+	}
+	validate.RegisterValidation("log_outputs", validateLogOutputs)
+	validationMessages["log_outputs"] = func(fe validator.FieldError, label string) string {
+		return label + " must be a comma-separated list of console, file or both"
+	}
+	validate.RegisterStructValidation(validateAuthConfig, AuthConfig{})
+	validate.RegisterStructValidation(validateWindowConfig, WindowConfig{})
+	validate.RegisterStructValidation(validateAPIConfig, APIConfig{})
+	validate.RegisterStructValidation(validateProductionConfig, Config{})
+	validationMessages["production_disabled"] = func(fe validator.FieldError, label string) string {
+		return label + " can't be used in production"
+	}
 }
 
 // LoadConfig loads configuration from INI files
 func LoadConfig() (*Config, error) {
+	stateMu.Lock()
+	defer stateMu.Unlock()
+	return loadConfig()
+}
+
+func loadConfig() (*Config, error) {
 	if instance != nil {
 		return instance, nil
 	}
@@ -37,45 +97,69 @@ func LoadConfig() (*Config, error) {
 		env = "development"
 	}
 
-	// Load single INI configuration file
+	// Load the INI configuration from its source
+	source := ConfigSource()
 	var err error
-	iniConfig, err = ini.Load("config.ini")
+	iniConfig, err = loadSource(source)
 	if err != nil {
-		return nil, fmt.Errorf("failed to load configuration file config.ini: %w", err)
+		return nil, fmt.Errorf("failed to load configuration from %s: %w", source, err)
 	}
+	loadErrors = nil
+	loadWarnings = nil
 
-	config := &Config{
-		App:      loadAppConfig(),
-		API:      loadAPIConfig(),
-		Auth:     loadAuthConfig(),
-		Log:      loadLogConfig(),
-		Database: loadDatabaseConfig(),
-		Security: loadSecurityConfig(),
-		Window:   loadWindowConfig(),
-		Cache:    loadCacheConfig(),
+	// Check the file as written, before profiles and environment variables
+	// add keys to it
+	unknown := unknownConfigEntries(iniConfig)
+
+	// Apply the active profile over the base sections. Environment
+	// variables and the secrets file still take precedence.
+	if err := applyProfile(activeProfile); err != nil {
+		return nil, err
+	}
+
+	// Apply environment overrides and merge sensitive keys from the secrets
+	// file before reading the sections
+	overridden := applyEnvOverrides()
+	appConfig := loadAppConfig()
+	if activeProfile != "" && appConfig.Environment.IsProduction() {
+		return nil, ErrProfilesDisabled
+	}
+	if len(unknown) > 0 {
+		if appConfig.StrictConfig {
+			return nil, unknownConfigError(unknown)
+		}
+		loadWarnings = append(loadWarnings, unknown...)
+	}
+	if err := mergeSecrets(appConfig.Environment, overridden); err != nil {
+		return nil, err
+	}
+
+	config := loadSections(appConfig)
+
+	// Fail on values that could not be resolved (e.g. undefined env variables)
+	if len(loadErrors) > 0 {
+		return nil, fmt.Errorf("failed to resolve configuration values: %w", errors.Join(loadErrors...))
 	}
 
 	// Validate configuration structure
 	if err := validate.Struct(config); err != nil {
-		return nil, fmt.Errorf("configuration validation failed: %w", err)
+		return nil, newValidationError(err)
+	}
+
+	// Apply the staging profile before the settings are checked
+	if err := applyStagingProfile(config); err != nil {
+		return nil, err
 	}
 
 	// Validate environment-specific requirements
+	// Don't fail on environment validation errors, just warn
 	envValidator := NewEnvironmentValidator(env)
-	if envErrors := envValidator.ValidateEnvironment(config); len(envErrors) > 0 {
-		for _, err := range envErrors {
-			fmt.Printf("Environment Validation Error: %s\n", err)
-		}
-		// Don't fail on environment validation errors, just warn
-	}
+	report := append(loadWarnings, newReportEntries(SeverityError, "environment", envValidator.ValidateEnvironment(config))...)
 
 	// Validate security settings
 	secValidator := NewSecurityValidator(config)
-	if secWarnings := secValidator.ValidateSecuritySettings(); len(secWarnings) > 0 {
-		for _, warning := range secWarnings {
-			fmt.Printf("Security Warning: %s\n", warning)
-		}
-	}
+	report = append(report, newReportEntries(SeverityWarning, "security", secValidator.ValidateSecuritySettings())...)
+	printReport(report)
 
 	// Post-validation adjustments
 	if err := postValidationAdjustments(config); err != nil {
@@ -86,29 +170,72 @@ func LoadConfig() (*Config, error) {
 	return config, nil
 }
 
+// loadSections reads every section other than [app] from the INI file
+func loadSections(app AppConfig) *Config {
+	return &Config{
+		App:      app,
+		API:      loadAPIConfig(),
+		Auth:     loadAuthConfig(),
+		Log:      loadLogConfig(),
+		Database: loadDatabaseConfig(),
+		Security: loadSecurityConfig(),
+		Window:   loadWindowConfig(),
+		Cache:    loadCacheConfig(),
+		TLS:      loadTLSConfig(),
+	}
+}
+
+// Validate validates a configuration against its struct constraints
+func Validate(config *Config) error {
+	return validate.Struct(config)
+}
+
 // GetConfig returns the loaded configuration instance
 func GetConfig() *Config {
+	stateMu.Lock()
+	defer stateMu.Unlock()
+	return loadedConfig()
+}
+
+func loadedConfig() *Config {
 	if instance == nil {
 		panic("configuration not loaded. Call LoadConfig() first")
 	}
 	return instance
 }
 
-// ReloadConfig reloads the configuration
+// ReloadConfig reloads the configuration. When the new configuration fails
+// to load, the previous one stays in effect and GetConfig keeps returning it.
 func ReloadConfig() (*Config, error) {
+	stateMu.Lock()
+	defer stateMu.Unlock()
+	return reloadConfig()
+}
+
+func reloadConfig() (*Config, error) {
+	previous, previousFile := instance, iniConfig
 	instance = nil
-	return LoadConfig()
+	config, err := loadConfig()
+	if err != nil {
+		instance, iniConfig = previous, previousFile
+		return nil, err
+	}
+	return config, nil
 }
 
 // GetPublicConfig returns configuration safe for frontend consumption
 func GetPublicConfig() *PublicConfig {
-	config := GetConfig()
+	stateMu.Lock()
+	defer stateMu.Unlock()
+
+	config := loadedConfig()
 	return &PublicConfig{
 		App: PublicAppConfig{
 			Environment: config.App.Environment,
 			Name:        config.App.Name,
 			Version:     config.App.Version,
 			Debug:       config.App.Debug,
+			Profile:     activeProfile,
 		},
 		API: PublicAPIConfig{
 			Timeout:    config.API.Timeout,
@@ -131,83 +258,159 @@ func loadAppConfig() AppConfig {
 	}
 
 	return AppConfig{
-		Environment: Environment(env),
-		Name:        getConfigValue("app", "name", "CSmart Wails App"),
-		Version:     getConfigValue("app", "version", "1.0.0"),
-		Debug:       getConfigBool("app", "debug", true),
-		HotReload:   getConfigBool("development", "hot_reload", true),
-		DevTools:    getConfigBool("development", "dev_tools", true),
-		MockAPI:     getConfigBool("development", "mock_api", false),
+		Environment:       Environment(env),
+		Name:              getConfigValue("app", "name", "CSmart Wails App"),
+		Version:           getConfigValue("app", "version", "1.0.0"),
+		Debug:             getConfigBool("app", "debug", true),
+		HotReload:         getConfigBool("development", "hot_reload", true),
+		DevTools:          getConfigBool("development", "dev_tools", true),
+		MockAPI:           getConfigBool("development", "mock_api", false),
+		FailFast:          getConfigBool("app", "fail_fast", false),
+		SelfTestAPI:       getConfigBool("app", "self_test_api", false),
+		ReadOnly:          getConfigBool("app", "readonly", false),
+		SelfTestDatabase:  getConfigBool("app", "self_test_database", false),
+		StartupTimeout:    getConfigDuration("app", "startup_timeout", 10*time.Second),
+		RecoverPanics:     getConfigBool("app", "recover_panics", true),
+		PushInitialConfig: getConfigBool("app", "push_initial_config", true),
+		WatchInterval:     getConfigDuration("app", "watch_interval", 2*time.Second),
+		ThemePollInterval: getConfigDuration("app", "theme_poll_interval", 5*time.Second),
+		Locale:            getConfigValue("app", "locale", "en"),
+		MessagesFile:      getConfigValue("app", "messages_file", ""),
+		StrictConfig:      getConfigBool("app", "strict_config", false),
 	}
 }
 
 func loadAPIConfig() APIConfig {
 	return APIConfig{
-		BaseURL:     getConfigValue("api", "base_url", ""),
-		Timeout:     getConfigDuration("api", "timeout", 30*time.Second),
-		RetryCount:  getConfigInt("api", "retry_count", 3),
-		RetryDelay:  getConfigDuration("api", "retry_delay", 1*time.Second),
-		UserAgent:   getConfigValue("api", "user_agent", "CSmart-Wails/1.0"),
-		MaxIdleConn: getConfigInt("api", "max_idle_conn", 10),
+		BaseURL:              getConfigValue("api", "base_url", ""),
+		BaseURLs:             getConfigList("api", "base_urls"),
+		EndpointCooldown:     getConfigDuration("api", "endpoint_cooldown", 30*time.Second),
+		Timeout:              getConfigDuration("api", "timeout", 30*time.Second),
+		RetryCount:           getConfigInt("api", "retry_count", 3),
+		RetryDelay:           getConfigDuration("api", "retry_delay", 1*time.Second),
+		JitterStrategy:       JitterStrategy(getConfigValue("api", "jitter_strategy", string(JitterFull))),
+		UserAgent:            getConfigValue("api", "user_agent", "CSmart-Wails/1.0"),
+		MaxIdleConn:          getConfigInt("api", "max_idle_conn", 10),
+		IdleConnTimeout:      getConfigDuration("api", "idle_conn_timeout", 90*time.Second),
+		KeepAlive:            getConfigDuration("api", "keep_alive", 30*time.Second),
+		MaxConcurrent:        getConfigInt("api", "max_concurrent_requests", 10),
+		MaxResponseBytes:     int64(getConfigInt("api", "max_response_bytes", 10<<20)),
+		DownloadTimeout:      getConfigDuration("api", "download_timeout", time.Hour),
+		DNSCacheTTL:          getConfigDuration("api", "dns_cache_ttl", 0),
+		DefaultPageSize:      getConfigInt("api", "default_page_size", 20),
+		MaxPageSize:          getConfigInt("api", "max_page_size", 100),
+		TenantURLTemplate:    getConfigValue("api", "tenant_url_template", ""),
+		MinVersion:           getConfigValue("api", "min_version", ""),
+		DefaultHeaders:       getConfigHeaders("api", "default_headers"),
+		SigningEnabled:       getConfigBool("api", "signing_enabled", false),
+		SigningSecret:        getConfigValue("api", "signing_secret", ""),
+		SigningHeader:        getConfigValue("api", "signing_header", "X-Signature"),
+		ValidateResponses:    getConfigBool("api", "validate_responses", false),
+		CompressRequests:     getConfigBool("api", "compress_requests", false),
+		CompressMinBytes:     getConfigInt("api", "compress_min_bytes", 1024),
+		ForceHTTP1:           getConfigBool("api", "force_http1", false),
+		AllowHTTP2Cleartext:  getConfigBool("api", "allow_http2_cleartext", false),
+		HealthPath:           getConfigValue("api", "health_path", ""),
+		HealthExpectedStatus: getConfigIntList("api", "health_expected_status", []int{http.StatusOK}),
+		FollowRedirects:      getConfigBool("api", "follow_redirects", true),
+		MaxRedirects:         getConfigInt("api", "max_redirects", 5),
+		VerifyBaseURLOnStart: getConfigBool("api", "verify_base_url_on_start", false),
+		RecordTo:             getConfigValue("api", "record_to", ""),
+		ReplayFrom:           getConfigValue("api", "replay_from", ""),
 	}
 }
 
 func loadAuthConfig() AuthConfig {
 	return AuthConfig{
+		Strategy:           getConfigValue("auth", "strategy", AuthStrategyPassword),
 		TokenExpiry:        getConfigDuration("auth", "token_expiry", 3600*time.Second),
 		RefreshThreshold:   getConfigDuration("auth", "refresh_threshold", 300*time.Second),
+		ClockSkewTolerance: getConfigDuration("auth", "clock_skew_tolerance", 30*time.Second),
 		MaxLoginAttempts:   getConfigInt("auth", "max_login_attempts", 5),
+		MinPasswordLength:  getConfigInt("auth", "min_password_length", 0),
 		LockoutDuration:    getConfigDuration("auth", "lockout_duration", 15*time.Minute),
 		SessionTimeout:     getConfigDuration("auth", "session_timeout", 24*time.Hour),
 		RememberMeDuration: getConfigDuration("auth", "remember_me_duration", 30*24*time.Hour),
+		ClientID:           getConfigValue("auth", "client_id", ""),
+		ClientSecret:       getConfigValue("auth", "client_secret", ""),
+		APIKey:             getConfigValue("auth", "api_key", ""),
+		HeaderName:         getConfigValue("auth", "auth_header_name", "Authorization"),
+		Scheme:             getConfigValue("auth", "auth_scheme", ""),
 	}
 }
 
 func loadLogConfig() LogConfig {
-	return LogConfig{
-		Level:      LogLevel(getConfigValue("log", "level", "debug")),
-		Format:     LogFormat(getConfigValue("log", "format", "json")),
-		Output:     LogOutput(getConfigValue("log", "output", "console")),
-		FilePath:   getConfigValue("log", "file_path", "logs/app.log"),
-		MaxSize:    getConfigInt("log", "max_size", 100),
-		MaxBackups: getConfigInt("log", "max_backups", 3),
-		MaxAge:     getConfigInt("log", "max_age", 28),
-		Compress:   getConfigBool("log", "compress", true),
+	logConfig := LogConfig{
+		Level:            LogLevel(getConfigValue("log", "level", "debug")),
+		Format:           LogFormat(getConfigValue("log", "format", "json")),
+		Output:           LogOutput(getConfigValue("log", "output", "console")),
+		FilePath:         getConfigValue("log", "file_path", "logs/app.log"),
+		MaxSize:          getConfigInt("log", "max_size", 100),
+		MaxBackups:       getConfigInt("log", "max_backups", 3),
+		MaxAge:           getConfigInt("log", "max_age", 28),
+		Compress:         getConfigBool("log", "compress", true),
+		MemoryBufferSize: getConfigInt("log", "memory_buffer_size", 500),
+		AuditFile:        getConfigValue("log", "audit_file", ""),
+		RedactPatterns:   getConfigFields("log", "redact_patterns"),
+	}
+	normalizeLogRotation(&logConfig)
+	return logConfig
+}
+
+// normalizeLogRotation warns about rotation settings that would discard logs
+// or have no effect when logging to a file, and resets a non-positive
+// MaxSize to its default
+func normalizeLogRotation(logConfig *LogConfig) {
+	if !logConfig.Output.Has(LogOutputFile) {
+		return
+	}
+
+	warn := func(format string, args ...any) {
+		loadWarnings = append(loadWarnings, ReportEntry{
+			Severity: SeverityWarning,
+			Section:  "log",
+			Message:  fmt.Sprintf(format, args...),
+		})
+	}
+
+	if logConfig.MaxSize <= 0 {
+		warn("max_size must be positive when logging to a file, using 100 MB")
+		logConfig.MaxSize = 100
+	}
+	if logConfig.MaxBackups == 0 && logConfig.MaxAge > 0 {
+		warn("max_backups is 0, so rotated logs are discarded immediately and max_age = %d has no effect", logConfig.MaxAge)
+	}
+	if logConfig.MaxBackups == 0 && logConfig.Compress {
+		warn("compress is enabled but max_backups is 0, so there are no rotated logs to compress")
 	}
 }
 
 func loadDatabaseConfig() DatabaseConfig {
 	return DatabaseConfig{
-		Host:         getConfigValue("database", "host", "localhost"),
-		Port:         getConfigInt("database", "port", 5432),
-		Name:         getConfigValue("database", "name", "csmart"),
-		Username:     getConfigValue("database", "username", ""),
-		Password:     getConfigValue("database", "password", ""),
-		SSLMode:      getConfigValue("database", "ssl_mode", "disable"),
-		MaxOpenConns: getConfigInt("database", "max_open_conns", 25),
-		MaxIdleConns: getConfigInt("database", "max_idle_conns", 5),
-		ConnLifetime: getConfigDuration("database", "conn_lifetime", 5*time.Minute),
+		Host:            getConfigValue("database", "host", "localhost"),
+		Port:            getConfigInt("database", "port", 5432),
+		Name:            getConfigValue("database", "name", "csmart"),
+		Username:        getConfigValue("database", "username", ""),
+		Password:        getConfigValue("database", "password", ""),
+		SSLMode:         getConfigValue("database", "ssl_mode", "disable"),
+		MaxOpenConns:    getConfigInt("database", "max_open_conns", 25),
+		MaxIdleConns:    getConfigInt("database", "max_idle_conns", 5),
+		ConnLifetime:    getConfigDuration("database", "conn_lifetime", 5*time.Minute),
+		ConnMaxIdleTime: getConfigDuration("database", "conn_max_idle_time", time.Minute),
 	}
 }
 
 func loadSecurityConfig() SecurityConfig {
-	corsOrigins := getConfigValue("security", "cors_origins", "")
-	var origins []string
-	if corsOrigins != "" {
-		origins = strings.Split(corsOrigins, ",")
-		for i, origin := range origins {
-			origins[i] = strings.TrimSpace(origin)
-		}
-	}
-
 	return SecurityConfig{
 		CORSEnabled:      getConfigBool("security", "cors_enabled", true),
-		CORSOrigins:      origins,
+		CORSOrigins:      getConfigList("security", "cors_origins"),
 		RateLimitEnabled: getConfigBool("security", "rate_limit_enabled", false),
 		RateLimitRPS:     getConfigInt("security", "rate_limit_rps", 100),
 		RateLimitBurst:   getConfigInt("security", "rate_limit_burst", 200),
+		RateLimitGrace:   getConfigInt("security", "rate_limit_grace", 0),
 		CSRFEnabled:      getConfigBool("security", "csrf_enabled", false),
 		CSRFSecret:       getConfigValue("security", "csrf_secret", ""),
+		DisabledWarnings: getConfigList("security", "disabled_warnings"),
 	}
 }
 
@@ -220,6 +423,8 @@ func loadWindowConfig() WindowConfig {
 		Maximized:   getConfigBool("window", "maximized", false),
 		Minimized:   getConfigBool("window", "minimized", false),
 		AlwaysOnTop: getConfigBool("window", "always_on_top", false),
+		Center:      getConfigBool("window", "center", true),
+		Monitor:     getConfigInt("window", "monitor", 0),
 	}
 }
 
@@ -230,12 +435,24 @@ func loadCacheConfig() CacheConfig {
 		MaxSize:            getConfigInt("cache", "max_size", 100),
 		MaxItems:           getConfigInt("cache", "max_items", 10000),
 		CompressionEnabled: getConfigBool("cache", "compression_enabled", false),
+		ServeStaleOnError:  getConfigBool("cache", "serve_stale_on_error", false),
+		MaxStale:           getConfigDuration("cache", "max_stale", 24*time.Hour),
 		EvictionPolicy:     getConfigValue("cache", "eviction_policy", "lru"),
 	}
 }
 
+func loadTLSConfig() TLSConfig {
+	return TLSConfig{
+		CACertPath:     getConfigValue("tls", "ca_cert_path", ""),
+		ClientCertPath: getConfigValue("tls", "client_cert_path", ""),
+		ClientKeyPath:  getConfigValue("tls", "client_key_path", ""),
+		MinVersion:     getConfigValue("tls", "min_version", "1.2"),
+	}
+}
+
 // Helper functions for INI configuration parsing
 func getConfigValue(section, key, defaultValue string) string {
+	recordDefault(section, key, defaultValue)
 	if iniConfig == nil {
 		return defaultValue
 	}
@@ -243,41 +460,147 @@ func getConfigValue(section, key, defaultValue string) string {
 	if sec == nil {
 		return defaultValue
 	}
-	return sec.Key(key).MustString(defaultValue)
+	value, err := expandEnvVars(sec.Key(key).MustString(defaultValue))
+	if err != nil {
+		loadErrors = append(loadErrors, fmt.Errorf("%s.%s: %w", section, key, err))
+		return defaultValue
+	}
+	return value
+}
+
+// expandEnvVars replaces ${VAR} and ${VAR:-default} references with values
+// from the environment. A reference to an unset variable without a default
+// is reported as an error instead of being left in place.
+func expandEnvVars(value string) (string, error) {
+	var missing []string
+	expanded := envVarPattern.ReplaceAllStringFunc(value, func(ref string) string {
+		match := envVarPattern.FindStringSubmatch(ref)
+		name, hasDefault, fallback := match[1], match[2] != "", match[3]
+		if envValue, ok := os.LookupEnv(name); ok {
+			return envValue
+		}
+		if hasDefault {
+			return fallback
+		}
+		missing = append(missing, name)
+		return ref
+	})
+	if len(missing) > 0 {
+		return "", fmt.Errorf("environment variable %s is not set", strings.Join(missing, ", "))
+	}
+	return expanded, nil
+}
+
+// getConfigList reads a comma-separated value into a slice of trimmed items
+func getConfigList(section, key string) []string {
+	value := getConfigValue(section, key, "")
+	if value == "" {
+		return nil
+	}
+	items := strings.Split(value, ",")
+	for i, item := range items {
+		items[i] = strings.TrimSpace(item)
+	}
+	return items
+}
+
+// getConfigFields reads a whitespace-separated value into a slice, for
+// items such as regular expressions that may contain commas
+func getConfigFields(section, key string) []string {
+	return strings.Fields(getConfigValue(section, key, ""))
+}
+
+// getConfigIntList reads a comma-separated list of integers. An entry that
+// isn't an integer is a load error.
+func getConfigIntList(section, key string, defaultValue []int) []int {
+	defaults := make([]string, len(defaultValue))
+	for i, value := range defaultValue {
+		defaults[i] = strconv.Itoa(value)
+	}
+	value := getConfigValue(section, key, strings.Join(defaults, ", "))
+	if value == "" {
+		return defaultValue
+	}
+
+	var parsed []int
+	for _, item := range strings.Split(value, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(item))
+		if err != nil {
+			loadErrors = append(loadErrors, fmt.Errorf("%s.%s: %q is not an integer", section, key, strings.TrimSpace(item)))
+			continue
+		}
+		parsed = append(parsed, n)
+	}
+	return parsed
+}
+
+// getConfigHeaders reads a comma-separated list of "Name: Value" entries
+// into a header map. Malformed entries and illegal characters are load errors.
+func getConfigHeaders(section, key string) map[string]string {
+	items := getConfigList(section, key)
+	if len(items) == 0 {
+		return nil
+	}
+
+	headers := make(map[string]string, len(items))
+	for _, item := range items {
+		name, value, ok := strings.Cut(item, ":")
+		name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+		switch {
+		case !ok:
+			loadErrors = append(loadErrors, fmt.Errorf("%s.%s: header %q must have the form \"Name: Value\"", section, key, item))
+		case !httpguts.ValidHeaderFieldName(name):
+			loadErrors = append(loadErrors, fmt.Errorf("%s.%s: invalid header name %q", section, key, name))
+		case !httpguts.ValidHeaderFieldValue(value):
+			loadErrors = append(loadErrors, fmt.Errorf("%s.%s: invalid value for header %q", section, key, name))
+		default:
+			headers[http.CanonicalHeaderKey(name)] = value
+		}
+	}
+	return headers
 }
 
 func getConfigInt(section, key string, defaultValue int) int {
+	recordDefault(section, key, strconv.Itoa(defaultValue))
 	if iniConfig == nil {
 		return defaultValue
 	}
-	sec := iniConfig.Section(section)
-	if sec == nil {
+	value, ok := rawConfigValue(section, key, defaultValue)
+	if !ok {
 		return defaultValue
 	}
-	return sec.Key(key).MustInt(defaultValue)
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		recordInvalidValue(section, key, value, defaultValue)
+		return defaultValue
+	}
+	return parsed
 }
 
 func getConfigBool(section, key string, defaultValue bool) bool {
+	recordDefault(section, key, strconv.FormatBool(defaultValue))
 	if iniConfig == nil {
 		return defaultValue
 	}
-	sec := iniConfig.Section(section)
-	if sec == nil {
+	value, ok := rawConfigValue(section, key, defaultValue)
+	if !ok {
 		return defaultValue
 	}
-	return sec.Key(key).MustBool(defaultValue)
+	parsed, err := iniConfig.Section(section).Key(key).Bool()
+	if err != nil {
+		recordInvalidValue(section, key, value, defaultValue)
+		return defaultValue
+	}
+	return parsed
 }
 
 func getConfigDuration(section, key string, defaultValue time.Duration) time.Duration {
+	recordDefault(section, key, formatDuration(defaultValue))
 	if iniConfig == nil {
 		return defaultValue
 	}
-	sec := iniConfig.Section(section)
-	if sec == nil {
-		return defaultValue
-	}
-	value := sec.Key(key).String()
-	if value == "" {
+	value, ok := rawConfigValue(section, key, defaultValue)
+	if !ok {
 		return defaultValue
 	}
 
@@ -289,19 +612,56 @@ func getConfigDuration(section, key string, defaultValue time.Duration) time.Dur
 	if seconds, err := strconv.Atoi(value); err == nil {
 		return time.Duration(seconds) * time.Second
 	}
+	recordInvalidValue(section, key, value, defaultValue)
 	return defaultValue
 }
 
+// rawConfigValue returns the value of section.key and whether it is set.
+// A key that is present but empty is recorded as missing. A key that is
+// absent stays silent, since config.ini only lists the values a deployment
+// changes and relies on the defaults for the rest.
+func rawConfigValue(section, key string, defaultValue any) (string, bool) {
+	sec := iniConfig.Section(section)
+	if !sec.HasKey(key) {
+		return "", false
+	}
+	value := sec.Key(key).String()
+	if value == "" {
+		loadWarnings = append(loadWarnings, ReportEntry{
+			Severity: SeverityWarning,
+			Section:  section,
+			Message:  fmt.Sprintf("missing value for %s.%s, using default %v", section, key, defaultValue),
+		})
+		return "", false
+	}
+	return value, true
+}
+
+// recordInvalidValue records a warning for a value that is present but
+// can't be parsed, so the default is used instead
+func recordInvalidValue(section, key, value string, defaultValue any) {
+	loadWarnings = append(loadWarnings, ReportEntry{
+		Severity: SeverityWarning,
+		Section:  section,
+		Message:  fmt.Sprintf("invalid value %q for %s.%s, using default %v", value, section, key, defaultValue),
+	})
+}
+
 // postValidationAdjustments performs any necessary adjustments after validation
 func postValidationAdjustments(config *Config) error {
 	// Ensure log directory exists
-	if config.Log.Output == LogOutputFile || config.Log.Output == LogOutputBoth {
+	if config.Log.Output.Has(LogOutputFile) {
 		logDir := filepath.Dir(config.Log.FilePath)
 		if err := os.MkdirAll(logDir, 0755); err != nil {
 			return fmt.Errorf("failed to create log directory: %w", err)
 		}
 	}
 
+	// Response validation is a development aid and never runs in production
+	if config.App.Environment.IsProduction() {
+		config.API.ValidateResponses = false
+	}
+
 	// Set user agent if not provided
 	if config.API.UserAgent == "" {
 		config.API.UserAgent = fmt.Sprintf("%s/%s", config.App.Name, config.App.Version)
@@ -310,18 +670,108 @@ func postValidationAdjustments(config *Config) error {
 	return nil
 }
 
-// validateSemver validates semantic version format
+// validateSemver validates semantic version format, including pre-release
+// and build metadata
 func validateSemver(fl validator.FieldLevel) bool {
-	version := fl.Field().String()
-	// Simple semver validation (major.minor.patch)
-	parts := strings.Split(version, ".")
-	if len(parts) != 3 {
-		return false
-	}
-	for _, part := range parts {
-		if _, err := strconv.Atoi(part); err != nil {
+	_, err := ParseSemver(fl.Field().String())
+	return err == nil
+}
+
+// validateHTTPToken validates that a value may be used as an HTTP header
+// name or authentication scheme
+func validateHTTPToken(fl validator.FieldLevel) bool {
+	return httpguts.ValidHeaderFieldName(fl.Field().String())
+}
+
+// validateRelativePath validates that a value is a URL path without scheme
+// or host, so it can be appended to the API base URL
+func validateRelativePath(fl validator.FieldLevel) bool {
+	u, err := url.Parse(fl.Field().String())
+	return err == nil && u.Scheme == "" && u.Host == "" && !strings.HasPrefix(u.Path, "//")
+}
+
+// validateRegexp validates that a value compiles as a regular expression
+func validateRegexp(fl validator.FieldLevel) bool {
+	_, err := regexp.Compile(fl.Field().String())
+	return err == nil
+}
+
+// validateEndpoint validates an api.base_urls entry with an optional
+// weight
+func validateEndpoint(fl validator.FieldLevel) bool {
+	_, _, err := ParseEndpoint(fl.Field().String())
+	return err == nil
+}
+
+// validateLogOutputs validates that every entry of a comma-separated log
+// output list is a known output
+func validateLogOutputs(fl validator.FieldLevel) bool {
+	outputs := LogOutput(fl.Field().String()).Outputs()
+	for output := range outputs {
+		if output != LogOutputConsole && output != LogOutputFile {
 			return false
 		}
 	}
-	return true
+	return len(outputs) > 0
+}
+
+// validateProductionConfig enforces the production requirements that are
+// hard errors rather than warnings
+func validateProductionConfig(sl validator.StructLevel) {
+	config := sl.Current().Interface().(Config)
+	if !config.App.Environment.IsProduction() {
+		return
+	}
+
+	// Replayed responses must never stand in for the real API
+	if config.API.ReplayFrom != "" {
+		sl.ReportError(config.API.ReplayFrom, "API.ReplayFrom", "API.ReplayFrom", "production_disabled", "")
+	}
+}
+
+// validateAPIConfig rejects forcing HTTP/1.1 while also allowing HTTP/2
+// over cleartext
+func validateAPIConfig(sl validator.StructLevel) {
+	api := sl.Current().Interface().(APIConfig)
+
+	if api.ForceHTTP1 && api.AllowHTTP2Cleartext {
+		sl.ReportError(api.AllowHTTP2Cleartext, "AllowHTTP2Cleartext", "AllowHTTP2Cleartext", "excluded_with", "ForceHTTP1")
+	}
+}
+
+// minClientSecretLength is the minimum length of an OAuth client secret
+const minClientSecretLength = 32
+
+// validateAuthConfig requires the client ID and secret to be provided
+// together and the secret to have a minimum length, and an API key for the
+// api_key strategy
+func validateAuthConfig(sl validator.StructLevel) {
+	auth := sl.Current().Interface().(AuthConfig)
+
+	if auth.Strategy == AuthStrategyAPIKey && auth.APIKey == "" {
+		sl.ReportError(auth.APIKey, "APIKey", "APIKey", "required", "")
+	}
+	if auth.ClientID != "" && auth.ClientSecret == "" {
+		sl.ReportError(auth.ClientSecret, "ClientSecret", "ClientSecret", "required_with", "ClientID")
+	}
+	if auth.ClientSecret != "" && auth.ClientID == "" {
+		sl.ReportError(auth.ClientID, "ClientID", "ClientID", "required_with", "ClientSecret")
+	}
+	if auth.ClientSecret != "" && len(auth.ClientSecret) < minClientSecretLength {
+		sl.ReportError(auth.ClientSecret, "ClientSecret", "ClientSecret", "min", strconv.Itoa(minClientSecretLength))
+	}
+}
+
+// validateWindowConfig rejects contradictory initial window states: a
+// window can't start both minimized and maximized, and fullscreen already
+// covers the screen so it can't be combined with maximized
+func validateWindowConfig(sl validator.StructLevel) {
+	window := sl.Current().Interface().(WindowConfig)
+
+	if window.Minimized && window.Maximized {
+		sl.ReportError(window.Minimized, "Minimized", "Minimized", "excluded_with", "Maximized")
+	}
+	if window.Fullscreen && window.Maximized {
+		sl.ReportError(window.Fullscreen, "Fullscreen", "Fullscreen", "excluded_with", "Maximized")
+	}
 }
@@ -0,0 +1,653 @@
+package config
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"gopkg.in/ini.v1"
+)
+
+func TestExpandEnvVars(t *testing.T) {
+	t.Setenv("TEST_API_HOST", "api.example.com")
+	t.Setenv("TEST_EMPTY", "")
+
+	tests := []struct {
+		name  string
+		value string
+		want  string
+	}{
+		{"plain", "no references", "no references"},
+		{"simple", "${TEST_API_HOST}", "api.example.com"},
+		{"nested in text", "https://${TEST_API_HOST}/api", "https://api.example.com/api"},
+		{"default unused", "${TEST_API_HOST:-fallback}", "api.example.com"},
+		{"default used", "${TEST_UNSET_VAR:-fallback}", "fallback"},
+		{"empty default", "${TEST_UNSET_VAR:-}", ""},
+		{"set but empty", "x${TEST_EMPTY}y", "xy"},
+		{"multiple", "${TEST_API_HOST}:${TEST_UNSET_VAR:-8080}", "api.example.com:8080"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := expandEnvVars(tt.value)
+			if err != nil {
+				t.Fatalf("expandEnvVars(%q) error = %v", tt.value, err)
+			}
+			if got != tt.want {
+				t.Errorf("expandEnvVars(%q) = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpandEnvVarsMissing(t *testing.T) {
+	_, err := expandEnvVars("https://${TEST_MISSING_HOST}/${TEST_MISSING_PATH}")
+	if err == nil {
+		t.Fatal("expandEnvVars succeeded with unset variables")
+	}
+	for _, name := range []string{"TEST_MISSING_HOST", "TEST_MISSING_PATH"} {
+		if !strings.Contains(err.Error(), name) {
+			t.Errorf("error %q does not name %s", err, name)
+		}
+	}
+}
+
+// useINI replaces the loaded ini file with data for the rest of the test
+func useINI(t *testing.T, data string) {
+	t.Helper()
+
+	saved, savedWarnings := iniConfig, loadWarnings
+	t.Cleanup(func() { iniConfig, loadWarnings = saved, savedWarnings })
+
+	cfg, err := ini.Load([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	iniConfig, loadWarnings = cfg, nil
+}
+
+// wantWarning checks that exactly one load warning was recorded and that it
+// mentions substr
+func wantWarning(t *testing.T, substr string) {
+	t.Helper()
+	if len(loadWarnings) != 1 {
+		t.Fatalf("warnings = %v, want one containing %q", loadWarnings, substr)
+	}
+	if w := loadWarnings[0]; w.Severity != SeverityWarning || !strings.Contains(w.Message, substr) {
+		t.Errorf("warning = %+v, want a warning containing %q", w, substr)
+	}
+}
+
+func TestGetConfigInvalidValues(t *testing.T) {
+	useINI(t, "[window]\nwidth = wide\nresizable = maybe\n[api]\ntimeout = soon\n")
+
+	if got := getConfigInt("window", "width", 1200); got != 1200 {
+		t.Errorf("getConfigInt = %d, want default 1200", got)
+	}
+	wantWarning(t, `invalid value "wide" for window.width, using default 1200`)
+
+	loadWarnings = nil
+	if got := getConfigBool("window", "resizable", true); !got {
+		t.Error("getConfigBool = false, want default true")
+	}
+	wantWarning(t, `invalid value "maybe" for window.resizable, using default true`)
+
+	loadWarnings = nil
+	if got := getConfigDuration("api", "timeout", 30*time.Second); got != 30*time.Second {
+		t.Errorf("getConfigDuration = %v, want default 30s", got)
+	}
+	wantWarning(t, `invalid value "soon" for api.timeout, using default 30s`)
+}
+
+func TestGetConfigMissingValues(t *testing.T) {
+	useINI(t, "[window]\nwidth =\nresizable =\n[api]\ntimeout =\n")
+
+	getConfigInt("window", "width", 1200)
+	wantWarning(t, "missing value for window.width, using default 1200")
+
+	loadWarnings = nil
+	getConfigBool("window", "resizable", true)
+	wantWarning(t, "missing value for window.resizable, using default true")
+
+	loadWarnings = nil
+	getConfigDuration("api", "timeout", 30*time.Second)
+	wantWarning(t, "missing value for api.timeout, using default 30s")
+}
+
+func TestGetConfigAbsentAndValidValues(t *testing.T) {
+	useINI(t, "[window]\nwidth = 800\nresizable = false\n[api]\ntimeout = 90\nretry_delay = 500ms\n")
+
+	if got := getConfigInt("window", "width", 1200); got != 800 {
+		t.Errorf("getConfigInt = %d, want 800", got)
+	}
+	if got := getConfigBool("window", "resizable", true); got {
+		t.Error("getConfigBool = true, want false")
+	}
+	if got := getConfigDuration("api", "timeout", 0); got != 90*time.Second {
+		t.Errorf("getConfigDuration(seconds) = %v, want 1m30s", got)
+	}
+	if got := getConfigDuration("api", "retry_delay", 0); got != 500*time.Millisecond {
+		t.Errorf("getConfigDuration(duration) = %v, want 500ms", got)
+	}
+	if got := getConfigInt("window", "height", 600); got != 600 {
+		t.Errorf("getConfigInt(absent) = %d, want default 600", got)
+	}
+	if len(loadWarnings) != 0 {
+		t.Errorf("warnings for valid and absent values: %v", loadWarnings)
+	}
+}
+
+// structErrors validates cfg and returns the "Field:tag" pairs of the
+// errors reported within the given struct, ignoring the other sections
+func structErrors(t *testing.T, cfg *Config, structName string) []string {
+	t.Helper()
+
+	var errs validator.ValidationErrors
+	if err := validate.Struct(cfg); err != nil && !errors.As(err, &errs) {
+		t.Fatalf("validate: %v", err)
+	}
+	var got []string
+	for _, e := range errs {
+		if strings.HasPrefix(e.StructNamespace(), "Config."+structName+".") {
+			got = append(got, e.StructField()+":"+e.Tag())
+		}
+	}
+	slices.Sort(got)
+	return got
+}
+
+// validAuthConfig returns an auth section that passes the field tags
+func validAuthConfig() AuthConfig {
+	return AuthConfig{
+		TokenExpiry:        time.Hour,
+		RefreshThreshold:   5 * time.Minute,
+		MaxLoginAttempts:   5,
+		LockoutDuration:    15 * time.Minute,
+		SessionTimeout:     time.Hour,
+		RememberMeDuration: 24 * time.Hour,
+	}
+}
+
+func TestValidateAuthClockSkewTolerance(t *testing.T) {
+	tests := []struct {
+		skew time.Duration
+		want []string
+	}{
+		{0, nil},
+		{30 * time.Second, nil},
+		{-time.Second, []string{"ClockSkewTolerance:min"}},
+		{5 * time.Minute, []string{"ClockSkewTolerance:ltfield"}},
+	}
+	for _, tt := range tests {
+		cfg := &Config{Auth: validAuthConfig()}
+		cfg.Auth.ClockSkewTolerance = tt.skew
+		if got := structErrors(t, cfg, "Auth"); !slices.Equal(got, tt.want) {
+			t.Errorf("skew %v: auth errors = %v, want %v", tt.skew, got, tt.want)
+		}
+	}
+}
+
+func TestValidateAuthClientCredentials(t *testing.T) {
+	secret := strings.Repeat("s", minClientSecretLength)
+
+	tests := []struct {
+		name         string
+		clientID     string
+		clientSecret string
+		want         []string
+	}{
+		{"neither", "", "", nil},
+		{"both", "client", secret, nil},
+		{"only id", "client", "", []string{"ClientSecret:required_with"}},
+		{"only secret", "", secret, []string{"ClientID:required_with"}},
+		{"short secret", "client", "short", []string{"ClientSecret:min"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{Auth: validAuthConfig()}
+			cfg.Auth.ClientID, cfg.Auth.ClientSecret = tt.clientID, tt.clientSecret
+			if got := structErrors(t, cfg, "Auth"); !slices.Equal(got, tt.want) {
+				t.Errorf("auth errors = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateAuthStrategy(t *testing.T) {
+	tests := []struct {
+		strategy string
+		apiKey   string
+		want     []string
+	}{
+		{"", "", nil},
+		{AuthStrategyPassword, "", nil},
+		{AuthStrategyAPIKey, "key", nil},
+		{AuthStrategyAPIKey, "", []string{"APIKey:required"}},
+		{"oauth", "", []string{"Strategy:oneof"}},
+	}
+	for _, tt := range tests {
+		cfg := &Config{Auth: validAuthConfig()}
+		cfg.Auth.Strategy, cfg.Auth.APIKey = tt.strategy, tt.apiKey
+		if got := structErrors(t, cfg, "Auth"); !slices.Equal(got, tt.want) {
+			t.Errorf("strategy %q: auth errors = %v, want %v", tt.strategy, got, tt.want)
+		}
+	}
+}
+
+func TestValidateTLSMinVersion(t *testing.T) {
+	tests := []struct {
+		env     Environment
+		version string
+		want    []string
+	}{
+		{Development, "", nil},
+		{Development, "1.2", nil},
+		{Development, "1.3", nil},
+		{Development, "1.1", []string{"MinVersion:oneof"}},
+		{Development, "1.0", []string{"MinVersion:oneof"}},
+		{Development, "1.4", []string{"MinVersion:oneof"}},
+		{Production, "1.2", nil},
+		{Production, "1.1", []string{"MinVersion:oneof"}},
+	}
+	for _, tt := range tests {
+		cfg := &Config{App: AppConfig{Environment: tt.env}, TLS: TLSConfig{MinVersion: tt.version}}
+		if got := structErrors(t, cfg, "TLS"); !slices.Equal(got, tt.want) {
+			t.Errorf("%s with TLS %s: errors = %v, want %v", tt.env, tt.version, got, tt.want)
+		}
+	}
+}
+
+func TestSanitizeConfigMasksClientSecret(t *testing.T) {
+	cfg := &Config{Auth: AuthConfig{ClientID: "client", ClientSecret: "top-secret"}}
+
+	sanitized := NewSecurityValidator(cfg).SanitizeConfig()
+	if sanitized.Auth.ClientSecret == "top-secret" {
+		t.Error("client secret not masked")
+	}
+	if sanitized.Auth.ClientID != "client" {
+		t.Errorf("client ID = %q, want it kept", sanitized.Auth.ClientID)
+	}
+	if cfg.Auth.ClientSecret != "top-secret" {
+		t.Error("SanitizeConfig modified the original config")
+	}
+}
+
+func TestNormalizeLogRotation(t *testing.T) {
+	tests := []struct {
+		name     string
+		log      LogConfig
+		warnings []string
+		maxSize  int
+	}{
+		{
+			name:    "sensible",
+			log:     LogConfig{Output: LogOutputFile, MaxSize: 50, MaxBackups: 3, MaxAge: 28, Compress: true},
+			maxSize: 50,
+		},
+		{
+			name:     "no backups with max age",
+			log:      LogConfig{Output: LogOutputFile, MaxSize: 50, MaxAge: 365},
+			warnings: []string{"max_backups is 0, so rotated logs are discarded immediately and max_age = 365 has no effect"},
+			maxSize:  50,
+		},
+		{
+			name: "no backups with compression",
+			log:  LogConfig{Output: LogOutputBoth, MaxSize: 50, MaxAge: 7, Compress: true},
+			warnings: []string{
+				"max_backups is 0, so rotated logs are discarded immediately and max_age = 7 has no effect",
+				"compress is enabled but max_backups is 0, so there are no rotated logs to compress",
+			},
+			maxSize: 50,
+		},
+		{
+			name:     "non-positive max size",
+			log:      LogConfig{Output: LogOutputFile, MaxSize: 0, MaxBackups: 3},
+			warnings: []string{"max_size must be positive when logging to a file, using 100 MB"},
+			maxSize:  100,
+		},
+		{
+			name:    "console only",
+			log:     LogConfig{Output: LogOutputConsole, MaxSize: -1, MaxAge: 365, Compress: true},
+			maxSize: -1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useINI(t, "")
+			logConfig := tt.log
+			normalizeLogRotation(&logConfig)
+
+			var got []string
+			for _, w := range loadWarnings {
+				if w.Section != "log" || w.Severity != SeverityWarning {
+					t.Errorf("warning %+v not reported in the log section", w)
+				}
+				got = append(got, w.Message)
+			}
+			if !slices.Equal(got, tt.warnings) {
+				t.Errorf("warnings = %q, want %q", got, tt.warnings)
+			}
+			if logConfig.MaxSize != tt.maxSize {
+				t.Errorf("MaxSize = %d, want %d", logConfig.MaxSize, tt.maxSize)
+			}
+		})
+	}
+}
+
+func TestGetConfigHeaders(t *testing.T) {
+	useINI(t, "[api]\ndefault_headers = X-Client-Version: 1.2.3, x-api-key: abc:def\n")
+	loadErrors = nil
+	t.Cleanup(func() { loadErrors = nil })
+
+	got := getConfigHeaders("api", "default_headers")
+	want := map[string]string{"X-Client-Version": "1.2.3", "X-Api-Key": "abc:def"}
+	if len(got) != len(want) || got["X-Client-Version"] != want["X-Client-Version"] || got["X-Api-Key"] != want["X-Api-Key"] {
+		t.Errorf("headers = %v, want %v", got, want)
+	}
+	if len(loadErrors) != 0 {
+		t.Errorf("load errors = %v", loadErrors)
+	}
+}
+
+func TestGetConfigHeadersInvalid(t *testing.T) {
+	for _, value := range []string{
+		"X-Missing-Colon",
+		"Bad Name: value",
+		"X-Control: a\x7fb",
+	} {
+		t.Run(value, func(t *testing.T) {
+			cfg := ini.Empty()
+			cfg.Section("api").Key("default_headers").SetValue(value)
+			saved := iniConfig
+			iniConfig, loadErrors = cfg, nil
+			t.Cleanup(func() { iniConfig, loadErrors = saved, nil })
+
+			if headers := getConfigHeaders("api", "default_headers"); len(headers) != 0 {
+				t.Errorf("headers = %v, want the entry rejected", headers)
+			}
+			if len(loadErrors) != 1 {
+				t.Errorf("load errors = %v, want one", loadErrors)
+			}
+		})
+	}
+}
+
+func TestValidateWindowStates(t *testing.T) {
+	tests := []struct {
+		name                             string
+		minimized, maximized, fullscreen bool
+		want                             []string
+	}{
+		{"normal", false, false, false, nil},
+		{"minimized", true, false, false, nil},
+		{"maximized", false, true, false, nil},
+		{"fullscreen", false, false, true, nil},
+		{"fullscreen and minimized", true, false, true, nil},
+		{"minimized and maximized", true, true, false, []string{"Minimized:excluded_with"}},
+		{"fullscreen and maximized", false, true, true, []string{"Fullscreen:excluded_with"}},
+		{"all", true, true, true, []string{"Fullscreen:excluded_with", "Minimized:excluded_with"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{Window: WindowConfig{
+				Width:      1200,
+				Height:     800,
+				Minimized:  tt.minimized,
+				Maximized:  tt.maximized,
+				Fullscreen: tt.fullscreen,
+			}}
+			if got := structErrors(t, cfg, "Window"); !slices.Equal(got, tt.want) {
+				t.Errorf("window errors = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateWindowStatesMessage(t *testing.T) {
+	cfg := &Config{Window: WindowConfig{Width: 1200, Height: 800, Minimized: true, Maximized: true}}
+	err := Validate(cfg)
+	if err == nil || !strings.Contains(err.Error(), "Config.Window.Minimized") || !strings.Contains(err.Error(), "excluded_with") {
+		t.Errorf("error = %v, want it to name Config.Window.Minimized and excluded_with", err)
+	}
+}
+
+func TestValidateAPIProtocols(t *testing.T) {
+	tests := []struct {
+		name            string
+		forceHTTP1, h2c bool
+		want            []string
+	}{
+		{"default", false, false, nil},
+		{"force http1", true, false, nil},
+		{"h2c", false, true, nil},
+		{"both", true, true, []string{"AllowHTTP2Cleartext:excluded_with"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{API: APIConfig{ForceHTTP1: tt.forceHTTP1, AllowHTTP2Cleartext: tt.h2c}}
+			var got []string
+			for _, e := range structErrors(t, cfg, "API") {
+				if strings.HasSuffix(e, ":excluded_with") {
+					got = append(got, e)
+				}
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("protocol errors = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateWindowMonitor(t *testing.T) {
+	cfg := &Config{Window: WindowConfig{Width: 1200, Height: 800, Monitor: -1}}
+	if got := structErrors(t, cfg, "Window"); !slices.Equal(got, []string{"Monitor:min"}) {
+		t.Errorf("window errors = %v, want Monitor:min", got)
+	}
+
+	cfg.Window.Monitor = 3
+	if got := structErrors(t, cfg, "Window"); got != nil {
+		t.Errorf("window errors = %v, want none", got)
+	}
+}
+
+func TestValidateAuthHeader(t *testing.T) {
+	tests := []struct {
+		headerName, scheme string
+		want               []string
+	}{
+		{"", "", nil},
+		{"Authorization", "Bearer", nil},
+		{"X-Auth-Token", "Token", nil},
+		{"X Auth", "Bearer", []string{"HeaderName:http_token"}},
+		{"Authorization:", "Bearer", []string{"HeaderName:http_token"}},
+		{"Authorization", "Bearer token", []string{"Scheme:http_token"}},
+	}
+	for _, tt := range tests {
+		cfg := &Config{Auth: validAuthConfig()}
+		cfg.Auth.HeaderName, cfg.Auth.Scheme = tt.headerName, tt.scheme
+		if got := structErrors(t, cfg, "Auth"); !slices.Equal(got, tt.want) {
+			t.Errorf("header %q, scheme %q: auth errors = %v, want %v", tt.headerName, tt.scheme, got, tt.want)
+		}
+	}
+}
+
+func TestValidatePageSizes(t *testing.T) {
+	tests := []struct {
+		defaultSize, maxSize int
+		want                 []string
+	}{
+		{20, 100, nil},
+		{100, 100, nil},
+		{200, 100, []string{"DefaultPageSize:ltefield"}},
+		{0, 100, []string{"DefaultPageSize:min"}},
+		{-1, 0, []string{"DefaultPageSize:min", "MaxPageSize:min"}},
+	}
+	for _, tt := range tests {
+		cfg := &Config{API: APIConfig{DefaultPageSize: tt.defaultSize, MaxPageSize: tt.maxSize}}
+		var got []string
+		for _, e := range structErrors(t, cfg, "API") {
+			if strings.Contains(e, "PageSize") {
+				got = append(got, e)
+			}
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("default %d, max %d: errors = %v, want %v", tt.defaultSize, tt.maxSize, got, tt.want)
+		}
+	}
+}
+
+func TestValidateJitterStrategy(t *testing.T) {
+	for strategy, valid := range map[JitterStrategy]bool{
+		"":          true,
+		JitterNone:  true,
+		JitterFull:  true,
+		JitterEqual: true,
+		"random":    false,
+	} {
+		cfg := &Config{API: APIConfig{JitterStrategy: strategy}}
+		got := slices.Contains(structErrors(t, cfg, "API"), "JitterStrategy:oneof")
+		if got == valid {
+			t.Errorf("jitter strategy %q: rejected = %v, want %v", strategy, got, !valid)
+		}
+	}
+}
+
+func TestValidateHealthCheck(t *testing.T) {
+	tests := []struct {
+		name   string
+		path   string
+		status []int
+		want   []string
+	}{
+		{"default", "", []int{200}, nil},
+		{"relative path", "/healthz", []int{200, 204}, nil},
+		{"path without slash", "status", []int{204}, nil},
+		{"absolute URL", "https://other.example.com/health", []int{200}, []string{"HealthPath:relative_path"}},
+		{"scheme-relative URL", "//other.example.com/health", []int{200}, []string{"HealthPath:relative_path"}},
+		{"no status", "/healthz", nil, []string{"HealthExpectedStatus:required"}},
+		{"invalid status", "/healthz", []int{200, 99, 600}, []string{"HealthExpectedStatus[1]:min", "HealthExpectedStatus[2]:max"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{API: APIConfig{HealthPath: tt.path, HealthExpectedStatus: tt.status}}
+			var got []string
+			for _, e := range structErrors(t, cfg, "API") {
+				if strings.HasPrefix(e, "Health") {
+					got = append(got, e)
+				}
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("health check errors = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetConfigIntList(t *testing.T) {
+	useINI(t, "[api]\nhealth_expected_status = 200, 204\ninvalid = 200, ok\n")
+	loadErrors = nil
+	t.Cleanup(func() { loadErrors = nil })
+
+	if got := getConfigIntList("api", "health_expected_status", []int{200}); !slices.Equal(got, []int{200, 204}) {
+		t.Errorf("list = %v, want [200 204]", got)
+	}
+	if got := getConfigIntList("api", "missing", []int{200}); !slices.Equal(got, []int{200}) {
+		t.Errorf("missing list = %v, want the default [200]", got)
+	}
+	if len(loadErrors) != 0 {
+		t.Errorf("load errors = %v, want none", loadErrors)
+	}
+
+	getConfigIntList("api", "invalid", []int{200})
+	if len(loadErrors) != 1 || !strings.Contains(loadErrors[0].Error(), `"ok"`) {
+		t.Errorf("load errors = %v, want one for \"ok\"", loadErrors)
+	}
+}
+
+func TestValidateRateLimitGrace(t *testing.T) {
+	tests := []struct {
+		grace, burst int
+		want         []string
+	}{
+		{0, 200, nil},
+		{50, 200, nil},
+		{200, 200, nil},
+		{201, 200, []string{"RateLimitGrace:ltefield"}},
+		{-1, 200, []string{"RateLimitGrace:min"}},
+	}
+	for _, tt := range tests {
+		cfg := &Config{Security: SecurityConfig{RateLimitRPS: 100, RateLimitBurst: tt.burst, RateLimitGrace: tt.grace}}
+		if got := structErrors(t, cfg, "Security"); !slices.Equal(got, tt.want) {
+			t.Errorf("grace %d, burst %d: errors = %v, want %v", tt.grace, tt.burst, got, tt.want)
+		}
+	}
+}
+
+func TestLoadRedactPatterns(t *testing.T) {
+	useINI(t, "[log]\nredact_patterns = [a-z]+@example\\.com  tok_[0-9a-f]{8,}\n")
+	cfg := &Config{Log: loadLogConfig()}
+	if want := []string{`[a-z]+@example\.com`, `tok_[0-9a-f]{8,}`}; !slices.Equal(cfg.Log.RedactPatterns, want) {
+		t.Errorf("redact patterns = %q, want %q", cfg.Log.RedactPatterns, want)
+	}
+	if got := structErrors(t, cfg, "Log"); slices.ContainsFunc(got, func(e string) bool { return strings.HasPrefix(e, "RedactPatterns") }) {
+		t.Errorf("valid patterns rejected: %v", got)
+	}
+
+	cfg.Log.RedactPatterns = []string{"(unclosed"}
+	if got := structErrors(t, cfg, "Log"); !slices.Contains(got, "RedactPatterns[0]:regexp") {
+		t.Errorf("log errors = %v, want the invalid pattern rejected", got)
+	}
+}
+
+func TestValidateRecordReplay(t *testing.T) {
+	tests := []struct {
+		env                  Environment
+		recordTo, replayFrom string
+		want                 string
+	}{
+		{Development, "session.jsonl", "", ""},
+		{Development, "", "session.jsonl", ""},
+		{Development, "new.jsonl", "session.jsonl", "ReplayFrom:excluded_with"},
+		{Production, "session.jsonl", "", ""},
+		{Production, "", "session.jsonl", "API.ReplayFrom:production_disabled"},
+	}
+	for _, tt := range tests {
+		cfg := &Config{App: AppConfig{Environment: tt.env}, API: APIConfig{RecordTo: tt.recordTo, ReplayFrom: tt.replayFrom}}
+		got := structErrors(t, cfg, "API")
+		for _, e := range got {
+			if strings.Contains(e, "ReplayFrom") && e != tt.want {
+				t.Errorf("%s, record %q, replay %q: unexpected error %s", tt.env, tt.recordTo, tt.replayFrom, e)
+			}
+		}
+		if tt.want != "" && !slices.Contains(got, tt.want) {
+			t.Errorf("%s, record %q, replay %q: errors = %v, want %s", tt.env, tt.recordTo, tt.replayFrom, got, tt.want)
+		}
+	}
+}
+
+func TestValidateBaseURLs(t *testing.T) {
+	tests := []struct {
+		baseURLs []string
+		want     []string
+	}{
+		{nil, nil},
+		{[]string{"https://a.example.com/api", "https://b.example.com/api"}, nil},
+		{[]string{"https://a.example.com/api;weight=3", "https://b.example.com/api"}, nil},
+		{[]string{"https://a.example.com/api", "not a url"}, []string{"BaseURLs[1]:endpoint"}},
+		{[]string{"https://a.example.com/api;weight=0"}, []string{"BaseURLs[0]:endpoint"}},
+	}
+	for _, tt := range tests {
+		cfg := &Config{API: APIConfig{BaseURL: "https://api.example.com", BaseURLs: tt.baseURLs}}
+		var got []string
+		for _, e := range structErrors(t, cfg, "API") {
+			if strings.HasPrefix(e, "BaseURL") {
+				got = append(got, e)
+			}
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("base_urls %q: errors = %v, want %v", tt.baseURLs, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,25 @@
+package config
+
+import "strings"
+
+// Outputs returns the set of outputs listed in o, expanding "both" to
+// console and file. Unknown entries are kept for validation to reject.
+func (o LogOutput) Outputs() map[LogOutput]bool {
+	outputs := make(map[LogOutput]bool)
+	for _, entry := range strings.Split(string(o), ",") {
+		switch entry := LogOutput(strings.ToLower(strings.TrimSpace(entry))); entry {
+		case "":
+		case LogOutputBoth:
+			outputs[LogOutputConsole] = true
+			outputs[LogOutputFile] = true
+		default:
+			outputs[entry] = true
+		}
+	}
+	return outputs
+}
+
+// Has reports whether logs are written to output
+func (o LogOutput) Has(output LogOutput) bool {
+	return o.Outputs()[output]
+}
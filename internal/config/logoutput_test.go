@@ -0,0 +1,52 @@
+package config
+
+import (
+	"maps"
+	"slices"
+	"testing"
+)
+
+func TestLogOutputs(t *testing.T) {
+	tests := []struct {
+		output LogOutput
+		want   []LogOutput
+	}{
+		{"console", []LogOutput{LogOutputConsole}},
+		{"file", []LogOutput{LogOutputFile}},
+		{"console,file", []LogOutput{LogOutputConsole, LogOutputFile}},
+		{" File , console ", []LogOutput{LogOutputConsole, LogOutputFile}},
+		{"both", []LogOutput{LogOutputConsole, LogOutputFile}},
+		{"both,file", []LogOutput{LogOutputConsole, LogOutputFile}},
+		{"", nil},
+	}
+	for _, tt := range tests {
+		got := slices.Sorted(maps.Keys(tt.output.Outputs()))
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("LogOutput(%q).Outputs() = %v, want %v", tt.output, got, tt.want)
+		}
+	}
+
+	if LogOutput("console").Has(LogOutputFile) || !LogOutput("both").Has(LogOutputFile) {
+		t.Error("Has doesn't match Outputs")
+	}
+}
+
+func TestValidateLogOutputs(t *testing.T) {
+	tests := []struct {
+		output LogOutput
+		want   []string
+	}{
+		{"console", nil},
+		{"console,file", nil},
+		{"both", nil},
+		{"console,syslog", []string{"Output:log_outputs"}},
+		{",", []string{"Output:log_outputs"}},
+		{"", []string{"Output:required"}},
+	}
+	for _, tt := range tests {
+		cfg := &Config{Log: LogConfig{Level: LogLevelInfo, Format: LogFormatJSON, Output: tt.output, MaxSize: 1, MaxAge: 1}}
+		if got := structErrors(t, cfg, "Log"); !slices.Equal(got, tt.want) {
+			t.Errorf("output %q: log errors = %v, want %v", tt.output, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrUnknownConfigPath is returned for paths that don't match a config value
+	ErrUnknownConfigPath = errors.New("unknown configuration path")
+	// ErrSensitiveConfigPath is returned for paths that reference a secret
+	ErrSensitiveConfigPath = errors.New("configuration value is sensitive")
+)
+
+// LookupValue resolves a dotted path such as "api.timeout" or
+// "window.width" against the configuration. Path segments match the JSON
+// field names, ignoring case and underscores, so INI-style names like
+// "log.file_path" work too. Durations are returned as seconds and secrets
+// are denied.
+func LookupValue(config *Config, path string) (any, error) {
+	value := reflect.ValueOf(config).Elem()
+	var resolved []string
+
+	for _, segment := range strings.Split(path, ".") {
+		if value.Kind() != reflect.Struct {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownConfigPath, path)
+		}
+
+		field, name, ok := findField(value, segment)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownConfigPath, path)
+		}
+		value = field
+		resolved = append(resolved, name)
+	}
+
+	for _, sensitive := range sensitivePaths {
+		if strings.Join(resolved, ".") == sensitive {
+			return nil, fmt.Errorf("%w: %s", ErrSensitiveConfigPath, path)
+		}
+	}
+	if value.Kind() == reflect.Struct {
+		return nil, fmt.Errorf("%w: %s is a section, not a value", ErrUnknownConfigPath, path)
+	}
+
+	if duration, ok := value.Interface().(time.Duration); ok {
+		return duration.Seconds(), nil
+	}
+	return value.Interface(), nil
+}
+
+// findField finds the struct field whose JSON name matches segment,
+// returning the field value and its JSON name
+func findField(value reflect.Value, segment string) (reflect.Value, string, bool) {
+	want := normalizeKey(segment)
+	for i := 0; i < value.NumField(); i++ {
+		name := jsonName(value.Type().Field(i))
+		if normalizeKey(name) == want {
+			return value.Field(i), name, true
+		}
+	}
+	return reflect.Value{}, "", false
+}
+
+// jsonName returns the JSON name of a struct field
+func jsonName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" {
+		return field.Name
+	}
+	return name
+}
+
+func normalizeKey(key string) string {
+	return strings.ToLower(strings.ReplaceAll(key, "_", ""))
+}
@@ -0,0 +1,82 @@
+package config
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// lookupTestConfig returns a config with a few distinct values set
+func lookupTestConfig() *Config {
+	return &Config{
+		App:      AppConfig{Environment: Staging, Debug: true},
+		API:      APIConfig{BaseURL: "https://api.example.com", Timeout: 30 * time.Second, DefaultHeaders: map[string]string{"X-Api-Key": "key"}},
+		Log:      LogConfig{FilePath: "logs/app.log"},
+		Window:   WindowConfig{Width: 1200},
+		Database: DatabaseConfig{Password: "db-secret"},
+		Security: SecurityConfig{CSRFSecret: "csrf-secret"},
+		Auth:     AuthConfig{ClientSecret: "client-secret"},
+	}
+}
+
+func TestLookupValue(t *testing.T) {
+	cfg := lookupTestConfig()
+
+	tests := []struct {
+		path string
+		want any
+	}{
+		{"api.timeout", 30.0},
+		{"api.baseUrl", "https://api.example.com"},
+		{"api.base_url", "https://api.example.com"},
+		{"window.width", 1200},
+		{"WINDOW.WIDTH", 1200},
+		{"log.file_path", "logs/app.log"},
+		{"app.debug", true},
+		{"app.environment", Staging},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, err := LookupValue(cfg, tt.path)
+			if err != nil {
+				t.Fatalf("LookupValue: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("LookupValue(%q) = %#v, want %#v", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLookupValueSensitive(t *testing.T) {
+	cfg := lookupTestConfig()
+	for _, path := range []string{
+		"database.password",
+		"security.csrf_secret",
+		"auth.client_secret",
+		"auth.api_key",
+		"auth.client_secret",
+		"api.signing_secret",
+		"api.default_headers",
+	} {
+		if got, err := LookupValue(cfg, path); !errors.Is(err, ErrSensitiveConfigPath) {
+			t.Errorf("LookupValue(%q) = %v, %v, want ErrSensitiveConfigPath", path, got, err)
+		}
+	}
+}
+
+func TestLookupValueUnknown(t *testing.T) {
+	cfg := lookupTestConfig()
+	for _, path := range []string{
+		"",
+		"api",
+		"api.missing",
+		"missing.timeout",
+		"api.timeout.seconds",
+		"window..width",
+	} {
+		if got, err := LookupValue(cfg, path); !errors.Is(err, ErrUnknownConfigPath) {
+			t.Errorf("LookupValue(%q) = %v, %v, want ErrUnknownConfigPath", path, got, err)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// messageFunc formats the message for a failed validation tag. label names
+// the field, e.g. "Window width".
+type messageFunc func(fe validator.FieldError, label string) string
+
+// validationMessages maps validation tags to friendly messages. Tags of
+// custom validators are registered in init next to the validator.
+var validationMessages = map[string]messageFunc{
+	"required": func(fe validator.FieldError, label string) string {
+		return label + " is required"
+	},
+	"min": func(fe validator.FieldError, label string) string {
+		return fmt.Sprintf("%s must be at least %s", label, withUnit(fe))
+	},
+	"max": func(fe validator.FieldError, label string) string {
+		return fmt.Sprintf("%s must be at most %s", label, withUnit(fe))
+	},
+	"oneof": func(fe validator.FieldError, label string) string {
+		return fmt.Sprintf("%s must be one of %s", label, strings.Join(strings.Fields(fe.Param()), ", "))
+	},
+	"url": func(fe validator.FieldError, label string) string {
+		return label + " must be a valid URL"
+	},
+	"contains": func(fe validator.FieldError, label string) string {
+		return fmt.Sprintf("%s must contain %s", label, fe.Param())
+	},
+	"ltfield": func(fe validator.FieldError, label string) string {
+		return fmt.Sprintf("%s must be less than %s", label, siblingLabel(fe))
+	},
+	"ltefield": func(fe validator.FieldError, label string) string {
+		return fmt.Sprintf("%s must be at most %s", label, siblingLabel(fe))
+	},
+	"nefield": func(fe validator.FieldError, label string) string {
+		return fmt.Sprintf("%s must differ from %s", label, siblingLabel(fe))
+	},
+	"required_with": func(fe validator.FieldError, label string) string {
+		return fmt.Sprintf("%s is required when %s is set", label, siblingLabel(fe))
+	},
+	"excluded_with": func(fe validator.FieldError, label string) string {
+		return fmt.Sprintf("%s can't be combined with %s", label, siblingLabel(fe))
+	},
+}
+
+// ValidationError is returned when the configuration fails validation. It
+// carries a friendly message for every invalid field.
+type ValidationError struct {
+	Messages []string
+	err      error
+}
+
+func (e *ValidationError) Error() string {
+	return "configuration validation failed: " + strings.Join(e.Messages, "; ")
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.err
+}
+
+// newValidationError wraps an error returned by validate.Struct, turning
+// each field error into a friendly message
+func newValidationError(err error) error {
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return fmt.Errorf("configuration validation failed: %w", err)
+	}
+
+	messages := make([]string, 0, len(validationErrors))
+	for _, fe := range validationErrors {
+		messages = append(messages, ValidationMessage(fe))
+	}
+	return &ValidationError{Messages: messages, err: err}
+}
+
+// ValidationMessage returns a message for a failed validation that is fit
+// for end users, such as "Window width must be at least 400"
+func ValidationMessage(fe validator.FieldError) string {
+	label := fieldLabel(fe.StructNamespace())
+	if format, ok := validationMessages[fe.Tag()]; ok {
+		return format(fe, label)
+	}
+
+	tag := fe.Tag()
+	if fe.Param() != "" {
+		tag += "=" + fe.Param()
+	}
+	return fmt.Sprintf("%s failed the %q validation", label, tag)
+}
+
+// withUnit returns the parameter of a min or max tag with the unit implied
+// by the field type
+func withUnit(fe validator.FieldError) string {
+	switch fe.Kind() {
+	case reflect.String:
+		return fe.Param() + " characters"
+	case reflect.Slice, reflect.Map:
+		return fe.Param() + " items"
+	}
+	return fe.Param()
+}
+
+// siblingLabel returns the label of the field named by the tag parameter,
+// which is in the same struct as the failed field
+func siblingLabel(fe validator.FieldError) string {
+	namespace := strings.TrimSuffix(fe.StructNamespace(), fe.StructField())
+	return fieldLabel(namespace + fe.Param())
+}
+
+// fieldLabel turns a validator namespace such as "Config.Window.Width" or
+// "WindowConfig.Width" into a readable label like "Window width"
+func fieldLabel(namespace string) string {
+	parts := strings.Split(namespace, ".")
+	if len(parts) > 2 && parts[0] == "Config" {
+		parts = parts[1:]
+	}
+	parts[0] = strings.TrimSuffix(parts[0], "Config")
+
+	words := []string{parts[0]}
+	for _, part := range parts[1:] {
+		for _, word := range splitWords(part) {
+			// Keep acronyms such as URL or ID
+			if len(word) == 1 || strings.ToUpper(word) != word {
+				word = strings.ToLower(word)
+			}
+			words = append(words, word)
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// splitWords splits a Go identifier such as "BaseURL" into its words,
+// keeping acronyms together: "Base", "URL"
+func splitWords(name string) []string {
+	runes := []rune(name)
+	var words []string
+	start := 0
+	for i := 1; i < len(runes); i++ {
+		upper := unicode.IsUpper(runes[i])
+		prevLower := unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1])
+		nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+		// A word starts at an upper case letter after a lower case one, or
+		// at the last letter of an acronym that is followed by lower case
+		if upper && (prevLower || (unicode.IsUpper(runes[i-1]) && nextLower)) {
+			words = append(words, string(runes[start:i]))
+			start = i
+		}
+	}
+	return append(words, string(runes[start:]))
+}
@@ -0,0 +1,171 @@
+package config
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// validationMessage validates cfg and returns the message for the field
+// with the given namespace
+func validationMessage(t *testing.T, cfg *Config, namespace string) string {
+	t.Helper()
+
+	var errs validator.ValidationErrors
+	if !errors.As(validate.Struct(cfg), &errs) {
+		t.Fatal("config passed validation")
+	}
+	for _, fe := range errs {
+		if fe.StructNamespace() == namespace {
+			return ValidationMessage(fe)
+		}
+	}
+	t.Fatalf("no validation error for %s", namespace)
+	return ""
+}
+
+func TestValidationMessage(t *testing.T) {
+	tests := []struct {
+		name      string
+		setup     func(cfg *Config)
+		namespace string
+		want      string
+	}{
+		{
+			name:      "required",
+			setup:     func(cfg *Config) { cfg.App.Name = "" },
+			namespace: "Config.App.Name",
+			want:      "App name is required",
+		},
+		{
+			name:      "min",
+			setup:     func(cfg *Config) { cfg.Window.Width = 100 },
+			namespace: "Config.Window.Width",
+			want:      "Window width must be at least 400",
+		},
+		{
+			name:      "max",
+			setup:     func(cfg *Config) { cfg.Window.Height = 5000 },
+			namespace: "Config.Window.Height",
+			want:      "Window height must be at most 3000",
+		},
+		{
+			name:      "max length",
+			setup:     func(cfg *Config) { cfg.App.Name = strings.Repeat("a", 101) },
+			namespace: "Config.App.Name",
+			want:      "App name must be at most 100 characters",
+		},
+		{
+			name:      "min duration",
+			setup:     func(cfg *Config) { cfg.Auth.TokenExpiry = time.Minute },
+			namespace: "Config.Auth.TokenExpiry",
+			want:      "Auth token expiry must be at least 300s",
+		},
+		{
+			name:      "oneof",
+			setup:     func(cfg *Config) { cfg.Log.Level = "verbose" },
+			namespace: "Config.Log.Level",
+			want:      "Log level must be one of debug, info, warn, error",
+		},
+		{
+			name:      "log outputs",
+			setup:     func(cfg *Config) { cfg.Log.Output = "console,syslog" },
+			namespace: "Config.Log.Output",
+			want:      "Log output must be a comma-separated list of console, file or both",
+		},
+		{
+			name:      "url",
+			setup:     func(cfg *Config) { cfg.API.BaseURL = "not a url" },
+			namespace: "Config.API.BaseURL",
+			want:      "API base URL must be a valid URL",
+		},
+		{
+			name:      "semver",
+			setup:     func(cfg *Config) { cfg.App.Version = "1.x" },
+			namespace: "Config.App.Version",
+			want:      "App version must be a semantic version such as 1.2.3",
+		},
+		{
+			name:      "ltfield",
+			setup:     func(cfg *Config) { cfg.Auth.ClockSkewTolerance = time.Hour },
+			namespace: "Config.Auth.ClockSkewTolerance",
+			want:      "Auth clock skew tolerance must be less than Auth refresh threshold",
+		},
+		{
+			name:      "ltefield",
+			setup:     func(cfg *Config) { cfg.API.DefaultPageSize, cfg.API.MaxPageSize = 200, 100 },
+			namespace: "Config.API.DefaultPageSize",
+			want:      "API default page size must be at most API max page size",
+		},
+		{
+			name:      "nefield",
+			setup:     func(cfg *Config) { cfg.Log.FilePath, cfg.Log.AuditFile = "logs/app.log", "logs/app.log" },
+			namespace: "Config.Log.AuditFile",
+			want:      "Log audit file must differ from Log file path",
+		},
+		{
+			name:      "required_with",
+			setup:     func(cfg *Config) { cfg.Auth.ClientID, cfg.Auth.ClientSecret = "client", "" },
+			namespace: "Config.Auth.ClientSecret",
+			want:      "Auth client secret is required when Auth client ID is set",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{Auth: validAuthConfig()}
+			tt.setup(cfg)
+			if got := validationMessage(t, cfg, tt.namespace); got != tt.want {
+				t.Errorf("message = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewValidationError(t *testing.T) {
+	cfg := *loadRepoConfig(t)
+	cfg.Window.Width = 100
+	cfg.App.Version = "1.x"
+
+	err := newValidationError(validate.Struct(&cfg))
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("error = %v, want a *ValidationError", err)
+	}
+	for _, want := range []string{
+		"Window width must be at least 400",
+		"App version must be a semantic version such as 1.2.3",
+	} {
+		if !slices.Contains(validationErr.Messages, want) {
+			t.Errorf("Messages = %q, missing %q", validationErr.Messages, want)
+		}
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Error() = %q, missing %q", err.Error(), want)
+		}
+	}
+
+	// The validator errors stay available to callers
+	var fieldErrs validator.ValidationErrors
+	if !errors.As(err, &fieldErrs) {
+		t.Error("ValidationError does not unwrap to validator.ValidationErrors")
+	}
+}
+
+func TestFieldLabel(t *testing.T) {
+	tests := map[string]string{
+		"Config.Window.Width":            "Window width",
+		"WindowConfig.Width":             "Window width",
+		"Config.API.MaxIdleConn":         "API max idle conn",
+		"Config.TLS.CACertPath":          "TLS CA cert path",
+		"Config.API.AllowHTTP2Cleartext": "API allow HTTP2 cleartext",
+		"Config.Auth.ClientID":           "Auth client ID",
+	}
+	for namespace, want := range tests {
+		if got := fieldLabel(namespace); got != want {
+			t.Errorf("fieldLabel(%q) = %q, want %q", namespace, got, want)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// profileSectionPrefix starts the sections holding the overrides of a
+// named profile, such as [profile.local]
+const profileSectionPrefix = "profile."
+
+var (
+	// ErrUnknownProfile is returned when switching to a profile that has no
+	// section in the config file
+	ErrUnknownProfile = errors.New("unknown configuration profile")
+	// ErrProfilesDisabled is returned when switching profiles in production
+	ErrProfilesDisabled = errors.New("configuration profiles are disabled in production")
+)
+
+// activeProfile is the profile applied by LoadConfig, empty for the base
+// configuration
+var activeProfile string
+
+// ActiveProfile returns the name of the profile in effect, or an empty
+// string for the base configuration
+func ActiveProfile() string {
+	stateMu.Lock()
+	defer stateMu.Unlock()
+	return activeProfile
+}
+
+// Profiles returns the names of the profiles defined in the loaded config
+// file, in file order
+func Profiles() []string {
+	stateMu.Lock()
+	defer stateMu.Unlock()
+
+	if iniConfig == nil {
+		return nil
+	}
+	var names []string
+	for _, sec := range iniConfig.Sections() {
+		if name, ok := strings.CutPrefix(sec.Name(), profileSectionPrefix); ok && name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// SwitchProfile reloads the configuration with the overrides of the named
+// profile applied, or without any for an empty name. When the result fails
+// to load, the previous configuration and profile stay in effect.
+// Profiles can't be switched in production.
+func SwitchProfile(name string) (*Config, error) {
+	stateMu.Lock()
+	defer stateMu.Unlock()
+
+	if instance != nil && instance.App.Environment.IsProduction() {
+		return nil, ErrProfilesDisabled
+	}
+
+	previous := activeProfile
+	activeProfile = name
+	config, err := reloadConfig()
+	if err != nil {
+		activeProfile = previous
+		return nil, err
+	}
+	return config, nil
+}
+
+// applyProfile sets the values of the [profile.<name>] section on the
+// sections they override. Profile keys name the value they replace as
+// section.key, e.g. "api.base_url = http://localhost:8080".
+func applyProfile(name string) error {
+	if name == "" {
+		return nil
+	}
+	profile, err := iniConfig.GetSection(profileSectionPrefix + name)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrUnknownProfile, name)
+	}
+
+	for _, key := range profile.Keys() {
+		section, sectionKey, ok := strings.Cut(key.Name(), ".")
+		if !ok || section == "" || sectionKey == "" {
+			return fmt.Errorf("profile %s: key %q must be written as section.key", name, key.Name())
+		}
+		iniConfig.Section(section).Key(sectionKey).SetValue(key.Value())
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package config
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+const profileINI = `
+[api]
+base_url = https://api.example.com
+timeout = 30
+
+[profile.local]
+api.base_url = http://localhost:8080
+window.width = 900
+
+[profile.staging]
+api.base_url = https://staging.example.com
+
+[profile.broken]
+base_url = http://localhost:8080
+`
+
+func TestProfiles(t *testing.T) {
+	useINI(t, profileINI)
+
+	if got, want := Profiles(), []string{"local", "staging", "broken"}; !slices.Equal(got, want) {
+		t.Errorf("Profiles = %q, want %q", got, want)
+	}
+}
+
+func TestApplyProfile(t *testing.T) {
+	useINI(t, profileINI)
+
+	if err := applyProfile("local"); err != nil {
+		t.Fatalf("applyProfile: %v", err)
+	}
+	if got := iniConfig.Section("api").Key("base_url").String(); got != "http://localhost:8080" {
+		t.Errorf("api.base_url = %q, want the profile value", got)
+	}
+	if got := iniConfig.Section("window").Key("width").String(); got != "900" {
+		t.Errorf("window.width = %q, want the profile value", got)
+	}
+	if got := iniConfig.Section("api").Key("timeout").String(); got != "30" {
+		t.Errorf("api.timeout = %q, want the base value", got)
+	}
+}
+
+func TestApplyProfileErrors(t *testing.T) {
+	useINI(t, profileINI)
+
+	if err := applyProfile("missing"); !errors.Is(err, ErrUnknownProfile) {
+		t.Errorf("unknown profile error = %v, want ErrUnknownProfile", err)
+	}
+	if err := applyProfile("broken"); err == nil {
+		t.Error("profile key without a section was accepted")
+	}
+}
+
+func TestSwitchProfileDisabledInProduction(t *testing.T) {
+	saved, savedProfile := instance, activeProfile
+	t.Cleanup(func() { instance, activeProfile = saved, savedProfile })
+	instance = &Config{App: AppConfig{Environment: Production}}
+
+	if _, err := SwitchProfile("local"); !errors.Is(err, ErrProfilesDisabled) {
+		t.Errorf("error = %v, want ErrProfilesDisabled", err)
+	}
+	if activeProfile != savedProfile {
+		t.Errorf("active profile = %q, want it unchanged", activeProfile)
+	}
+}
@@ -0,0 +1,93 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// ConfigReportFormat represents how configuration diagnostics are printed
+type ConfigReportFormat string
+
+const (
+	ReportFormatText ConfigReportFormat = "text"
+	ReportFormatJSON ConfigReportFormat = "json"
+)
+
+// Severity represents the severity of a configuration diagnostic
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// ReportEntry is a single configuration diagnostic
+type ReportEntry struct {
+	ID       string   `json:"id,omitempty"` // stable identifier of the check, if any
+	Severity Severity `json:"severity"`
+	Section  string   `json:"section"`
+	Message  string   `json:"message"`
+}
+
+// GetReportFormat returns the report format selected by CONFIG_REPORT_FORMAT,
+// defaulting to text
+func GetReportFormat() ConfigReportFormat {
+	if ConfigReportFormat(strings.ToLower(os.Getenv("CONFIG_REPORT_FORMAT"))) == ReportFormatJSON {
+		return ReportFormatJSON
+	}
+	return ReportFormatText
+}
+
+// newReportEntries wraps plain messages into report entries
+func newReportEntries(severity Severity, section string, messages []string) []ReportEntry {
+	entries := make([]ReportEntry, 0, len(messages))
+	for _, message := range messages {
+		entries = append(entries, ReportEntry{Severity: severity, Section: section, Message: message})
+	}
+	return entries
+}
+
+// reportMessages returns the messages of the entries
+func reportMessages(entries []ReportEntry) []string {
+	messages := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		messages = append(messages, entry.Message)
+	}
+	return messages
+}
+
+// WriteReport writes the diagnostics to w in the given format. Nothing is
+// written when there are no entries.
+func WriteReport(w io.Writer, format ConfigReportFormat, entries []ReportEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	if format == ReportFormatJSON {
+		return json.NewEncoder(w).Encode(entries)
+	}
+
+	for _, entry := range entries {
+		if _, err := fmt.Fprintf(w, "%s %s: %s\n", capitalize(entry.Section), capitalize(string(entry.Severity)), entry.Message); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// printReport writes the diagnostics to stdout in the configured format
+func printReport(entries []ReportEntry) {
+	if err := WriteReport(os.Stdout, GetReportFormat(), entries); err != nil {
+		fmt.Printf("Failed to print configuration report: %v\n", err)
+	}
+}
+
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
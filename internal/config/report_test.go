@@ -0,0 +1,69 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+var testReportEntries = []ReportEntry{
+	{Severity: SeverityError, Section: "environment", Message: "Production must use HTTPS API URLs"},
+	{Severity: SeverityWarning, Section: "security", Message: "CSRF protection is disabled"},
+}
+
+func TestWriteReportText(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteReport(&buf, ReportFormatText, testReportEntries); err != nil {
+		t.Fatalf("WriteReport: %v", err)
+	}
+
+	want := "Environment Error: Production must use HTTPS API URLs\n" +
+		"Security Warning: CSRF protection is disabled\n"
+	if buf.String() != want {
+		t.Errorf("text report = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestWriteReportJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteReport(&buf, ReportFormatJSON, testReportEntries); err != nil {
+		t.Fatalf("WriteReport: %v", err)
+	}
+
+	var got []ReportEntry
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("report is not valid JSON: %v\n%s", err, buf.String())
+	}
+	if !reflect.DeepEqual(got, testReportEntries) {
+		t.Errorf("JSON report = %+v, want %+v", got, testReportEntries)
+	}
+}
+
+func TestWriteReportEmpty(t *testing.T) {
+	for _, format := range []ConfigReportFormat{ReportFormatText, ReportFormatJSON} {
+		var buf bytes.Buffer
+		if err := WriteReport(&buf, format, nil); err != nil {
+			t.Fatalf("WriteReport(%s): %v", format, err)
+		}
+		if buf.Len() != 0 {
+			t.Errorf("WriteReport(%s) wrote %q for no entries", format, buf.String())
+		}
+	}
+}
+
+func TestGetReportFormat(t *testing.T) {
+	tests := map[string]ConfigReportFormat{
+		"":     ReportFormatText,
+		"text": ReportFormatText,
+		"json": ReportFormatJSON,
+		"JSON": ReportFormatJSON,
+		"yaml": ReportFormatText,
+	}
+	for value, want := range tests {
+		t.Setenv("CONFIG_REPORT_FORMAT", value)
+		if got := GetReportFormat(); got != want {
+			t.Errorf("GetReportFormat() with %q = %q, want %q", value, got, want)
+		}
+	}
+}
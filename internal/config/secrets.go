@@ -0,0 +1,98 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"slices"
+
+	"gopkg.in/ini.v1"
+)
+
+// secretKeys lists the section/key pairs that may be provided by the
+// secrets file
+var secretKeys = map[string][]string{
+	"api":      {"signing_secret"},
+	"auth":     {"client_secret", "api_key"},
+	"database": {"password"},
+	"security": {"csrf_secret"},
+}
+
+// mergeSecrets loads the file referenced by [secrets] file and merges its
+// sensitive keys over the main configuration, except for keys overridden by
+// environment variables. Other keys in the secrets file are ignored with a
+// warning.
+func mergeSecrets(env Environment, overridden map[string]bool) error {
+	path := getConfigValue("secrets", "file", "")
+	if path == "" {
+		return nil
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("failed to read secrets file %s: %w", path, err)
+	}
+
+	// File permissions aren't meaningful on Windows
+	if runtime.GOOS != "windows" && info.Mode().Perm()&0o004 != 0 {
+		if env.IsProduction() {
+			return fmt.Errorf("secrets file %s must not be world-readable", path)
+		}
+		loadWarnings = append(loadWarnings, ReportEntry{
+			Severity: SeverityWarning,
+			Section:  "secrets",
+			Message:  fmt.Sprintf("secrets file %s is world-readable, restrict it with chmod 600", path),
+		})
+	}
+
+	secrets, err := ini.Load(path)
+	if err != nil {
+		return fmt.Errorf("failed to load secrets file %s: %w", path, err)
+	}
+
+	for _, sec := range secrets.Sections() {
+		allowed := secretKeys[sec.Name()]
+		for _, key := range sec.Keys() {
+			if !slices.Contains(allowed, key.Name()) {
+				loadWarnings = append(loadWarnings, ReportEntry{
+					Severity: SeverityWarning,
+					Section:  "secrets",
+					Message:  fmt.Sprintf("ignoring non-sensitive key %s.%s in secrets file", sec.Name(), key.Name()),
+				})
+				continue
+			}
+			if !overridden[sec.Name()+"."+key.Name()] {
+				iniConfig.Section(sec.Name()).Key(key.Name()).SetValue(key.Value())
+			}
+		}
+	}
+
+	return nil
+}
+
+// OverrideSource describes where the value of section.key comes from when
+// it is not the config file: an environment variable or the secrets file.
+// It returns an empty string when the config file value is used.
+func OverrideSource(section, key string) (string, error) {
+	if name := envOverrideName(section, key); name != "" {
+		return "environment variable " + name, nil
+	}
+	if !slices.Contains(secretKeys[section], key) {
+		return "", nil
+	}
+
+	stateMu.Lock()
+	path := getConfigValue("secrets", "file", "")
+	stateMu.Unlock()
+	if path == "" {
+		return "", nil
+	}
+	secrets, err := ini.Load(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to load secrets file %s: %w", path, err)
+	}
+	if secrets.Section(section).HasKey(key) {
+		return "secrets file " + path, nil
+	}
+	return "", nil
+}
@@ -0,0 +1,82 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// writeSecretsFile writes data to a secrets file with the given permissions
+// and points [secrets] file at it
+func writeSecretsFile(t *testing.T, data string, perm os.FileMode) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "secrets.ini")
+	if err := os.WriteFile(path, []byte(data), perm); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(path, perm); err != nil {
+		t.Fatal(err)
+	}
+	useINI(t, "[secrets]\nfile = "+path+"\n[database]\npassword = from-config\nhost = localhost\n[security]\ncsrf_secret = from-config\n")
+	return path
+}
+
+func TestMergeSecrets(t *testing.T) {
+	writeSecretsFile(t, "[database]\npassword = from-secrets\nhost = db.internal\n[security]\ncsrf_secret = csrf-from-secrets\n", 0600)
+
+	overridden := map[string]bool{"security.csrf_secret": true}
+	if err := mergeSecrets(Development, overridden); err != nil {
+		t.Fatalf("mergeSecrets: %v", err)
+	}
+
+	if got := iniConfig.Section("database").Key("password").String(); got != "from-secrets" {
+		t.Errorf("database.password = %q, want the secrets file value", got)
+	}
+	if got := iniConfig.Section("database").Key("host").String(); got != "localhost" {
+		t.Errorf("database.host = %q, non-sensitive keys must not be merged", got)
+	}
+	if got := iniConfig.Section("security").Key("csrf_secret").String(); got != "from-config" {
+		t.Errorf("security.csrf_secret = %q, environment overrides must win", got)
+	}
+	wantWarning(t, "ignoring non-sensitive key database.host")
+}
+
+func TestMergeSecretsMissingFile(t *testing.T) {
+	path := writeSecretsFile(t, "", 0600)
+	os.Remove(path)
+
+	err := mergeSecrets(Development, nil)
+	if err == nil || !strings.Contains(err.Error(), path) {
+		t.Errorf("mergeSecrets error = %v, want one naming %s", err, path)
+	}
+}
+
+func TestMergeSecretsNoFile(t *testing.T) {
+	useINI(t, "[database]\npassword = from-config\n")
+
+	if err := mergeSecrets(Development, nil); err != nil {
+		t.Fatalf("mergeSecrets without [secrets] file: %v", err)
+	}
+	if len(loadWarnings) != 0 {
+		t.Errorf("warnings = %v", loadWarnings)
+	}
+}
+
+func TestMergeSecretsPermissions(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file permissions are not checked on Windows")
+	}
+
+	path := writeSecretsFile(t, "[database]\npassword = from-secrets\n", 0644)
+	if err := mergeSecrets(Development, nil); err != nil {
+		t.Fatalf("mergeSecrets: %v", err)
+	}
+	wantWarning(t, "world-readable")
+
+	if err := mergeSecrets(Production, nil); err == nil || !strings.Contains(err.Error(), path) {
+		t.Errorf("production error = %v, want a world-readable error", err)
+	}
+}
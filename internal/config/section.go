@@ -0,0 +1,102 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// FieldError describes a validation problem with a single config field
+type FieldError struct {
+	Field   string `json:"field"`
+	Tag     string `json:"tag"`
+	Message string `json:"message"`
+}
+
+// ValidateSection validates the values of a single section, such as "api"
+// or "window", against the constraints of its struct. Values are keyed by
+// field name like in LookupValue and durations are given in seconds or as
+// duration strings. Fields that aren't provided keep their current values.
+// The loaded configuration is never modified.
+func ValidateSection(name string, values map[string]any) []FieldError {
+	var current Config
+	stateMu.Lock()
+	if instance != nil {
+		current = *instance
+	}
+	stateMu.Unlock()
+
+	section, sectionName, ok := findField(reflect.ValueOf(&current).Elem(), name)
+	if !ok || section.Kind() != reflect.Struct {
+		return []FieldError{{Field: name, Tag: "section", Message: fmt.Sprintf("unknown configuration section %q", name)}}
+	}
+
+	var fieldErrors []FieldError
+	for key, raw := range values {
+		field, fieldName, ok := findField(section, key)
+		if !ok {
+			fieldErrors = append(fieldErrors, FieldError{Field: key, Tag: "unknown", Message: fmt.Sprintf("unknown field %q in section %q", key, sectionName)})
+			continue
+		}
+		if err := setFieldValue(field, raw); err != nil {
+			fieldErrors = append(fieldErrors, FieldError{Field: fieldName, Tag: "type", Message: fmt.Sprintf("%s: %v", fieldName, err)})
+		}
+	}
+	if len(fieldErrors) > 0 {
+		return fieldErrors
+	}
+
+	err := validate.Struct(section.Interface())
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return nil
+	}
+	for _, fe := range validationErrors {
+		fieldName := fe.StructField()
+		if structField, ok := section.Type().FieldByName(fe.StructField()); ok {
+			fieldName = jsonName(structField)
+		}
+		fieldErrors = append(fieldErrors, FieldError{
+			Field:   fieldName,
+			Tag:     fe.Tag(),
+			Message: ValidationMessage(fe),
+		})
+	}
+	return fieldErrors
+}
+
+// setFieldValue assigns a value decoded from JSON to a config field
+func setFieldValue(field reflect.Value, raw any) error {
+	if _, ok := field.Interface().(time.Duration); ok {
+		number := reflect.ValueOf(raw)
+		switch v := raw.(type) {
+		case float64, float32, int, int32, int64:
+			field.SetInt(int64(number.Convert(reflect.TypeOf(float64(0))).Float() * float64(time.Second)))
+			return nil
+		case string:
+			d, err := time.ParseDuration(v)
+			if err != nil {
+				return err
+			}
+			field.SetInt(int64(d))
+			return nil
+		default:
+			return fmt.Errorf("expected seconds or a duration string, got %T", raw)
+		}
+	}
+
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return err
+	}
+	target := reflect.New(field.Type())
+	if err := json.Unmarshal(data, target.Interface()); err != nil {
+		return fmt.Errorf("expected a value of type %s", field.Type())
+	}
+	field.Set(target.Elem())
+	return nil
+}
@@ -0,0 +1,82 @@
+package config
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+// loadRepoConfig loads the repository config.ini as the current instance
+func loadRepoConfig(t *testing.T) *Config {
+	t.Helper()
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir("../.."); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	cfg, err := ReloadConfig()
+	if err != nil {
+		t.Fatalf("ReloadConfig: %v", err)
+	}
+	return cfg
+}
+
+func TestValidateSection(t *testing.T) {
+	loaded := loadRepoConfig(t)
+	before := *loaded
+
+	tests := []struct {
+		section string
+		good    map[string]any
+		bad     map[string]any
+		field   string
+		tag     string
+	}{
+		{"app", map[string]any{"name": "Renamed", "version": "2.0.0-beta.1"}, map[string]any{"version": "2.0"}, "version", "semver"},
+		{"api", map[string]any{"baseUrl": "https://api.example.com", "timeout": 45}, map[string]any{"base_url": "not a url"}, "baseUrl", "url"},
+		{"auth", map[string]any{"tokenExpiry": "2h", "maxLoginAttempts": 3}, map[string]any{"max_login_attempts": 0}, "maxLoginAttempts", "min"},
+		{"auth", map[string]any{"clientId": "id", "clientSecret": "0123456789abcdef0123456789abcdef"}, map[string]any{"clientId": "id"}, "clientSecret", "required_with"},
+		{"log", map[string]any{"level": "warn", "maxAge": 7}, map[string]any{"level": "verbose"}, "level", "oneof"},
+		{"database", map[string]any{"port": 5433, "sslMode": "require"}, map[string]any{"port": 70000}, "port", "max"},
+		{"security", map[string]any{"rateLimitRps": 50}, map[string]any{"rate_limit_burst": 0}, "rateLimitBurst", "min"},
+		{"window", map[string]any{"width": 1024, "height": 768}, map[string]any{"height": 100}, "height", "min"},
+		{"window", map[string]any{"minimized": true}, map[string]any{"minimized": true, "maximized": true}, "minimized", "excluded_with"},
+		{"cache", map[string]any{"ttl": 60, "evictionPolicy": "lfu"}, map[string]any{"eviction_policy": "random"}, "evictionPolicy", "oneof"},
+		{"tls", map[string]any{"caCertPath": "/etc/ssl/ca.pem"}, map[string]any{"caCertPath": 5}, "caCertPath", "type"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.section+"/"+tt.field, func(t *testing.T) {
+			if errs := ValidateSection(tt.section, tt.good); len(errs) != 0 {
+				t.Errorf("good values: %+v", errs)
+			}
+
+			errs := ValidateSection(tt.section, tt.bad)
+			if len(errs) != 1 || errs[0].Field != tt.field || errs[0].Tag != tt.tag {
+				t.Errorf("bad values: %+v, want one %s error on %s", errs, tt.tag, tt.field)
+			}
+		})
+	}
+
+	if !reflect.DeepEqual(*instance, before) || instance != loaded {
+		t.Error("ValidateSection modified the loaded configuration")
+	}
+}
+
+func TestValidateSectionUnknown(t *testing.T) {
+	loadRepoConfig(t)
+
+	if errs := ValidateSection("nope", nil); len(errs) != 1 || errs[0].Tag != "section" {
+		t.Errorf("unknown section: %+v", errs)
+	}
+	if errs := ValidateSection("api", map[string]any{"nope": 1}); len(errs) != 1 || errs[0].Tag != "unknown" {
+		t.Errorf("unknown field: %+v", errs)
+	}
+	if errs := ValidateSection("api", map[string]any{"timeout": true}); len(errs) != 1 || errs[0].Tag != "type" {
+		t.Errorf("wrongly typed duration: %+v", errs)
+	}
+}
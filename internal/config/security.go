@@ -4,11 +4,54 @@ import (
 	"crypto/rand"
 	"encoding/base64"
 	"fmt"
+	"io"
 	"os"
+	"reflect"
 	"regexp"
+	"slices"
 	"strings"
+	"sync"
 )
 
+// Security warning IDs, which can be listed in [security] disabled_warnings
+// to suppress the corresponding warning
+const (
+	WarningCORSNoOrigins             = "cors-no-origins"
+	WarningCORSInvalidOrigin         = "cors-invalid-origin"
+	WarningCORSDuplicateOrigin       = "cors-duplicate"
+	WarningCORSRedundantOrigin       = "cors-redundant"
+	WarningCORSInsecureWildcard      = "cors-insecure-wildcard"
+	WarningCSRFNoSecret              = "csrf-no-secret"
+	WarningCSRFShortSecret           = "csrf-short-secret"
+	WarningSigningNoSecret           = "signing-no-secret"
+	WarningRateLimitRPS              = "rate-limit-rps"
+	WarningRateLimitBurst            = "rate-limit-burst"
+	WarningProductionDebug           = "production-debug"
+	WarningProductionDevTools        = "production-dev-tools"
+	WarningProductionLocalhostOrigin = "production-localhost-origin"
+	WarningProductionDatabaseSSL     = "production-database-ssl"
+	WarningProductionAPITimeout      = "production-api-timeout"
+	WarningStagingDebug              = "staging-debug"
+	WarningStagingDevTools           = "staging-dev-tools"
+)
+
+// Environment check IDs, which identify environment warnings in health
+// reports
+const (
+	EnvDevelopmentDebugDisabled = "env-development-debug-disabled"
+	EnvDevelopmentRemoteAPI     = "env-development-remote-api"
+	EnvStagingLocalhostAPI      = "env-staging-localhost-api"
+	EnvStagingLowTimeout        = "env-staging-low-timeout"
+	EnvProductionDebug          = "env-production-debug"
+	EnvProductionDevTools       = "env-production-dev-tools"
+	EnvProductionHTTP           = "env-production-http"
+	EnvProductionDatabaseSSL    = "env-production-database-ssl"
+	EnvProductionNoRateLimit    = "env-production-no-rate-limit"
+)
+
+// loggedSuppressions records suppressed warnings that were already logged
+var loggedSuppressions sync.Map
+
 // SecurityValidator provides security validation for configuration
 type SecurityValidator struct {
 	config *Config
@@ -21,109 +64,217 @@ func NewSecurityValidator(config *Config) *SecurityValidator {
 
 // ValidateSecuritySettings validates security-related configuration
 func (sv *SecurityValidator) ValidateSecuritySettings() []string {
-	var warnings []string
+	return reportMessages(sv.Findings())
+}
+
+// Findings validates security-related configuration like
+// ValidateSecuritySettings, returning the warnings with their IDs
+func (sv *SecurityValidator) Findings() []ReportEntry {
+	var warnings []ReportEntry
 
 	// Validate CORS settings
-	if sv.config.Security.CORSEnabled {
-		if len(sv.config.Security.CORSOrigins) == 0 {
-			warnings = append(warnings, "CORS is enabled but no origins are specified")
-		} else {
-			for _, origin := range sv.config.Security.CORSOrigins {
-				if !isValidOrigin(origin) {
-					warnings = append(warnings, fmt.Sprintf("Invalid CORS origin: %s", origin))
-				}
-			}
-		}
-	}
+	warnings = append(warnings, sv.ValidateCORS()...)
 
 	// Validate CSRF settings
 	if sv.config.Security.CSRFEnabled {
 		if sv.config.Security.CSRFSecret == "" {
-			warnings = append(warnings, "CSRF is enabled but no secret is provided")
+			warnings = sv.warn(warnings, WarningCSRFNoSecret, "CSRF is enabled but no secret is provided")
 		} else if len(sv.config.Security.CSRFSecret) < 32 {
-			warnings = append(warnings, "CSRF secret should be at least 32 characters long")
+			warnings = sv.warn(warnings, WarningCSRFShortSecret, "CSRF secret should be at least 32 characters long")
 		}
 	}
 
+	// Validate request signing
+	if sv.config.API.SigningEnabled && sv.config.API.SigningSecret == "" {
+		warnings = sv.warn(warnings, WarningSigningNoSecret, "Request signing is enabled but no signing secret is provided")
+	}
+
 	// Validate rate limiting
 	if sv.config.Security.RateLimitEnabled {
 		if sv.config.Security.RateLimitRPS <= 0 {
-			warnings = append(warnings, "Rate limiting is enabled but RPS is not positive")
+			warnings = sv.warn(warnings, WarningRateLimitRPS, "Rate limiting is enabled but RPS is not positive")
 		}
 		if sv.config.Security.RateLimitBurst <= 0 {
-			warnings = append(warnings, "Rate limiting is enabled but burst is not positive")
+			warnings = sv.warn(warnings, WarningRateLimitBurst, "Rate limiting is enabled but burst is not positive")
 		}
 	}
 
 	// Check for production security requirements
-	if sv.config.App.Environment == Production {
+	if sv.config.App.Environment.IsProduction() {
 		warnings = append(warnings, sv.validateProductionSecurity()...)
 	}
 
+	// Staging keeps debug tooling but flags it
+	if sv.config.App.Environment.IsStaging() {
+		warnings = append(warnings, sv.validateStagingSecurity()...)
+	}
+
+	return warnings
+}
+
+// ValidateCORS checks the CORS origins as a whole: besides invalid origins it
+// reports duplicates, origins already covered by a wildcard pattern, and the
+// "*" wildcard in production. It returns nothing when CORS is disabled.
+func (sv *SecurityValidator) ValidateCORS() []ReportEntry {
+	var warnings []ReportEntry
+	if !sv.config.Security.CORSEnabled {
+		return warnings
+	}
+
+	origins := sv.config.Security.CORSOrigins
+	if len(origins) == 0 {
+		return sv.warn(warnings, WarningCORSNoOrigins, "CORS is enabled but no origins are specified")
+	}
+
+	seen := make(map[string]bool, len(origins))
+	for _, origin := range origins {
+		if !isValidOrigin(origin) {
+			warnings = sv.warn(warnings, WarningCORSInvalidOrigin, fmt.Sprintf("Invalid CORS origin: %s", origin))
+			continue
+		}
+
+		normalized := strings.ToLower(origin)
+		if seen[normalized] {
+			warnings = sv.warn(warnings, WarningCORSDuplicateOrigin, fmt.Sprintf("CORS origin %s is listed more than once", origin))
+			continue
+		}
+		seen[normalized] = true
+
+		// Another pattern matching this one makes it redundant
+		for _, pattern := range origins {
+			if strings.ToLower(pattern) != normalized && isValidOrigin(pattern) && MatchOrigin(pattern, origin) {
+				warnings = sv.warn(warnings, WarningCORSRedundantOrigin, fmt.Sprintf("CORS origin %s is already covered by %s", origin, pattern))
+				break
+			}
+		}
+	}
+
+	if sv.config.App.Environment.IsProduction() && seen["*"] {
+		warnings = sv.warn(warnings, WarningCORSInsecureWildcard, "CORS allows any origin (*) in production")
+	}
+
+	return warnings
+}
+
+// warn appends the warning unless its ID is disabled in the security
+// config. Suppressed warnings are logged once at debug level for auditing.
+func (sv *SecurityValidator) warn(warnings []ReportEntry, id, message string) []ReportEntry {
+	if !slices.Contains(sv.config.Security.DisabledWarnings, id) {
+		return append(warnings, ReportEntry{ID: id, Severity: SeverityWarning, Section: "security", Message: message})
+	}
+	if _, logged := loggedSuppressions.LoadOrStore(id+"|"+message, true); !logged && sv.config.Log.Level == LogLevelDebug {
+		fmt.Printf("Suppressed security warning %s: %s\n", id, message)
+	}
 	return warnings
 }
 
 // validateProductionSecurity validates production-specific security requirements
-func (sv *SecurityValidator) validateProductionSecurity() []string {
-	var warnings []string
+func (sv *SecurityValidator) validateProductionSecurity() []ReportEntry {
+	var warnings []ReportEntry
 
 	// Debug mode should be disabled in production
 	if sv.config.App.Debug {
-		warnings = append(warnings, "Debug mode should be disabled in production")
+		warnings = sv.warn(warnings, WarningProductionDebug, "Debug mode should be disabled in production")
 	}
 
 	// Dev tools should be disabled in production
 	if sv.config.App.DevTools {
-		warnings = append(warnings, "Dev tools should be disabled in production")
+		warnings = sv.warn(warnings, WarningProductionDevTools, "Dev tools should be disabled in production")
 	}
 
 	// CORS should be properly configured in production
 	if sv.config.Security.CORSEnabled {
 		for _, origin := range sv.config.Security.CORSOrigins {
 			if strings.Contains(origin, "localhost") || strings.Contains(origin, "127.0.0.1") {
-				warnings = append(warnings, "Localhost origins should not be allowed in production")
+				warnings = sv.warn(warnings, WarningProductionLocalhostOrigin, "Localhost origins should not be allowed in production")
 			}
 		}
 	}
 
 	// Database SSL should be enabled in production
 	if sv.config.Database.SSLMode == "disable" {
-		warnings = append(warnings, "Database SSL should be enabled in production")
+		warnings = sv.warn(warnings, WarningProductionDatabaseSSL, "Database SSL should be enabled in production")
 	}
 
 	// API timeout should be reasonable in production
 	if sv.config.API.Timeout.Seconds() > 60 {
-		warnings = append(warnings, "API timeout is very high for production environment")
+		warnings = sv.warn(warnings, WarningProductionAPITimeout, "API timeout is very high for production environment")
 	}
 
 	return warnings
 }
 
-// SanitizeConfig removes or masks sensitive information from config
-func (sv *SecurityValidator) SanitizeConfig() *Config {
-	sanitized := *sv.config
+// validateStagingSecurity flags debug tooling left enabled in staging
+func (sv *SecurityValidator) validateStagingSecurity() []ReportEntry {
+	var warnings []ReportEntry
 
-	// Mask sensitive database information
-	if sanitized.Database.Password != "" {
-		sanitized.Database.Password = "***MASKED***"
+	if sv.config.App.Debug {
+		warnings = sv.warn(warnings, WarningStagingDebug, "Debug mode is enabled in staging")
 	}
+	if sv.config.App.DevTools {
+		warnings = sv.warn(warnings, WarningStagingDevTools, "Dev tools are enabled in staging")
+	}
+
+	return warnings
+}
 
-	// Mask CSRF secret
-	if sanitized.Security.CSRFSecret != "" {
-		sanitized.Security.CSRFSecret = "***MASKED***"
+// apiURLs returns the base URL of the API followed by its balanced
+// endpoints
+func apiURLs(api APIConfig) []string {
+	urls := []string{api.BaseURL}
+	for _, entry := range api.BaseURLs {
+		if url, _, err := ParseEndpoint(entry); err == nil {
+			urls = append(urls, url)
+		}
+	}
+	return urls
+}
+
+// applyStagingProfile makes staging production-like on the wire: the API
+// must be reached over HTTPS and the database connection requires SSL
+func applyStagingProfile(config *Config) error {
+	if !config.App.Environment.IsStaging() {
+		return nil
+	}
+	for _, url := range apiURLs(config.API) {
+		if !strings.HasPrefix(url, "https://") {
+			return fmt.Errorf("staging must use an HTTPS API URL, got %s", url)
+		}
+	}
+	if config.Database.SSLMode == "disable" {
+		config.Database.SSLMode = "require"
 	}
+	return nil
+}
 
+// SanitizeConfig returns a copy of the config with every field tagged
+// sensitive:"true" masked
+func (sv *SecurityValidator) SanitizeConfig() *Config {
+	sanitized := *sv.config
+	maskSensitive(reflect.ValueOf(&sanitized).Elem())
 	return &sanitized
 }
 
+// randReader is the source of the random bytes of GenerateSecureSecret,
+// replaced by tests
+var randReader io.Reader = rand.Reader
+
 // GenerateSecureSecret generates a cryptographically secure secret
 func GenerateSecureSecret(length int) (string, error) {
+	return GenerateSecureSecretWithReader(randReader, length)
+}
+
+// GenerateSecureSecretWithReader generates a secret like
+// GenerateSecureSecret, reading the random bytes from r. The secret is only
+// as strong as r; it is meant for environments without a usable
+// crypto/rand source and for deterministic tests.
+func GenerateSecureSecretWithReader(r io.Reader, length int) (string, error) {
 	if length < 16 {
 		return "", fmt.Errorf("secret length must be at least 16 characters")
 	}
 
 	bytes := make([]byte, length)
-	if _, err := rand.Read(bytes); err != nil {
+	if _, err := io.ReadFull(r, bytes); err != nil {
 		return "", fmt.Errorf("failed to generate secure random bytes: %w", err)
 	}
 
@@ -136,11 +287,46 @@ func isValidOrigin(origin string) bool {
 		return true // Wildcard is valid but not recommended for production
 	}
 
-	// Basic URL validation
-	urlPattern := regexp.MustCompile(`^https?://[a-zA-Z0-9.-]+(:[0-9]+)?$`)
+	// Basic URL validation, allowing a wildcard subdomain (https://*.example.com)
+	urlPattern := regexp.MustCompile(`^https?://(\*\.)?[a-zA-Z0-9.-]+(:[0-9]+)?$`)
 	return urlPattern.MatchString(origin)
 }
 
+// IsOriginAllowed reports whether origin matches one of the configured CORS
+// origins. It always returns false when CORS is disabled.
+func (s SecurityConfig) IsOriginAllowed(origin string) bool {
+	if !s.CORSEnabled || origin == "" {
+		return false
+	}
+	for _, pattern := range s.CORSOrigins {
+		if MatchOrigin(pattern, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchOrigin reports whether origin matches a CORS origin pattern. A pattern
+// is either "*", an exact origin, or an origin with a wildcard subdomain such
+// as https://*.example.com, which matches any subdomain but not the bare domain.
+func MatchOrigin(pattern, origin string) bool {
+	pattern = strings.ToLower(pattern)
+	origin = strings.ToLower(origin)
+	if pattern == "*" || pattern == origin {
+		return true
+	}
+
+	scheme, domain, ok := strings.Cut(pattern, "://*.")
+	if !ok {
+		return false
+	}
+	host, ok := strings.CutPrefix(origin, scheme+"://")
+	if !ok {
+		return false
+	}
+	return len(host) > len(domain)+1 && strings.HasSuffix(host, "."+domain)
+}
+
 // SecureConfigLoader provides secure configuration loading
 type SecureConfigLoader struct {
 	validator *SecurityValidator
@@ -164,11 +350,7 @@ func (scl *SecureConfigLoader) LoadSecureConfig() (*Config, error) {
 
 	// Validate security settings
 	warnings := scl.validator.ValidateSecuritySettings()
-	if len(warnings) > 0 {
-		for _, warning := range warnings {
-			fmt.Printf("Security Warning: %s\n", warning)
-		}
-	}
+	printReport(newReportEntries(SeverityWarning, "security", warnings))
 
 	// Apply security defaults if needed
 	if err := scl.applySecurityDefaults(config); err != nil {
@@ -191,7 +373,7 @@ func (scl *SecureConfigLoader) applySecurityDefaults(config *Config) error {
 	}
 
 	// Set secure defaults for production
-	if config.App.Environment == Production {
+	if config.App.Environment.IsProduction() {
 		// Disable debug features
 		config.App.Debug = false
 		config.App.DevTools = false
@@ -225,90 +407,98 @@ func NewEnvironmentValidator(env Environment) *EnvironmentValidator {
 
 // ValidateEnvironment validates environment-specific configuration requirements
 func (ev *EnvironmentValidator) ValidateEnvironment(config *Config) []string {
-	var errors []string
+	return reportMessages(ev.Findings(config))
+}
 
+// Findings validates the environment requirements like ValidateEnvironment,
+// returning the problems as warnings with their IDs
+func (ev *EnvironmentValidator) Findings(config *Config) []ReportEntry {
 	switch ev.environment {
 	case Development:
-		errors = append(errors, ev.validateDevelopment(config)...)
+		return ev.validateDevelopment(config)
 	case Staging:
-		errors = append(errors, ev.validateStaging(config)...)
+		return ev.validateStaging(config)
 	case Production:
-		errors = append(errors, ev.validateProduction(config)...)
+		return ev.validateProduction(config)
 	}
-
-	return errors
+	return nil
 }
 
 // validateDevelopment validates development environment requirements
-func (ev *EnvironmentValidator) validateDevelopment(config *Config) []string {
-	var errors []string
+func (ev *EnvironmentValidator) validateDevelopment(config *Config) []ReportEntry {
+	var errors []ReportEntry
 
 	// Development should have debug enabled
 	if !config.App.Debug {
-		errors = append(errors, "Debug mode should be enabled in development")
+		errors = append(errors, environmentWarning(EnvDevelopmentDebugDisabled, "Debug mode should be enabled in development"))
 	}
 
 	// Check for localhost API URLs
 	if !strings.Contains(config.API.BaseURL, "localhost") && !strings.Contains(config.API.BaseURL, "127.0.0.1") && !strings.Contains(config.API.BaseURL, "test") {
-		errors = append(errors, "Development should typically use localhost or test API URLs")
+		errors = append(errors, environmentWarning(EnvDevelopmentRemoteAPI, "Development should typically use localhost or test API URLs"))
 	}
 
 	return errors
 }
 
 // validateStaging validates staging environment requirements
-func (ev *EnvironmentValidator) validateStaging(config *Config) []string {
-	var errors []string
+func (ev *EnvironmentValidator) validateStaging(config *Config) []ReportEntry {
+	var errors []ReportEntry
 
 	// Staging should not use localhost
 	if strings.Contains(config.API.BaseURL, "localhost") || strings.Contains(config.API.BaseURL, "127.0.0.1") {
-		errors = append(errors, "Staging should not use localhost API URLs")
+		errors = append(errors, environmentWarning(EnvStagingLocalhostAPI, "Staging should not use localhost API URLs"))
 	}
 
 	// Staging should have reasonable timeouts
 	if config.API.Timeout.Seconds() < 10 {
-		errors = append(errors, "API timeout is too low for staging environment")
+		errors = append(errors, environmentWarning(EnvStagingLowTimeout, "API timeout is too low for staging environment"))
 	}
 
 	return errors
 }
 
 // validateProduction validates production environment requirements
-func (ev *EnvironmentValidator) validateProduction(config *Config) []string {
-	var errors []string
+func (ev *EnvironmentValidator) validateProduction(config *Config) []ReportEntry {
+	var errors []ReportEntry
 
 	// Production must not have debug enabled
 	if config.App.Debug {
-		errors = append(errors, "Debug mode must be disabled in production")
+		errors = append(errors, environmentWarning(EnvProductionDebug, "Debug mode must be disabled in production"))
 	}
 
 	// Production must not have dev tools enabled
 	if config.App.DevTools {
-		errors = append(errors, "Dev tools must be disabled in production")
+		errors = append(errors, environmentWarning(EnvProductionDevTools, "Dev tools must be disabled in production"))
 	}
 
 	// Production must use HTTPS API URLs
-	if !strings.HasPrefix(config.API.BaseURL, "https://") {
-		errors = append(errors, "Production must use HTTPS API URLs")
+	if slices.ContainsFunc(apiURLs(config.API), func(url string) bool { return !strings.HasPrefix(url, "https://") }) {
+		errors = append(errors, environmentWarning(EnvProductionHTTP, "Production must use HTTPS API URLs"))
 	}
 
 	// Production should have SSL enabled for database
 	if config.Database.SSLMode == "disable" {
-		errors = append(errors, "Database SSL must be enabled in production")
+		errors = append(errors, environmentWarning(EnvProductionDatabaseSSL, "Database SSL must be enabled in production"))
 	}
 
 	// Production should have rate limiting enabled
 	if !config.Security.RateLimitEnabled {
-		errors = append(errors, "Rate limiting should be enabled in production")
+		errors = append(errors, environmentWarning(EnvProductionNoRateLimit, "Rate limiting should be enabled in production"))
 	}
 
 	return errors
 }
 
+// environmentWarning creates a report entry for a failed environment check
+func environmentWarning(id, message string) ReportEntry {
+	return ReportEntry{ID: id, Severity: SeverityWarning, Section: "environment", Message: message}
+}
+
 // CheckEnvironmentFile validates that the configuration file exists
 func CheckEnvironmentFile(env Environment) error {
-	if _, err := os.Stat("config.ini"); os.IsNotExist(err) {
-		return fmt.Errorf("configuration file config.ini does not exist")
+	if _, err := os.Stat(ConfigFile); os.IsNotExist(err) {
+		return fmt.Errorf("configuration file %s does not exist", ConfigFile)
 	}
 	return nil
 }
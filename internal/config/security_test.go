@@ -0,0 +1,228 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+// productionConfig returns a production config that raises the database SSL
+// and debug mode warnings
+func productionConfig() *Config {
+	return &Config{
+		App:      AppConfig{Environment: Production, Debug: true},
+		Database: DatabaseConfig{SSLMode: "disable"},
+	}
+}
+
+func TestSecurityWarningsReported(t *testing.T) {
+	warnings := NewSecurityValidator(productionConfig()).ValidateSecuritySettings()
+
+	for _, want := range []string{
+		"Debug mode should be disabled in production",
+		"Database SSL should be enabled in production",
+	} {
+		if !slices.Contains(warnings, want) {
+			t.Errorf("warnings = %q, missing %q", warnings, want)
+		}
+	}
+}
+
+func TestSecurityWarningSuppressed(t *testing.T) {
+	cfg := productionConfig()
+	cfg.Security.DisabledWarnings = []string{WarningProductionDatabaseSSL}
+
+	warnings := NewSecurityValidator(cfg).ValidateSecuritySettings()
+	if slices.Contains(warnings, "Database SSL should be enabled in production") {
+		t.Errorf("suppressed warning was reported: %q", warnings)
+	}
+	if !slices.Contains(warnings, "Debug mode should be disabled in production") {
+		t.Errorf("unrelated warning was dropped: %q", warnings)
+	}
+}
+
+func TestStagingProfileRequiresDatabaseSSL(t *testing.T) {
+	t.Setenv("APP_ENV", "staging")
+	t.Setenv("CSMART_DATABASE_SSL_MODE", "disable")
+
+	cfg := loadRepoConfig(t)
+	if cfg.Database.SSLMode != "require" {
+		t.Errorf("SSLMode = %q, want require", cfg.Database.SSLMode)
+	}
+}
+
+func TestStagingProfileRejectsHTTP(t *testing.T) {
+	t.Setenv("APP_ENV", "staging")
+	t.Setenv("CSMART_API_BASE_URL", "http://api.example.com")
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir("../.."); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	if _, err := ReloadConfig(); err == nil || !strings.Contains(err.Error(), "HTTPS") {
+		t.Errorf("ReloadConfig error = %v, want HTTPS error", err)
+	}
+}
+
+func TestStagingWarningsSuppressed(t *testing.T) {
+	cfg := &Config{App: AppConfig{Environment: Staging, Debug: true, DevTools: true}}
+
+	warnings := NewSecurityValidator(cfg).ValidateSecuritySettings()
+	for _, want := range []string{
+		"Debug mode is enabled in staging",
+		"Dev tools are enabled in staging",
+	} {
+		if !slices.Contains(warnings, want) {
+			t.Errorf("warnings = %q, missing %q", warnings, want)
+		}
+	}
+
+	cfg.Security.DisabledWarnings = []string{WarningStagingDebug, WarningStagingDevTools}
+	if warnings := NewSecurityValidator(cfg).ValidateSecuritySettings(); len(warnings) != 0 {
+		t.Errorf("suppressed warnings were reported: %q", warnings)
+	}
+}
+
+func TestValidateCORS(t *testing.T) {
+	tests := []struct {
+		name        string
+		environment Environment
+		origins     []string
+		want        []string // warning IDs
+	}{
+		{
+			name:    "clean",
+			origins: []string{"https://app.example.com", "https://*.example.org"},
+		},
+		{
+			name:    "duplicate",
+			origins: []string{"https://app.example.com", "https://APP.example.com"},
+			want:    []string{WarningCORSDuplicateOrigin},
+		},
+		{
+			name:    "covered by wildcard subdomain",
+			origins: []string{"https://*.example.com", "https://app.example.com", "http://app.example.com"},
+			want:    []string{WarningCORSRedundantOrigin},
+		},
+		{
+			name:    "covered by wider wildcard subdomain",
+			origins: []string{"https://*.api.example.com", "https://*.example.com"},
+			want:    []string{WarningCORSRedundantOrigin},
+		},
+		{
+			name:    "covered by any origin",
+			origins: []string{"*", "https://app.example.com"},
+			want:    []string{WarningCORSRedundantOrigin},
+		},
+		{
+			name:        "wildcard in production",
+			environment: Production,
+			origins:     []string{"*"},
+			want:        []string{WarningCORSInsecureWildcard},
+		},
+		{
+			name:    "wildcard outside production",
+			origins: []string{"*"},
+		},
+		{
+			name:    "invalid origin",
+			origins: []string{"app.example.com"},
+			want:    []string{WarningCORSInvalidOrigin},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{
+				App:      AppConfig{Environment: tt.environment},
+				Security: SecurityConfig{CORSEnabled: true, CORSOrigins: tt.origins},
+			}
+
+			var got []string
+			for _, finding := range NewSecurityValidator(cfg).ValidateCORS() {
+				got = append(got, finding.ID)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("finding IDs = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCORSFindingsInWarnings(t *testing.T) {
+	cfg := &Config{Security: SecurityConfig{
+		CORSEnabled: true,
+		CORSOrigins: []string{"https://*.example.com", "https://app.example.com"},
+	}}
+
+	want := "CORS origin https://app.example.com is already covered by https://*.example.com"
+	if warnings := NewSecurityValidator(cfg).ValidateSecuritySettings(); !slices.Equal(warnings, []string{want}) {
+		t.Errorf("warnings = %q, want %q", warnings, want)
+	}
+
+	cfg.Security.CORSEnabled = false
+	if findings := NewSecurityValidator(cfg).ValidateCORS(); len(findings) != 0 {
+		t.Errorf("findings with CORS disabled = %v, want none", findings)
+	}
+}
+
+func TestSigningWithoutSecretWarning(t *testing.T) {
+	cfg := &Config{API: APIConfig{SigningEnabled: true}}
+
+	want := "Request signing is enabled but no signing secret is provided"
+	if warnings := NewSecurityValidator(cfg).ValidateSecuritySettings(); !slices.Contains(warnings, want) {
+		t.Errorf("warnings = %q, missing %q", warnings, want)
+	}
+
+	cfg.API.SigningSecret = "secret"
+	if warnings := NewSecurityValidator(cfg).ValidateSecuritySettings(); slices.Contains(warnings, want) {
+		t.Errorf("warning reported with a secret: %q", warnings)
+	}
+	if sanitized := NewSecurityValidator(cfg).SanitizeConfig(); sanitized.API.SigningSecret == "secret" {
+		t.Error("signing secret not masked")
+	}
+}
+
+func TestGenerateSecureSecretReproducible(t *testing.T) {
+	oldReader := randReader
+	t.Cleanup(func() { randReader = oldReader })
+
+	var secrets []string
+	for range 2 {
+		randReader = bytes.NewReader(bytes.Repeat([]byte{0xAB, 0x01, 0x7F}, 32))
+		secret, err := GenerateSecureSecret(32)
+		if err != nil {
+			t.Fatalf("GenerateSecureSecret: %v", err)
+		}
+		secrets = append(secrets, secret)
+	}
+	if secrets[0] != secrets[1] || len(secrets[0]) != 32 {
+		t.Errorf("secrets = %q, want the same 32 characters from the same bytes", secrets)
+	}
+
+	fixed, err := GenerateSecureSecretWithReader(bytes.NewReader(make([]byte, 16)), 16)
+	if err != nil || fixed != "AAAAAAAAAAAAAAAA" {
+		t.Errorf("secret from zero bytes = %q, %v, want AAAAAAAAAAAAAAAA", fixed, err)
+	}
+}
+
+func TestGenerateSecureSecretReaderError(t *testing.T) {
+	entropyErr := errors.New("entropy source unavailable")
+	if _, err := GenerateSecureSecretWithReader(iotest.ErrReader(entropyErr), 32); !errors.Is(err, entropyErr) {
+		t.Errorf("error = %v, want the reader error", err)
+	}
+
+	// A reader that runs dry is an error, not a shorter secret
+	if _, err := GenerateSecureSecretWithReader(bytes.NewReader(make([]byte, 8)), 32); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("error = %v, want io.ErrUnexpectedEOF", err)
+	}
+}
@@ -0,0 +1,172 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Semver is a semantic version as defined by https://semver.org
+type Semver struct {
+	Major      int
+	Minor      int
+	Patch      int
+	PreRelease []string
+	Build      []string
+}
+
+// ParseSemver parses a semantic version such as 1.2.3, 1.2.3-beta.1 or
+// 1.2.3+build.5
+func ParseSemver(s string) (Semver, error) {
+	var v Semver
+
+	rest, build, hasBuild := strings.Cut(s, "+")
+	core, preRelease, hasPreRelease := strings.Cut(rest, "-")
+
+	parts := strings.Split(core, ".")
+	if len(parts) != 3 {
+		return v, fmt.Errorf("invalid version %q: expected major.minor.patch", s)
+	}
+	numbers := [3]*int{&v.Major, &v.Minor, &v.Patch}
+	for i, part := range parts {
+		n, err := parseNumericIdentifier(part)
+		if err != nil {
+			return v, fmt.Errorf("invalid version %q: %w", s, err)
+		}
+		*numbers[i] = n
+	}
+
+	if hasPreRelease {
+		v.PreRelease = strings.Split(preRelease, ".")
+		for _, id := range v.PreRelease {
+			if err := validateIdentifier(id); err != nil {
+				return v, fmt.Errorf("invalid pre-release in %q: %w", s, err)
+			}
+			if isNumeric(id) {
+				if _, err := parseNumericIdentifier(id); err != nil {
+					return v, fmt.Errorf("invalid pre-release in %q: %w", s, err)
+				}
+			}
+		}
+	}
+
+	if hasBuild {
+		v.Build = strings.Split(build, ".")
+		for _, id := range v.Build {
+			if err := validateIdentifier(id); err != nil {
+				return v, fmt.Errorf("invalid build metadata in %q: %w", s, err)
+			}
+		}
+	}
+
+	return v, nil
+}
+
+// Compare returns -1, 0 or 1 when v has lower, equal or higher precedence
+// than other. Build metadata is ignored as required by the spec.
+func (v Semver) Compare(other Semver) int {
+	for _, pair := range [][2]int{{v.Major, other.Major}, {v.Minor, other.Minor}, {v.Patch, other.Patch}} {
+		if c := compareInts(pair[0], pair[1]); c != 0 {
+			return c
+		}
+	}
+
+	// A version without pre-release has higher precedence
+	switch {
+	case len(v.PreRelease) == 0 && len(other.PreRelease) == 0:
+		return 0
+	case len(v.PreRelease) == 0:
+		return 1
+	case len(other.PreRelease) == 0:
+		return -1
+	}
+
+	for i := 0; i < len(v.PreRelease) && i < len(other.PreRelease); i++ {
+		if c := comparePreReleaseIdentifiers(v.PreRelease[i], other.PreRelease[i]); c != 0 {
+			return c
+		}
+	}
+	return compareInts(len(v.PreRelease), len(other.PreRelease))
+}
+
+// String formats the version
+func (v Semver) String() string {
+	s := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if len(v.PreRelease) > 0 {
+		s += "-" + strings.Join(v.PreRelease, ".")
+	}
+	if len(v.Build) > 0 {
+		s += "+" + strings.Join(v.Build, ".")
+	}
+	return s
+}
+
+// comparePreReleaseIdentifiers compares identifiers numerically when both
+// are numeric and lexically otherwise; numeric identifiers sort first
+func comparePreReleaseIdentifiers(a, b string) int {
+	aNumeric, bNumeric := isNumeric(a), isNumeric(b)
+	switch {
+	case aNumeric && bNumeric:
+		an, _ := strconv.Atoi(a)
+		bn, _ := strconv.Atoi(b)
+		return compareInts(an, bn)
+	case aNumeric:
+		return -1
+	case bNumeric:
+		return 1
+	default:
+		return strings.Compare(a, b)
+	}
+}
+
+// parseNumericIdentifier parses a non-negative number without leading zeros
+func parseNumericIdentifier(s string) (int, error) {
+	if !isNumeric(s) {
+		return 0, fmt.Errorf("%q is not a number", s)
+	}
+	if len(s) > 1 && s[0] == '0' {
+		return 0, fmt.Errorf("%q has a leading zero", s)
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("%q is out of range", s)
+	}
+	return n, nil
+}
+
+// validateIdentifier checks an identifier is non-empty and only contains
+// ASCII alphanumerics and hyphens
+func validateIdentifier(id string) error {
+	if id == "" {
+		return fmt.Errorf("empty identifier")
+	}
+	for _, r := range id {
+		if !(r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r == '-') {
+			return fmt.Errorf("identifier %q contains invalid character %q", id, r)
+		}
+	}
+	return nil
+}
+
+func isNumeric(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func compareInts(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
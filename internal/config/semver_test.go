@@ -0,0 +1,123 @@
+package config
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseSemverValid(t *testing.T) {
+	tests := []struct {
+		input      string
+		major      int
+		minor      int
+		patch      int
+		preRelease []string
+		build      []string
+	}{
+		{"0.0.0", 0, 0, 0, nil, nil},
+		{"1.2.3", 1, 2, 3, nil, nil},
+		{"10.20.30", 10, 20, 30, nil, nil},
+		{"1.2.3-beta.1", 1, 2, 3, []string{"beta", "1"}, nil},
+		{"1.2.3-alpha", 1, 2, 3, []string{"alpha"}, nil},
+		{"1.2.3-0.3.7", 1, 2, 3, []string{"0", "3", "7"}, nil},
+		{"1.2.3-x-y-z.--", 1, 2, 3, []string{"x-y-z", "--"}, nil},
+		{"1.2.3+build.5", 1, 2, 3, nil, []string{"build", "5"}},
+		{"1.2.3+001", 1, 2, 3, nil, []string{"001"}},
+		{"1.2.3-rc.1+exp.sha.5114f85", 1, 2, 3, []string{"rc", "1"}, []string{"exp", "sha", "5114f85"}},
+		{"1.0.0-alpha+beta-1", 1, 0, 0, []string{"alpha"}, []string{"beta-1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			v, err := ParseSemver(tt.input)
+			if err != nil {
+				t.Fatalf("ParseSemver: %v", err)
+			}
+			if v.Major != tt.major || v.Minor != tt.minor || v.Patch != tt.patch ||
+				!slices.Equal(v.PreRelease, tt.preRelease) || !slices.Equal(v.Build, tt.build) {
+				t.Errorf("ParseSemver = %+v", v)
+			}
+			if v.String() != tt.input {
+				t.Errorf("String() = %q, want %q", v.String(), tt.input)
+			}
+		})
+	}
+}
+
+func TestParseSemverInvalid(t *testing.T) {
+	for _, input := range []string{
+		"",
+		"1",
+		"1.2",
+		"1.2.3.4",
+		"v1.2.3",
+		"01.2.3",
+		"1.02.3",
+		"1.2.03",
+		"-1.2.3",
+		"1.2.3-",
+		"1.2.3+",
+		"1.2.3-beta..1",
+		"1.2.3-01",
+		"1.2.3-beta_1",
+		"1.2.3+build!",
+		"a.b.c",
+		"1.2.3 ",
+		"99999999999999999999.0.0",
+	} {
+		t.Run(input, func(t *testing.T) {
+			if v, err := ParseSemver(input); err == nil {
+				t.Errorf("ParseSemver(%q) = %+v, want an error", input, v)
+			}
+		})
+	}
+}
+
+func TestSemverCompare(t *testing.T) {
+	// Ordered by precedence, as in the example of the semver spec
+	ordered := []string{
+		"1.0.0-alpha",
+		"1.0.0-alpha.1",
+		"1.0.0-alpha.beta",
+		"1.0.0-beta",
+		"1.0.0-beta.2",
+		"1.0.0-beta.11",
+		"1.0.0-rc.1",
+		"1.0.0",
+		"1.0.1",
+		"1.1.0",
+		"2.0.0",
+	}
+	for i := range ordered {
+		for j := range ordered {
+			a, _ := ParseSemver(ordered[i])
+			b, _ := ParseSemver(ordered[j])
+			if got, want := a.Compare(b), compareInts(i, j); got != want {
+				t.Errorf("Compare(%s, %s) = %d, want %d", ordered[i], ordered[j], got, want)
+			}
+		}
+	}
+
+	a, _ := ParseSemver("1.0.0+build.1")
+	b, _ := ParseSemver("1.0.0+build.2")
+	if a.Compare(b) != 0 {
+		t.Error("build metadata affected precedence")
+	}
+}
+
+func TestSemverValidator(t *testing.T) {
+	type versioned struct {
+		Version string `validate:"semver"`
+	}
+	for input, valid := range map[string]bool{
+		"1.2.3":        true,
+		"1.2.3-beta.1": true,
+		"1.2.3+build":  true,
+		"1.2":          false,
+		"latest":       false,
+	} {
+		err := validate.Struct(versioned{Version: input})
+		if (err == nil) != valid {
+			t.Errorf("semver validator on %q: error = %v, want valid = %v", input, err, valid)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package config
+
+import "reflect"
+
+// maskedSecret replaces secrets in sanitized output
+const maskedSecret = "***MASKED***"
+
+// sensitivePaths lists the config values that must never be exposed, by
+// their JSON path. Fields are marked with a sensitive:"true" tag.
+var sensitivePaths = sensitiveFieldPaths(reflect.TypeOf(Config{}), "")
+
+// isSensitive reports whether a struct field holds a secret
+func isSensitive(field reflect.StructField) bool {
+	return field.Tag.Get("sensitive") == "true"
+}
+
+// sensitiveFieldPaths returns the JSON paths of the sensitive fields of t
+func sensitiveFieldPaths(t reflect.Type, prefix string) []string {
+	var paths []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		path := prefix + jsonName(field)
+		switch {
+		case field.Type.Kind() == reflect.Struct:
+			paths = append(paths, sensitiveFieldPaths(field.Type, path+".")...)
+		case isSensitive(field):
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// maskSensitive masks the sensitive fields of the struct value, which must
+// be settable. Strings that are set are replaced and maps get a copy with
+// every value replaced, keeping their keys.
+func maskSensitive(value reflect.Value) {
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Field(i)
+		if field.Kind() == reflect.Struct {
+			maskSensitive(field)
+			continue
+		}
+		if !isSensitive(value.Type().Field(i)) || field.IsZero() {
+			continue
+		}
+
+		switch field.Kind() {
+		case reflect.String:
+			field.SetString(maskedSecret)
+		case reflect.Map:
+			masked := reflect.MakeMapWithSize(field.Type(), field.Len())
+			for _, key := range field.MapKeys() {
+				masked.SetMapIndex(key, reflect.ValueOf(maskedSecret).Convert(field.Type().Elem()))
+			}
+			field.Set(masked)
+		}
+	}
+}
+
+// sensitiveValues returns the non-empty secret strings held by the
+// sensitive fields of the struct value, including map values
+func sensitiveValues(value reflect.Value) []string {
+	var secrets []string
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Field(i)
+		if field.Kind() == reflect.Struct {
+			secrets = append(secrets, sensitiveValues(field)...)
+			continue
+		}
+		if !isSensitive(value.Type().Field(i)) {
+			continue
+		}
+
+		switch field.Kind() {
+		case reflect.String:
+			if field.String() != "" {
+				secrets = append(secrets, field.String())
+			}
+		case reflect.Map:
+			for _, key := range field.MapKeys() {
+				if secret := field.MapIndex(key).String(); secret != "" {
+					secrets = append(secrets, secret)
+				}
+			}
+		}
+	}
+	return secrets
+}
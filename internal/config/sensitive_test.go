@@ -0,0 +1,77 @@
+package config
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+)
+
+// taggedConfig stands in for a config struct gaining a new sensitive field
+type taggedConfig struct {
+	Plugin struct {
+		Name    string            `json:"name"`
+		Token   string            `json:"token" sensitive:"true"`
+		Unset   string            `json:"unset" sensitive:"true"`
+		Headers map[string]string `json:"headers" sensitive:"true"`
+	} `json:"plugin"`
+}
+
+func TestMaskSensitiveTaggedField(t *testing.T) {
+	var cfg taggedConfig
+	cfg.Plugin.Name = "plugin"
+	cfg.Plugin.Token = "plugin-token"
+	cfg.Plugin.Headers = map[string]string{"X-Key": "header-secret"}
+
+	if got := sensitiveValues(reflect.ValueOf(cfg)); !slices.Equal(got, []string{"plugin-token", "header-secret"}) {
+		t.Errorf("sensitiveValues = %q, want the token and header value", got)
+	}
+	if got := sensitiveFieldPaths(reflect.TypeOf(cfg), ""); !slices.Equal(got, []string{"plugin.token", "plugin.unset", "plugin.headers"}) {
+		t.Errorf("sensitiveFieldPaths = %q", got)
+	}
+
+	masked := cfg
+	maskSensitive(reflect.ValueOf(&masked).Elem())
+	if masked.Plugin.Token != maskedSecret || masked.Plugin.Headers["X-Key"] != maskedSecret {
+		t.Errorf("masked = %+v, want the token and header masked", masked.Plugin)
+	}
+	if masked.Plugin.Name != "plugin" || masked.Plugin.Unset != "" {
+		t.Errorf("masked = %+v, want the name kept and the unset secret left empty", masked.Plugin)
+	}
+	if cfg.Plugin.Headers["X-Key"] != "header-secret" {
+		t.Error("masking modified the headers of the original")
+	}
+}
+
+func TestSensitivePathsFromTags(t *testing.T) {
+	want := []string{
+		"api.defaultHeaders",
+		"api.signingSecret",
+		"auth.clientSecret",
+		"auth.apiKey",
+		"database.password",
+		"security.csrfSecret",
+	}
+	if !slices.Equal(sensitivePaths, want) {
+		t.Errorf("sensitivePaths = %q, want %q", sensitivePaths, want)
+	}
+}
+
+func TestSanitizeConfigMasksTaggedFields(t *testing.T) {
+	cfg := &Config{
+		API:      APIConfig{BaseURL: "https://api.example.com", SigningSecret: "signing", DefaultHeaders: map[string]string{"X-Api-Key": "key"}},
+		Database: DatabaseConfig{Password: "db-secret"},
+		Security: SecurityConfig{CSRFSecret: "csrf-secret"},
+	}
+
+	sanitized := NewSecurityValidator(cfg).SanitizeConfig()
+	if sanitized.Database.Password != maskedSecret || sanitized.Security.CSRFSecret != maskedSecret ||
+		sanitized.API.SigningSecret != maskedSecret || sanitized.API.DefaultHeaders["X-Api-Key"] != maskedSecret {
+		t.Errorf("sanitized = %+v, want every secret masked", sanitized)
+	}
+	if sanitized.API.BaseURL != cfg.API.BaseURL || sanitized.Auth.APIKey != "" {
+		t.Errorf("sanitized changed values that aren't set secrets")
+	}
+	if cfg.API.DefaultHeaders["X-Api-Key"] != "key" {
+		t.Error("SanitizeConfig modified the headers of the original")
+	}
+}
@@ -0,0 +1,234 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/ini.v1"
+)
+
+const (
+	// sourceStdin is the source spec that reads the configuration from
+	// standard input
+	sourceStdin = "-"
+
+	// defaultSourceTimeout bounds reading the configuration from standard
+	// input or a URL unless CONFIG_SOURCE_TIMEOUT is set
+	defaultSourceTimeout = 10 * time.Second
+
+	// defaultMaxSourceBytes is the largest configuration accepted unless
+	// CONFIG_SOURCE_MAX_BYTES is set
+	defaultMaxSourceBytes = 1 << 20
+)
+
+var (
+	// ErrURLSourceDisabled is returned for URL sources unless they were
+	// enabled with CONFIG_SOURCE_ALLOW_URL
+	ErrURLSourceDisabled = errors.New("loading configuration from a URL is disabled, set CONFIG_SOURCE_ALLOW_URL=true to enable it")
+	// ErrSourceTooLarge is returned for a configuration larger than the
+	// size limit
+	ErrSourceTooLarge = errors.New("configuration is too large")
+	// ErrSourceTimeout is returned when reading the configuration from
+	// standard input or a URL takes longer than the source timeout
+	ErrSourceTimeout = errors.New("timed out reading the configuration")
+)
+
+// urlSourceContentTypes lists the content types accepted from URL sources
+var urlSourceContentTypes = []string{"text/plain", "text/x-ini", "application/x-ini"}
+
+var (
+	// stdin is read for the "-" source
+	stdin io.Reader = os.Stdin
+
+	// stdinResult is the read of stdin, started by the first load from it
+	stdinMu     sync.Mutex
+	stdinResult *stdinRead
+)
+
+// ConfigSource returns the source LoadConfig reads, set with CONFIG_SOURCE:
+// "-" for standard input, an http(s):// URL or a file path. It defaults to
+// ConfigFile.
+func ConfigSource() string {
+	if source := os.Getenv("CONFIG_SOURCE"); source != "" {
+		return source
+	}
+	return ConfigFile
+}
+
+// SourceFile returns the path of the configuration file, reporting false
+// when the configuration is read from standard input or a URL
+func SourceFile() (string, bool) {
+	source := ConfigSource()
+	if source == sourceStdin || isURLSource(source) {
+		return "", false
+	}
+	return source, true
+}
+
+// urlSourceAllowed reports whether URL sources were enabled with
+// CONFIG_SOURCE_ALLOW_URL. They are opt-in so a stray variable can't make
+// the app fetch its configuration from an arbitrary host.
+func urlSourceAllowed() bool {
+	allowed, _ := strconv.ParseBool(os.Getenv("CONFIG_SOURCE_ALLOW_URL"))
+	return allowed
+}
+
+// maxSourceBytes returns the size limit of the configuration, set in bytes
+// with CONFIG_SOURCE_MAX_BYTES
+func maxSourceBytes() int64 {
+	if limit, err := strconv.ParseInt(os.Getenv("CONFIG_SOURCE_MAX_BYTES"), 10, 64); err == nil && limit > 0 {
+		return limit
+	}
+	return defaultMaxSourceBytes
+}
+
+// sourceTimeout returns the time allowed for reading the configuration
+// from standard input or a URL, set with CONFIG_SOURCE_TIMEOUT in seconds
+// or as a duration such as 30s
+func sourceTimeout() time.Duration {
+	value := os.Getenv("CONFIG_SOURCE_TIMEOUT")
+	if timeout, err := time.ParseDuration(value); err == nil && timeout > 0 {
+		return timeout
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	return defaultSourceTimeout
+}
+
+// tooLarge returns the error for a configuration over the size limit
+func tooLarge(limit int64) error {
+	return fmt.Errorf("%w: the limit is %d bytes, see CONFIG_SOURCE_MAX_BYTES", ErrSourceTooLarge, limit)
+}
+
+func isURLSource(source string) bool {
+	return strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://")
+}
+
+// loadSource parses the configuration from the given source spec. Sources
+// larger than the size limit are rejected before they are parsed.
+func loadSource(source string) (*ini.File, error) {
+	switch {
+	case source == sourceStdin:
+		data, err := readStdin()
+		if err != nil {
+			return nil, err
+		}
+		return ini.Load(data)
+	case isURLSource(source):
+		if !urlSourceAllowed() {
+			return nil, ErrURLSourceDisabled
+		}
+		data, err := fetchSource(source)
+		if err != nil {
+			return nil, err
+		}
+		return ini.Load(data)
+	default:
+		if info, err := os.Stat(source); err == nil && info.Size() > maxSourceBytes() {
+			return nil, fmt.Errorf("%s: %w", source, tooLarge(maxSourceBytes()))
+		}
+		return ini.Load(source)
+	}
+}
+
+// stdinRead is the single read of standard input shared by every load,
+// since stdin can only be read once. done is closed once data or err is
+// set.
+type stdinRead struct {
+	done chan struct{}
+	data []byte
+	err  error
+}
+
+// readStdin returns the configuration read from standard input within the
+// size limit. The input is read once in the background and its outcome,
+// the configuration or the error, is reused by later loads. A load gives
+// up after the source timeout without abandoning the read, which stays
+// blocked since stdin can't be interrupted; a later load waits for the
+// same read instead of starting another.
+func readStdin() ([]byte, error) {
+	stdinMu.Lock()
+	read := stdinResult
+	if read == nil {
+		read = &stdinRead{done: make(chan struct{})}
+		stdinResult = read
+		r, limit := stdin, maxSourceBytes()
+		go func() {
+			defer close(read.done)
+			data, err := io.ReadAll(io.LimitReader(r, limit+1))
+			switch {
+			case err != nil:
+				read.err = fmt.Errorf("failed to read configuration from stdin: %w", err)
+			case int64(len(data)) > limit:
+				read.err = tooLarge(limit)
+			default:
+				read.data = data
+			}
+		}()
+	}
+	stdinMu.Unlock()
+
+	timeout := sourceTimeout()
+	select {
+	case <-read.done:
+		return read.data, read.err
+	case <-time.After(timeout):
+		return nil, fmt.Errorf("%w from stdin after %v, see CONFIG_SOURCE_TIMEOUT", ErrSourceTimeout, timeout)
+	}
+}
+
+// fetchSource downloads the configuration from url within the source
+// timeout. The response must be a plain text or INI document within the
+// size limit. Nothing is cached, every load fetches the configuration
+// again.
+func fetchSource(url string) ([]byte, error) {
+	timeout := sourceTimeout()
+	client := &http.Client{Timeout: timeout}
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, fetchError(url, timeout, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch configuration: %s", resp.Status)
+	}
+	mediaType, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if err != nil || !slices.Contains(urlSourceContentTypes, mediaType) {
+		return nil, fmt.Errorf("unexpected configuration content type %q", resp.Header.Get("Content-Type"))
+	}
+	limit := maxSourceBytes()
+	if resp.ContentLength > limit {
+		return nil, tooLarge(limit)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, limit+1))
+	if err != nil {
+		return nil, fetchError(url, timeout, err)
+	}
+	if int64(len(data)) > limit {
+		return nil, tooLarge(limit)
+	}
+	return data, nil
+}
+
+// fetchError wraps an error fetching the configuration from url, as
+// ErrSourceTimeout when timeout ran out
+func fetchError(url string, timeout time.Duration, err error) error {
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return fmt.Errorf("%w from %s after %v, see CONFIG_SOURCE_TIMEOUT", ErrSourceTimeout, url, timeout)
+	}
+	return fmt.Errorf("failed to fetch configuration: %w", err)
+}
@@ -0,0 +1,272 @@
+package config
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// repoConfigData returns the contents of the repository config.ini with
+// app.name replaced by name
+func repoConfigData(t *testing.T, name string) string {
+	t.Helper()
+
+	data, err := os.ReadFile("../../config.ini")
+	if err != nil {
+		t.Fatal(err)
+	}
+	file, err := loadSource("../../config.ini")
+	if err != nil {
+		t.Fatal(err)
+	}
+	old := "name = " + file.Section("app").Key("name").String()
+	return strings.Replace(string(data), old, "name = "+name, 1)
+}
+
+// reloadFromSource reloads the configuration from source in a temporary
+// working directory
+func reloadFromSource(t *testing.T, source string) (*Config, error) {
+	t.Helper()
+
+	t.Setenv("CONFIG_SOURCE", source)
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	return ReloadConfig()
+}
+
+func TestLoadConfigFromStdin(t *testing.T) {
+	oldStdin := stdin
+	t.Cleanup(func() {
+		stdin = oldStdin
+		stdinResult = nil
+	})
+	stdin = strings.NewReader(repoConfigData(t, "Stdin App"))
+	stdinResult = nil
+
+	// Reloading reuses the configuration, stdin can only be read once
+	for range 2 {
+		cfg, err := reloadFromSource(t, "-")
+		if err != nil {
+			t.Fatalf("ReloadConfig: %v", err)
+		}
+		if cfg.App.Name != "Stdin App" {
+			t.Errorf("App.Name = %q, want Stdin App", cfg.App.Name)
+		}
+	}
+}
+
+func TestLoadConfigFromURL(t *testing.T) {
+	data := repoConfigData(t, "Remote App")
+	var hits int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		io.WriteString(w, data)
+	}))
+	defer srv.Close()
+	t.Setenv("CONFIG_SOURCE_ALLOW_URL", "true")
+
+	// Nothing is cached, every load fetches the configuration
+	for range 2 {
+		cfg, err := reloadFromSource(t, srv.URL+"/config.ini")
+		if err != nil {
+			t.Fatalf("ReloadConfig: %v", err)
+		}
+		if cfg.App.Name != "Remote App" {
+			t.Errorf("App.Name = %q, want Remote App", cfg.App.Name)
+		}
+	}
+	if hits != 2 {
+		t.Errorf("server hit %d times, want 2", hits)
+	}
+}
+
+func TestLoadConfigFromURLDisabled(t *testing.T) {
+	var hits int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+	}))
+	defer srv.Close()
+
+	if _, err := reloadFromSource(t, srv.URL); !errors.Is(err, ErrURLSourceDisabled) {
+		t.Errorf("ReloadConfig error = %v, want ErrURLSourceDisabled", err)
+	}
+	if hits != 0 {
+		t.Errorf("server hit %d times, want 0", hits)
+	}
+}
+
+func TestFetchSourceRejects(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		wantErr string
+	}{
+		{
+			name: "content type",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "text/html")
+				io.WriteString(w, "<html></html>")
+			},
+			wantErr: "content type",
+		},
+		{
+			name: "status",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				http.Error(w, "missing", http.StatusNotFound)
+			},
+			wantErr: "404",
+		},
+		{
+			name: "size",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "text/plain")
+				io.WriteString(w, strings.Repeat("#", defaultMaxSourceBytes+1))
+			},
+			wantErr: "too large",
+		},
+		{
+			name: "size without content length",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "text/plain")
+				w.(http.Flusher).Flush()
+				io.WriteString(w, strings.Repeat("#", defaultMaxSourceBytes+1))
+			},
+			wantErr: "too large",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(tt.handler)
+			defer srv.Close()
+
+			if _, err := fetchSource(srv.URL); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("fetchSource error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSourceFile(t *testing.T) {
+	tests := []struct {
+		source string
+		want   string
+		wantOK bool
+	}{
+		{"", ConfigFile, true},
+		{"conf/app.ini", "conf/app.ini", true},
+		{"-", "", false},
+		{"https://config.example.com/app.ini", "", false},
+	}
+	for _, tt := range tests {
+		t.Setenv("CONFIG_SOURCE", tt.source)
+		if got, ok := SourceFile(); got != tt.want || ok != tt.wantOK {
+			t.Errorf("SourceFile() with CONFIG_SOURCE=%q = %q, %v, want %q, %v", tt.source, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestLoadSourceRejectsOversizedFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "huge.ini")
+	if err := os.WriteFile(path, []byte("[app]\nname = "+strings.Repeat("x", 2048)+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("CONFIG_SOURCE_MAX_BYTES", "1024")
+	if _, err := loadSource(path); !errors.Is(err, ErrSourceTooLarge) {
+		t.Errorf("loadSource error = %v, want ErrSourceTooLarge", err)
+	}
+	t.Setenv("CONFIG_SOURCE_MAX_BYTES", "4096")
+	if _, err := loadSource(path); err != nil {
+		t.Errorf("loadSource within the limit: %v", err)
+	}
+}
+
+// slowReader returns no data until release is closed, then data. reads
+// counts the calls of Read.
+type slowReader struct {
+	release chan struct{}
+	data    *strings.Reader
+	reads   *atomic.Int32
+}
+
+func (r slowReader) Read(p []byte) (int, error) {
+	r.reads.Add(1)
+	<-r.release
+	return r.data.Read(p)
+}
+
+func TestReadStdinGuards(t *testing.T) {
+	oldStdin := stdin
+	t.Cleanup(func() {
+		stdin = oldStdin
+		stdinResult = nil
+	})
+
+	release := make(chan struct{})
+	var reads atomic.Int32
+	stdin = slowReader{release: release, data: strings.NewReader("[app]\nname = Late\n"), reads: &reads}
+	stdinResult = nil
+	t.Setenv("CONFIG_SOURCE_TIMEOUT", "50ms")
+
+	// Loads that time out wait for the same read rather than starting
+	// another reader of stdin
+	for range 2 {
+		start := time.Now()
+		if _, err := readStdin(); !errors.Is(err, ErrSourceTimeout) {
+			t.Errorf("readStdin from a slow source = %v, want ErrSourceTimeout", err)
+		}
+		if elapsed := time.Since(start); elapsed > 2*time.Second {
+			t.Errorf("readStdin took %v, want it to give up after the timeout", elapsed)
+		}
+	}
+	if n := reads.Load(); n != 1 {
+		t.Errorf("stdin read by %d readers, want 1", n)
+	}
+
+	// Once the input arrives later loads get it
+	close(release)
+	t.Setenv("CONFIG_SOURCE_TIMEOUT", "5s")
+	if data, err := readStdin(); err != nil || !strings.Contains(string(data), "Late") {
+		t.Errorf("readStdin after the input arrived = %q, %v", data, err)
+	}
+
+	// Errors are kept too
+	stdin = strings.NewReader(strings.Repeat("#", 2048))
+	stdinResult = nil
+	t.Setenv("CONFIG_SOURCE_MAX_BYTES", "1024")
+	for range 2 {
+		if _, err := readStdin(); !errors.Is(err, ErrSourceTooLarge) {
+			t.Errorf("readStdin of an oversized source = %v, want ErrSourceTooLarge", err)
+		}
+	}
+}
+
+func TestFetchSourceTimeout(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	defer srv.Close()
+
+	t.Setenv("CONFIG_SOURCE_TIMEOUT", "50ms")
+	if _, err := fetchSource(srv.URL); !errors.Is(err, ErrSourceTimeout) {
+		t.Errorf("fetchSource from a slow server = %v, want ErrSourceTimeout", err)
+	}
+}
@@ -29,13 +29,29 @@ const (
 	LogFormatText LogFormat = "text"
 )
 
-// LogOutput represents where logs should be written
+// LogOutput represents where logs should be written. It is a single output
+// or a comma-separated list such as "console,file".
 type LogOutput string
 
 const (
 	LogOutputConsole LogOutput = "console"
 	LogOutputFile    LogOutput = "file"
-	LogOutputBoth    LogOutput = "both"
+	LogOutputBoth    LogOutput = "both" // console and file
+)
+
+// JitterStrategy selects how retry delays are randomized
+type JitterStrategy string
+
+const (
+	JitterNone  JitterStrategy = "none"  // the configured delay
+	JitterFull  JitterStrategy = "full"  // between zero and the delay
+	JitterEqual JitterStrategy = "equal" // between half the delay and the delay
+)
+
+// Authentication strategies selectable with [auth] strategy
+const (
+	AuthStrategyPassword = "password" // username and password login against the API
+	AuthStrategyAPIKey   = "api_key"  // a static API key, no login request
 )
 
 // Config represents the complete application configuration
@@ -48,62 +64,116 @@ type Config struct {
 	Security SecurityConfig `json:"security"`
 	Window   WindowConfig   `json:"window"`
 	Cache    CacheConfig    `json:"cache"`
+	TLS      TLSConfig      `json:"tls"`
 }
 
 // AppConfig contains application-level configuration
 type AppConfig struct {
-	Environment Environment `json:"environment" validate:"required,oneof=development staging production"`
-	Name        string      `json:"name" validate:"required,min=1,max=100"`
-	Version     string      `json:"version" validate:"required,semver"`
-	Debug       bool        `json:"debug"`
-	HotReload   bool        `json:"hotReload"`
-	DevTools    bool        `json:"devTools"`
-	MockAPI     bool        `json:"mockApi"`
+	Environment       Environment   `json:"environment" validate:"required,oneof=development staging production"`
+	Name              string        `json:"name" validate:"required,min=1,max=100"`
+	Version           string        `json:"version" validate:"required,semver"`
+	Debug             bool          `json:"debug"`
+	HotReload         bool          `json:"hotReload"`
+	DevTools          bool          `json:"devTools"`
+	MockAPI           bool          `json:"mockApi"`
+	FailFast          bool          `json:"failFast"`
+	SelfTestAPI       bool          `json:"selfTestApi"`
+	SelfTestDatabase  bool          `json:"selfTestDatabase"`
+	StartupTimeout    time.Duration `json:"startupTimeout" validate:"min=0"` // budget of the API and database checks, 0 = none
+	ReadOnly          bool          `json:"readOnly"`                        // the app never writes the config file
+	RecoverPanics     bool          `json:"recoverPanics"`
+	PushInitialConfig bool          `json:"pushInitialConfig"`                  // emit config:initial once the frontend is ready
+	WatchInterval     time.Duration `json:"watchInterval" validate:"min=0"`     // config file polling, 0 = disabled
+	ThemePollInterval time.Duration `json:"themePollInterval" validate:"min=0"` // OS theme polling, 0 = disabled
+	Locale            string        `json:"locale" validate:"required,bcp47_language_tag"`
+	MessagesFile      string        `json:"messagesFile"` // JSON message catalog, empty = [messages] sections only
+	StrictConfig      bool          `json:"strictConfig"` // fail on unknown sections and keys instead of warning
 }
 
 // APIConfig contains API-related configuration
 type APIConfig struct {
-	BaseURL     string        `json:"baseUrl" validate:"required,url"`
-	Timeout     time.Duration `json:"timeout" validate:"required"`
-	RetryCount  int           `json:"retryCount" validate:"min=0,max=10"`
-	RetryDelay  time.Duration `json:"retryDelay"`
-	UserAgent   string        `json:"userAgent"`
-	MaxIdleConn int           `json:"maxIdleConn" validate:"min=1,max=100"`
+	BaseURL              string            `json:"baseUrl" validate:"required,url"`
+	BaseURLs             []string          `json:"baseUrls" validate:"dive,endpoint"` // weighted endpoints balancing the requests to BaseURL, empty = BaseURL only
+	EndpointCooldown     time.Duration     `json:"endpointCooldown" validate:"min=0"` // how long an endpoint refusing connections is tried last
+	Timeout              time.Duration     `json:"timeout" validate:"required"`
+	RetryCount           int               `json:"retryCount" validate:"min=0,max=10"`
+	RetryDelay           time.Duration     `json:"retryDelay"`
+	JitterStrategy       JitterStrategy    `json:"jitterStrategy" validate:"omitempty,oneof=none full equal"` // empty = full
+	UserAgent            string            `json:"userAgent"`
+	MaxIdleConn          int               `json:"maxIdleConn" validate:"min=1,max=100"`
+	IdleConnTimeout      time.Duration     `json:"idleConnTimeout" validate:"min=0"`                              // 0 = idle connections are kept
+	KeepAlive            time.Duration     `json:"keepAlive" validate:"min=0"`                                    // TCP keep-alive probe interval, 0 = disabled
+	MaxConcurrent        int               `json:"maxConcurrentRequests" validate:"min=0,max=1000"`               // 0 = unlimited
+	MaxResponseBytes     int64             `json:"maxResponseBytes" validate:"min=0"`                             // bytes, 0 = unlimited
+	DownloadTimeout      time.Duration     `json:"downloadTimeout" validate:"min=0"`                              // 0 = unlimited
+	DNSCacheTTL          time.Duration     `json:"dnsCacheTtl" validate:"min=0"`                                  // 0 = disabled
+	DefaultPageSize      int               `json:"defaultPageSize" validate:"min=1,ltefield=MaxPageSize"`         // RequestPaged without a page size
+	MaxPageSize          int               `json:"maxPageSize" validate:"min=1"`                                  // larger page sizes are clamped
+	TenantURLTemplate    string            `json:"tenantUrlTemplate" validate:"omitempty,contains={tenant}"`      // e.g. https://{tenant}.api.example.com
+	MinVersion           string            `json:"minVersion" validate:"omitempty,semver"`                        // minimum supported API version
+	DefaultHeaders       map[string]string `json:"defaultHeaders" sensitive:"true"`                               // sent with every request
+	ValidateResponses    bool              `json:"validateResponses"`                                             // ignored in production
+	SigningEnabled       bool              `json:"signingEnabled"`                                                // sign requests with an HMAC
+	SigningSecret        string            `json:"signingSecret" sensitive:"true"`                                // HMAC key of request signatures
+	SigningHeader        string            `json:"signingHeader" validate:"omitempty,http_token"`                 // header carrying the signature, empty = X-Signature
+	CompressRequests     bool              `json:"compressRequests"`                                              // gzip large request bodies
+	CompressMinBytes     int               `json:"compressMinBytes" validate:"min=0"`                             // bodies up to this size are sent as is
+	ForceHTTP1           bool              `json:"forceHttp1"`                                                    // never negotiate HTTP/2
+	AllowHTTP2Cleartext  bool              `json:"allowHttp2Cleartext"`                                           // HTTP/2 without TLS (h2c) for http:// URLs
+	HealthPath           string            `json:"healthPath" validate:"omitempty,relative_path"`                 // empty = any response from the base URL is healthy
+	HealthExpectedStatus []int             `json:"healthExpectedStatus" validate:"required,dive,min=100,max=599"` // statuses of a healthy health_path
+	FollowRedirects      bool              `json:"followRedirects"`                                               // false = return redirect responses as is
+	MaxRedirects         int               `json:"maxRedirects" validate:"min=0,max=20"`
+	VerifyBaseURLOnStart bool              `json:"verifyBaseUrlOnStart"`                         // resolve and connect to the base URL host in the self-test
+	RecordTo             string            `json:"recordTo"`                                     // JSON lines file of API exchanges, empty = disabled
+	ReplayFrom           string            `json:"replayFrom" validate:"excluded_with=RecordTo"` // serve responses from a recording, empty = disabled
 }
 
 // AuthConfig contains authentication configuration
 type AuthConfig struct {
+	Strategy           string        `json:"strategy" validate:"omitempty,oneof=password api_key"` // empty = password
 	TokenExpiry        time.Duration `json:"tokenExpiry" validate:"required,min=300s,max=86400s"`
 	RefreshThreshold   time.Duration `json:"refreshThreshold" validate:"required,min=60s,max=3600s"`
+	ClockSkewTolerance time.Duration `json:"clockSkewTolerance" validate:"min=0,ltfield=RefreshThreshold"` // subtracted from token expiry
 	MaxLoginAttempts   int           `json:"maxLoginAttempts" validate:"min=1,max=10"`
+	MinPasswordLength  int           `json:"minPasswordLength" validate:"min=0,max=128"` // 0 = only empty passwords are rejected
 	LockoutDuration    time.Duration `json:"lockoutDuration" validate:"min=1m,max=24h"`
 	SessionTimeout     time.Duration `json:"sessionTimeout" validate:"min=5m,max=24h"`
 	RememberMeDuration time.Duration `json:"rememberMeDuration" validate:"min=1h,max=720h"`
+	ClientID           string        `json:"clientId"`
+	ClientSecret       string        `json:"clientSecret" sensitive:"true"`
+	APIKey             string        `json:"apiKey" sensitive:"true"`                    // sent as the access token by the api_key strategy
+	HeaderName         string        `json:"headerName" validate:"omitempty,http_token"` // header carrying the access token, empty = Authorization
+	Scheme             string        `json:"scheme" validate:"omitempty,http_token"`     // used when the login reports no token type, empty = Bearer
 }
 
 // LogConfig contains logging configuration
 type LogConfig struct {
-	Level      LogLevel  `json:"level" validate:"required,oneof=debug info warn error"`
-	Format     LogFormat `json:"format" validate:"required,oneof=json text"`
-	Output     LogOutput `json:"output" validate:"required,oneof=console file both"`
-	FilePath   string    `json:"filePath"`
-	MaxSize    int       `json:"maxSize" validate:"min=1,max=1000"`   // MB
-	MaxBackups int       `json:"maxBackups" validate:"min=0,max=100"` // files
-	MaxAge     int       `json:"maxAge" validate:"min=1,max=365"`     // days
-	Compress   bool      `json:"compress"`
+	Level            LogLevel  `json:"level" validate:"required,oneof=debug info warn error"`
+	Format           LogFormat `json:"format" validate:"required,oneof=json text"`
+	Output           LogOutput `json:"output" validate:"required,log_outputs"`
+	FilePath         string    `json:"filePath"`
+	MaxSize          int       `json:"maxSize" validate:"min=1,max=1000"`   // MB
+	MaxBackups       int       `json:"maxBackups" validate:"min=0,max=100"` // files
+	MaxAge           int       `json:"maxAge" validate:"min=1,max=365"`     // days
+	Compress         bool      `json:"compress"`
+	MemoryBufferSize int       `json:"memoryBufferSize" validate:"min=0,max=10000"`     // records, 0 = disabled
+	AuditFile        string    `json:"auditFile" validate:"omitempty,nefield=FilePath"` // empty = disabled
+	RedactPatterns   []string  `json:"redactPatterns" validate:"dive,regexp"`           // matches are logged as ***
 }
 
 // DatabaseConfig contains database configuration
 type DatabaseConfig struct {
-	Host         string        `json:"host" validate:"required"`
-	Port         int           `json:"port" validate:"required,min=1,max=65535"`
-	Name         string        `json:"name" validate:"required,min=1,max=100"`
-	Username     string        `json:"username"`
-	Password     string        `json:"password"`
-	SSLMode      string        `json:"sslMode" validate:"oneof=disable require verify-ca verify-full"`
-	MaxOpenConns int           `json:"maxOpenConns" validate:"min=1,max=100"`
-	MaxIdleConns int           `json:"maxIdleConns" validate:"min=1,max=100"`
-	ConnLifetime time.Duration `json:"connLifetime" validate:"min=1m,max=24h"`
+	Host            string        `json:"host" validate:"required"`
+	Port            int           `json:"port" validate:"required,min=1,max=65535"`
+	Name            string        `json:"name" validate:"required,min=1,max=100"`
+	Username        string        `json:"username"`
+	Password        string        `json:"password" sensitive:"true"`
+	SSLMode         string        `json:"sslMode" validate:"oneof=disable require verify-ca verify-full"`
+	MaxOpenConns    int           `json:"maxOpenConns" validate:"min=1,max=100"`
+	MaxIdleConns    int           `json:"maxIdleConns" validate:"min=1,max=100"`
+	ConnLifetime    time.Duration `json:"connLifetime" validate:"min=1m,max=24h"`
+	ConnMaxIdleTime time.Duration `json:"connMaxIdleTime" validate:"min=0,max=24h"` // 0 = idle connections are kept
 }
 
 // SecurityConfig contains security-related configuration
@@ -113,8 +183,10 @@ type SecurityConfig struct {
 	RateLimitEnabled bool     `json:"rateLimitEnabled"`
 	RateLimitRPS     int      `json:"rateLimitRps" validate:"min=1,max=10000"`
 	RateLimitBurst   int      `json:"rateLimitBurst" validate:"min=1,max=1000"`
+	RateLimitGrace   int      `json:"rateLimitGrace" validate:"min=0,ltefield=RateLimitBurst"` // extra burst after inactivity
 	CSRFEnabled      bool     `json:"csrfEnabled"`
-	CSRFSecret       string   `json:"csrfSecret"`
+	CSRFSecret       string   `json:"csrfSecret" sensitive:"true"`
+	DisabledWarnings []string `json:"disabledWarnings"`
 }
 
 // WindowConfig contains window-specific configuration
@@ -126,6 +198,8 @@ type WindowConfig struct {
 	Maximized   bool `json:"maximized"`
 	Minimized   bool `json:"minimized"`
 	AlwaysOnTop bool `json:"alwaysOnTop"`
+	Center      bool `json:"center"`                   // center on Monitor at startup
+	Monitor     int  `json:"monitor" validate:"min=0"` // screen index, the primary screen when out of range
 }
 
 // CacheConfig contains caching configuration
@@ -135,9 +209,19 @@ type CacheConfig struct {
 	MaxSize            int           `json:"maxSize" validate:"min=1,max=10000"`      // MB
 	MaxItems           int           `json:"maxItems" validate:"min=100,max=1000000"` // items
 	CompressionEnabled bool          `json:"compressionEnabled"`
+	ServeStaleOnError  bool          `json:"serveStaleOnError"`                  // answer with expired data when the API is unreachable
+	MaxStale           time.Duration `json:"maxStale" validate:"min=0,max=720h"` // how long expired data may be served
 	EvictionPolicy     string        `json:"evictionPolicy" validate:"oneof=lru lfu fifo"`
 }
 
+// TLSConfig contains TLS configuration for outbound connections
+type TLSConfig struct {
+	CACertPath     string `json:"caCertPath"`                                            // extra CA trusted on top of the system pool
+	ClientCertPath string `json:"clientCertPath" validate:"required_with=ClientKeyPath"` // client certificate (PEM) presented to servers that ask for one
+	ClientKeyPath  string `json:"clientKeyPath" validate:"required_with=ClientCertPath"`
+	MinVersion     string `json:"minVersion" validate:"omitempty,oneof=1.2 1.3"`
+}
+
 // PublicConfig represents configuration that can be safely exposed to frontend
 type PublicConfig struct {
 	App    PublicAppConfig    `json:"app"`
@@ -151,6 +235,7 @@ type PublicAppConfig struct {
 	Name        string      `json:"name"`
 	Version     string      `json:"version"`
 	Debug       bool        `json:"debug"`
+	Profile     string      `json:"profile,omitempty"` // active profile, empty for the base configuration
 }
 
 // PublicAPIConfig contains non-sensitive API configuration
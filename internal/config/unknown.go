@@ -0,0 +1,82 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"gopkg.in/ini.v1"
+)
+
+// ErrUnknownConfig is returned by LoadConfig in strict mode when the config
+// file has sections or keys the app doesn't read
+var ErrUnknownConfig = errors.New("unknown configuration sections or keys")
+
+// unrecordedKeys are the keys read outside the section loaders, which
+// Defaults doesn't record
+var unrecordedKeys = map[string][]string{
+	"app":     {"environment"},
+	"secrets": {"file"},
+}
+
+// unknownConfigEntries returns a warning for every section and key of file
+// that the app doesn't read, such as a misspelled key. Profile keys are
+// checked against the sections they override; [messages] sections may hold
+// any key.
+func unknownConfigEntries(file *ini.File) []ReportEntry {
+	_, known := defaults()
+	for section, keys := range unrecordedKeys {
+		for _, key := range keys {
+			if known[section] == nil {
+				known[section] = make(map[string]string)
+			}
+			known[section][key] = ""
+		}
+	}
+
+	var entries []ReportEntry
+	unknown := func(section, message string) {
+		entries = append(entries, ReportEntry{Severity: SeverityWarning, Section: section, Message: message})
+	}
+	for _, sec := range file.Sections() {
+		name := sec.Name()
+		if _, ok := messagesLocale(name); ok {
+			continue
+		}
+		if strings.HasPrefix(name, profileSectionPrefix) {
+			for _, key := range sec.Keys() {
+				section, sectionKey, _ := strings.Cut(key.Name(), ".")
+				if _, ok := known[section][sectionKey]; !ok {
+					unknown(name, fmt.Sprintf("unknown key %s in [%s]", key.Name(), name))
+				}
+			}
+			continue
+		}
+		if name == ini.DefaultSection {
+			for _, key := range sec.Keys() {
+				unknown(name, fmt.Sprintf("key %s is outside of any section", key.Name()))
+			}
+			continue
+		}
+		if _, ok := known[name]; !ok {
+			unknown(name, fmt.Sprintf("unknown section [%s]", name))
+			continue
+		}
+		for _, key := range sec.Keys() {
+			if _, ok := known[name][key.Name()]; !ok {
+				unknown(name, fmt.Sprintf("unknown key %s in [%s]", key.Name(), name))
+			}
+		}
+	}
+	return entries
+}
+
+// unknownConfigError joins the messages of unknown entries into an error
+// wrapping ErrUnknownConfig
+func unknownConfigError(entries []ReportEntry) error {
+	messages := make([]string, len(entries))
+	for i, entry := range entries {
+		messages[i] = entry.Message
+	}
+	return fmt.Errorf("%w: %s", ErrUnknownConfig, strings.Join(messages, "; "))
+}
@@ -0,0 +1,85 @@
+package config
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"gopkg.in/ini.v1"
+)
+
+func TestUnknownConfigEntries(t *testing.T) {
+	file, err := ini.Load([]byte(`stray = 1
+[window]
+width = 1200
+widht = 1200
+[windwo]
+height = 800
+[profile.local]
+api.base_url = http://localhost:8080
+api.timout = 5
+[messages]
+NOT_FOUND = Not found
+[messages.vi]
+NOT_FOUND = Khong tim thay
+[secrets]
+file = secrets.ini
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, entry := range unknownConfigEntries(file) {
+		if entry.Severity != SeverityWarning {
+			t.Errorf("%q: severity = %s, want warning", entry.Message, entry.Severity)
+		}
+		got = append(got, entry.Message)
+	}
+	want := []string{
+		"key stray is outside of any section",
+		"unknown key widht in [window]",
+		"unknown section [windwo]",
+		"unknown key api.timout in [profile.local]",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("unknown entries = %q, want %q", got, want)
+	}
+}
+
+func TestUnknownConfigEntriesRepoConfig(t *testing.T) {
+	file, err := loadSource("../../config.ini")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entries := unknownConfigEntries(file); len(entries) > 0 {
+		t.Errorf("config.ini has unknown entries: %v", entries)
+	}
+}
+
+func TestLoadConfigStrict(t *testing.T) {
+	typo := strings.Replace(repoConfigData(t, "Strict App"), "height = 800", "heigth = 800", 1)
+
+	cfg, err := reloadFromSource(t, writeConfigFile(t, "lenient.ini", typo))
+	if err != nil {
+		t.Fatalf("lenient ReloadConfig: %v", err)
+	}
+	if cfg.App.Name != "Strict App" {
+		t.Errorf("App.Name = %q, want Strict App", cfg.App.Name)
+	}
+	if !slices.ContainsFunc(loadWarnings, func(entry ReportEntry) bool {
+		return entry.Message == "unknown key heigth in [window]"
+	}) {
+		t.Errorf("warnings %v don't report the misspelled key", loadWarnings)
+	}
+
+	strict := strings.Replace(typo, "strict_config = false", "strict_config = true", 1)
+	_, err = reloadFromSource(t, writeConfigFile(t, "strict.ini", strict))
+	if !errors.Is(err, ErrUnknownConfig) {
+		t.Fatalf("strict ReloadConfig error = %v, want ErrUnknownConfig", err)
+	}
+	if !strings.Contains(err.Error(), "heigth") {
+		t.Errorf("error %q doesn't name the misspelled key", err)
+	}
+}
@@ -0,0 +1,93 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/ini.v1"
+)
+
+// ConfigFile is the configuration file read by LoadConfig
+const ConfigFile = "config.ini"
+
+// Values holds configuration values to write, keyed by section then key
+type Values map[string]map[string]string
+
+// WriteValues updates the given keys in the INI file at path. An existing
+// file is edited in place so comments, ordering and unrelated keys are
+// preserved; a missing file is created from scratch. The file is replaced
+// atomically.
+func WriteValues(path string, values Values) error {
+	file, perm, err := loadForWrite(path)
+	if err != nil {
+		return err
+	}
+
+	for section, keys := range values {
+		sec := file.Section(section)
+		for key, value := range keys {
+			sec.Key(key).SetValue(value)
+		}
+	}
+
+	return saveAtomic(file, path, perm)
+}
+
+// loadForWrite loads the INI file at path for editing, returning an empty
+// file when it doesn't exist yet
+func loadForWrite(path string) (*ini.File, fs.FileMode, error) {
+	info, err := os.Stat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return ini.Empty(), 0644, nil
+	}
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to stat %s: %w", path, err)
+	}
+
+	file, err := ini.Load(path)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to load %s: %w", path, err)
+	}
+	return file, info.Mode().Perm(), nil
+}
+
+// saveAtomic writes file to a temporary file next to path and renames it
+// into place so readers never observe a partially written config
+func saveAtomic(file *ini.File, path string, perm fs.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	var buf bytes.Buffer
+	if _, err := file.WriteTo(&buf); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to serialize %s: %w", path, err)
+	}
+
+	// ini.v1 writes "key = " for empty values, trim that trailing space
+	lines := strings.Split(buf.String(), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t")
+	}
+	if _, err := tmp.WriteString(strings.Join(lines, "\n")); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := os.Chmod(tmp.Name(), perm); err != nil {
+		return fmt.Errorf("failed to set permissions on %s: %w", path, err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriteValuesPreservesLayout(t *testing.T) {
+	const original = `# Application settings
+[app]
+# Display name
+name = Test App
+environment = development
+
+# API settings
+[api]
+base_url = https://api.example.com
+# Seconds or a duration
+timeout = 30s
+`
+	path := filepath.Join(t.TempDir(), "config.ini")
+	if err := os.WriteFile(path, []byte(original), 0640); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := WriteValues(path, Values{"api": {"timeout": "45s"}}); err != nil {
+		t.Fatalf("WriteValues: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := strings.Replace(original, "timeout = 30s", "timeout = 45s", 1)
+	if string(data) != want {
+		t.Errorf("file after WriteValues:\n%s\nwant:\n%s", data, want)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0640 {
+		t.Errorf("permissions = %v, want 0640 kept", info.Mode().Perm())
+	}
+}
+
+func TestWriteValuesCreatesFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.ini")
+
+	if err := WriteValues(path, Values{"security": {"csrf_secret": "abc"}}); err != nil {
+		t.Fatalf("WriteValues: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "[security]\ncsrf_secret = abc\n"; strings.TrimSpace(string(data)) != strings.TrimSpace(want) {
+		t.Errorf("created file = %q, want %q", data, want)
+	}
+
+	matches, _ := filepath.Glob(filepath.Join(filepath.Dir(path), "*.tmp"))
+	if len(matches) != 0 {
+		t.Errorf("temporary files left behind: %v", matches)
+	}
+}
@@ -0,0 +1,65 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+	"wails-template/internal/config"
+)
+
+// AuditEntry records one runtime change of the configuration
+type AuditEntry struct {
+	Time    time.Time       `json:"time"`
+	Action  string          `json:"action"`  // e.g. reload, reset, rotate-csrf-secret
+	Trigger string          `json:"trigger"` // what started the change, e.g. user, file-watcher
+	Changes []config.Change `json:"changes,omitempty"`
+	Error   string          `json:"error,omitempty"` // set when the change was rejected
+}
+
+// AuditLog appends config change records to a dedicated file as JSON
+// lines, separate from the application log. Records are never rewritten.
+type AuditLog struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// OpenAudit opens the audit file at path for appending, creating it and
+// its directory as needed
+func OpenAudit(path string) (*AuditLog, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create audit log directory: %w", err)
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	return &AuditLog{file: file}, nil
+}
+
+// Write appends entry to the audit file. It does nothing on a nil log, so
+// callers don't need to check whether auditing is enabled.
+func (l *AuditLog) Write(entry AuditEntry) error {
+	if l == nil {
+		return nil
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, err = l.file.Write(append(data, '\n'))
+	return err
+}
+
+// Close closes the audit file. It does nothing on a nil log.
+func (l *AuditLog) Close() error {
+	if l == nil {
+		return nil
+	}
+	return l.file.Close()
+}
@@ -0,0 +1,44 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAuditLogAppends(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "audit.log")
+	for _, action := range []string{"reload", "reset"} {
+		audit, err := OpenAudit(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := audit.Write(AuditEntry{Time: time.Now(), Action: action, Trigger: "user"}); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+		if err := audit.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[0], `"action":"reload"`) || !strings.Contains(lines[1], `"action":"reset"`) {
+		t.Errorf("audit file = %q, want both entries in order", lines)
+	}
+}
+
+func TestAuditLogNil(t *testing.T) {
+	var audit *AuditLog
+	if err := audit.Write(AuditEntry{Action: "reload"}); err != nil {
+		t.Errorf("Write on a nil log = %v", err)
+	}
+	if err := audit.Close(); err != nil {
+		t.Errorf("Close on a nil log = %v", err)
+	}
+}
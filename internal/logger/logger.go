@@ -0,0 +1,145 @@
+// Package logger builds the structured application logger from the log
+// configuration.
+package logger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"wails-template/internal/config"
+)
+
+// Logger is the application logger. Besides writing to the configured
+// console and file outputs it keeps recent records in memory.
+type Logger struct {
+	*slog.Logger
+	buffer  *RingBuffer
+	closers []io.Closer
+}
+
+// New creates a logger writing to the outputs selected by cfg. Matches of
+// the redaction patterns are replaced in every output.
+func New(cfg config.LogConfig) (*Logger, error) {
+	level, err := ParseLevel(string(cfg.Level))
+	if err != nil {
+		return nil, err
+	}
+	patterns, err := compilePatterns(cfg.RedactPatterns)
+	if err != nil {
+		return nil, err
+	}
+	opts := &slog.HandlerOptions{Level: level}
+
+	var writers []io.Writer
+	var closers []io.Closer
+	if cfg.Output.Has(config.LogOutputConsole) {
+		writers = append(writers, os.Stdout)
+	}
+	if cfg.Output.Has(config.LogOutputFile) {
+		if err := os.MkdirAll(filepath.Dir(cfg.FilePath), 0755); err != nil {
+			return nil, fmt.Errorf("failed to create log directory: %w", err)
+		}
+		file, err := os.OpenFile(cfg.FilePath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open log file: %w", err)
+		}
+		writers = append(writers, file)
+		closers = append(closers, file)
+	}
+
+	var handlers []slog.Handler
+	for _, w := range writers {
+		if cfg.Format == config.LogFormatText {
+			handlers = append(handlers, slog.NewTextHandler(w, opts))
+		} else {
+			handlers = append(handlers, slog.NewJSONHandler(w, opts))
+		}
+	}
+
+	var buffer *RingBuffer
+	if cfg.MemoryBufferSize > 0 {
+		buffer = NewRingBuffer(cfg.MemoryBufferSize)
+		handlers = append(handlers, buffer.Handler(level))
+	}
+
+	var handler slog.Handler = multiHandler(handlers)
+	if len(patterns) > 0 {
+		handler = &redactHandler{next: handler, patterns: patterns}
+	}
+
+	return &Logger{
+		Logger:  slog.New(handler),
+		buffer:  buffer,
+		closers: closers,
+	}, nil
+}
+
+// Recent returns the buffered records at or above minLevel, oldest first.
+// It returns nil when the memory buffer is disabled.
+func (l *Logger) Recent(minLevel slog.Level) []Record {
+	if l.buffer == nil {
+		return nil
+	}
+	return l.buffer.Records(minLevel)
+}
+
+// Close closes the log file, if any
+func (l *Logger) Close() error {
+	var errs []error
+	for _, c := range l.closers {
+		errs = append(errs, c.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// ParseLevel converts a configured log level such as "warn" into a slog level
+func ParseLevel(level string) (slog.Level, error) {
+	var l slog.Level
+	if err := l.UnmarshalText([]byte(strings.TrimSpace(level))); err != nil {
+		return slog.LevelDebug, fmt.Errorf("invalid log level %q", level)
+	}
+	return l, nil
+}
+
+// multiHandler passes records to each of its handlers
+type multiHandler []slog.Handler
+
+func (m multiHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, h := range m {
+		if h.Enabled(ctx, level) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m multiHandler) Handle(ctx context.Context, r slog.Record) error {
+	var errs []error
+	for _, h := range m {
+		if h.Enabled(ctx, r.Level) {
+			errs = append(errs, h.Handle(ctx, r.Clone()))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (m multiHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	next := make(multiHandler, len(m))
+	for i, h := range m {
+		next[i] = h.WithAttrs(attrs)
+	}
+	return next
+}
+
+func (m multiHandler) WithGroup(name string) slog.Handler {
+	next := make(multiHandler, len(m))
+	for i, h := range m {
+		next[i] = h.WithGroup(name)
+	}
+	return next
+}
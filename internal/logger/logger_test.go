@@ -0,0 +1,150 @@
+package logger
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"wails-template/internal/config"
+)
+
+func TestLoggerRecent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	log, err := New(config.LogConfig{
+		Level:            config.LogLevelInfo,
+		Format:           config.LogFormatText,
+		Output:           config.LogOutputFile,
+		FilePath:         path,
+		MemoryBufferSize: 2,
+	})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer log.Close()
+
+	log.Debug("below level")
+	log.Info("first")
+	log.Warn("second")
+	log.Error("third")
+
+	if got := fmt.Sprint(messages(log.Recent(slog.LevelDebug))); got != "[second third]" {
+		t.Errorf("Recent = %s, want the last two records", got)
+	}
+	if got := fmt.Sprint(messages(log.Recent(slog.LevelError))); got != "[third]" {
+		t.Errorf("Recent(error) = %s", got)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "msg=first") || strings.Contains(string(data), "below level") {
+		t.Errorf("log file = %q, want every record at or above info", data)
+	}
+}
+
+func TestLoggerWithoutBuffer(t *testing.T) {
+	log, err := New(config.LogConfig{
+		Level:    config.LogLevelInfo,
+		Output:   config.LogOutputFile,
+		FilePath: filepath.Join(t.TempDir(), "app.log"),
+	})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer log.Close()
+
+	log.Info("message")
+	if got := log.Recent(slog.LevelDebug); got != nil {
+		t.Errorf("Recent = %v, want nil with the buffer disabled", got)
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	for input, want := range map[string]slog.Level{
+		"debug":  slog.LevelDebug,
+		"info":   slog.LevelInfo,
+		" WARN ": slog.LevelWarn,
+		"error":  slog.LevelError,
+	} {
+		if got, err := ParseLevel(input); err != nil || got != want {
+			t.Errorf("ParseLevel(%q) = %v, %v, want %v", input, got, err, want)
+		}
+	}
+	for _, input := range []string{"", "verbose"} {
+		if got, err := ParseLevel(input); err == nil || got != slog.LevelDebug {
+			t.Errorf("ParseLevel(%q) = %v, %v, want an error and debug", input, got, err)
+		}
+	}
+}
+
+func TestLoggerOutputList(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	log, err := New(config.LogConfig{
+		Level:    config.LogLevelInfo,
+		Format:   config.LogFormatText,
+		Output:   "console,file",
+		FilePath: path,
+	})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer log.Close()
+
+	log.Info("fanned out")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "msg=\"fanned out\"") {
+		t.Errorf("log file = %q, want the record", data)
+	}
+}
+
+func TestLoggerRedactPatterns(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	log, err := New(config.LogConfig{
+		Level:            config.LogLevelInfo,
+		Format:           config.LogFormatJSON,
+		Output:           config.LogOutputFile,
+		FilePath:         path,
+		MemoryBufferSize: 10,
+		RedactPatterns:   []string{`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`, `tok_[0-9a-f]+`},
+	})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer log.Close()
+
+	log.With("owner", "ops@example.com").Info("login by jane@example.com",
+		"token", "tok_1a2b", "error", fmt.Errorf("refresh failed for tok_3c4d"), "attempt", 2, "path", "/items")
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{"jane@example.com", "ops@example.com", "tok_1a2b", "tok_3c4d"} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("log file contains %q: %s", secret, data)
+		}
+	}
+	for _, kept := range []string{`"msg":"login by ***"`, `"token":"***"`, `"error":"refresh failed for ***"`, `"attempt":2`, `"path":"/items"`} {
+		if !strings.Contains(string(data), kept) {
+			t.Errorf("log file lacks %s: %s", kept, data)
+		}
+	}
+
+	records := log.Recent(slog.LevelDebug)
+	if len(records) != 1 || records[0].Message != "login by ***" {
+		t.Errorf("Recent = %+v, want the redacted record", records)
+	}
+}
+
+func TestLoggerInvalidRedactPattern(t *testing.T) {
+	_, err := New(config.LogConfig{Level: config.LogLevelInfo, RedactPatterns: []string{"(unclosed"}})
+	if err == nil || !strings.Contains(err.Error(), "(unclosed") {
+		t.Errorf("New = %v, want an invalid pattern error", err)
+	}
+}
@@ -0,0 +1,88 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+)
+
+// redacted replaces the matches of redaction patterns
+const redacted = "***"
+
+// compilePatterns compiles the configured redaction patterns
+func compilePatterns(patterns []string) ([]*regexp.Regexp, error) {
+	compiled := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redaction pattern %q: %w", pattern, err)
+		}
+		compiled = append(compiled, re)
+	}
+	return compiled, nil
+}
+
+// redactHandler replaces matches of its patterns in the message and the
+// attribute values of records before passing them on
+type redactHandler struct {
+	next     slog.Handler
+	patterns []*regexp.Regexp
+}
+
+func (h *redactHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *redactHandler) Handle(ctx context.Context, r slog.Record) error {
+	redactedRecord := slog.NewRecord(r.Time, r.Level, h.redact(r.Message), r.PC)
+	r.Attrs(func(attr slog.Attr) bool {
+		redactedRecord.AddAttrs(h.redactAttr(attr))
+		return true
+	})
+	return h.next.Handle(ctx, redactedRecord)
+}
+
+func (h *redactHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redactedAttrs := make([]slog.Attr, len(attrs))
+	for i, attr := range attrs {
+		redactedAttrs[i] = h.redactAttr(attr)
+	}
+	return &redactHandler{next: h.next.WithAttrs(redactedAttrs), patterns: h.patterns}
+}
+
+func (h *redactHandler) WithGroup(name string) slog.Handler {
+	return &redactHandler{next: h.next.WithGroup(name), patterns: h.patterns}
+}
+
+// redactAttr redacts the value of attr. Strings, including the text of
+// errors and other values, are redacted; numbers, times and the like are
+// kept as they are.
+func (h *redactHandler) redactAttr(attr slog.Attr) slog.Attr {
+	value := attr.Value.Resolve()
+	switch value.Kind() {
+	case slog.KindString:
+		return slog.String(attr.Key, h.redact(value.String()))
+	case slog.KindGroup:
+		group := value.Group()
+		redactedGroup := make([]slog.Attr, len(group))
+		for i, member := range group {
+			redactedGroup[i] = h.redactAttr(member)
+		}
+		return slog.Attr{Key: attr.Key, Value: slog.GroupValue(redactedGroup...)}
+	case slog.KindAny:
+		text := value.String()
+		if redactedText := h.redact(text); redactedText != text {
+			return slog.String(attr.Key, redactedText)
+		}
+	}
+	return slog.Attr{Key: attr.Key, Value: value}
+}
+
+// redact replaces every match of the patterns in s
+func (h *redactHandler) redact(s string) string {
+	for _, re := range h.patterns {
+		s = re.ReplaceAllString(s, redacted)
+	}
+	return s
+}
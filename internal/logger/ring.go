@@ -0,0 +1,165 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Record is a log record kept in the memory buffer
+type Record struct {
+	Time    time.Time      `json:"time"`
+	Level   string         `json:"level"`
+	Message string         `json:"message"`
+	Attrs   map[string]any `json:"attrs,omitempty"`
+}
+
+// RingBuffer keeps the most recent log records, dropping the oldest once
+// it is full. It is safe for concurrent use.
+type RingBuffer struct {
+	mu      sync.Mutex
+	records []Record
+	levels  []slog.Level
+	next    int
+	full    bool
+}
+
+// NewRingBuffer creates a buffer holding up to capacity records
+func NewRingBuffer(capacity int) *RingBuffer {
+	capacity = max(capacity, 1)
+	return &RingBuffer{
+		records: make([]Record, capacity),
+		levels:  make([]slog.Level, capacity),
+	}
+}
+
+// Handler returns a slog handler that stores records at or above level in
+// the buffer
+func (b *RingBuffer) Handler(level slog.Leveler) slog.Handler {
+	return &ringHandler{buffer: b, level: level}
+}
+
+// Records returns the buffered records at or above minLevel, oldest first
+func (b *RingBuffer) Records(minLevel slog.Level) []Record {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	start, count := 0, b.next
+	if b.full {
+		start, count = b.next, len(b.records)
+	}
+
+	var records []Record
+	for i := range count {
+		idx := (start + i) % len(b.records)
+		if b.levels[idx] >= minLevel {
+			records = append(records, b.records[idx])
+		}
+	}
+	return records
+}
+
+func (b *RingBuffer) add(level slog.Level, record Record) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.records[b.next] = record
+	b.levels[b.next] = level
+	b.next = (b.next + 1) % len(b.records)
+	if b.next == 0 {
+		b.full = true
+	}
+}
+
+// ringHandler is the slog handler writing into a RingBuffer
+type ringHandler struct {
+	buffer *RingBuffer
+	level  slog.Leveler
+	attrs  []slog.Attr
+	groups []string
+}
+
+func (h *ringHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level.Level()
+}
+
+func (h *ringHandler) Handle(_ context.Context, r slog.Record) error {
+	record := Record{
+		Time:    r.Time,
+		Level:   r.Level.String(),
+		Message: r.Message,
+	}
+
+	if len(h.attrs) > 0 || r.NumAttrs() > 0 {
+		record.Attrs = make(map[string]any)
+		for _, attr := range h.attrs {
+			addAttr(record.Attrs, "", attr)
+		}
+		prefix := groupPrefix(h.groups)
+		r.Attrs(func(attr slog.Attr) bool {
+			addAttr(record.Attrs, prefix, attr)
+			return true
+		})
+	}
+
+	h.buffer.add(r.Level, record)
+	return nil
+}
+
+func (h *ringHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	next := *h
+	prefix := groupPrefix(h.groups)
+	next.attrs = append([]slog.Attr(nil), h.attrs...)
+	for _, attr := range attrs {
+		attr.Key = prefix + attr.Key
+		next.attrs = append(next.attrs, attr)
+	}
+	return &next
+}
+
+func (h *ringHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	next := *h
+	next.groups = append(append([]string(nil), h.groups...), name)
+	return &next
+}
+
+// groupPrefix joins group names into a dotted key prefix
+func groupPrefix(groups []string) string {
+	var prefix string
+	for _, group := range groups {
+		prefix += group + "."
+	}
+	return prefix
+}
+
+// addAttr flattens attr into attrs, using dotted keys for groups
+func addAttr(attrs map[string]any, prefix string, attr slog.Attr) {
+	value := attr.Value.Resolve()
+	if attr.Equal(slog.Attr{}) {
+		return
+	}
+
+	if value.Kind() == slog.KindGroup {
+		groupPrefix := prefix
+		if attr.Key != "" {
+			groupPrefix += attr.Key + "."
+		}
+		for _, child := range value.Group() {
+			addAttr(attrs, groupPrefix, child)
+		}
+		return
+	}
+
+	switch v := value.Any().(type) {
+	case error:
+		attrs[prefix+attr.Key] = v.Error()
+	case time.Duration:
+		attrs[prefix+attr.Key] = v.String()
+	default:
+		attrs[prefix+attr.Key] = v
+	}
+}
@@ -0,0 +1,113 @@
+package logger
+
+import (
+	"fmt"
+	"log/slog"
+	"sync"
+	"testing"
+)
+
+// messages returns the messages of records
+func messages(records []Record) []string {
+	var out []string
+	for _, r := range records {
+		out = append(out, r.Message)
+	}
+	return out
+}
+
+func TestRingBufferFiltering(t *testing.T) {
+	buffer := NewRingBuffer(10)
+	log := slog.New(buffer.Handler(slog.LevelDebug))
+
+	log.Debug("debug")
+	log.Info("info")
+	log.Warn("warn")
+	log.Error("error")
+
+	tests := []struct {
+		level slog.Level
+		want  string
+	}{
+		{slog.LevelDebug, "[debug info warn error]"},
+		{slog.LevelInfo, "[info warn error]"},
+		{slog.LevelWarn, "[warn error]"},
+		{slog.LevelError, "[error]"},
+	}
+	for _, tt := range tests {
+		if got := fmt.Sprint(messages(buffer.Records(tt.level))); got != tt.want {
+			t.Errorf("Records(%v) = %s, want %s", tt.level, got, tt.want)
+		}
+	}
+}
+
+func TestRingBufferHandlerLevel(t *testing.T) {
+	buffer := NewRingBuffer(10)
+	log := slog.New(buffer.Handler(slog.LevelWarn))
+
+	log.Info("dropped")
+	log.Warn("kept")
+	if got := fmt.Sprint(messages(buffer.Records(slog.LevelDebug))); got != "[kept]" {
+		t.Errorf("records = %s, want records below the handler level dropped", got)
+	}
+}
+
+func TestRingBufferEviction(t *testing.T) {
+	buffer := NewRingBuffer(3)
+	log := slog.New(buffer.Handler(slog.LevelDebug))
+
+	if got := buffer.Records(slog.LevelDebug); len(got) != 0 {
+		t.Errorf("empty buffer returned %v", got)
+	}
+	for i := range 5 {
+		log.Info(fmt.Sprint(i))
+	}
+	if got := fmt.Sprint(messages(buffer.Records(slog.LevelDebug))); got != "[2 3 4]" {
+		t.Errorf("records = %s, want the last three, oldest first", got)
+	}
+	log.Info("5")
+	if got := fmt.Sprint(messages(buffer.Records(slog.LevelDebug))); got != "[3 4 5]" {
+		t.Errorf("records = %s after another wrap", got)
+	}
+}
+
+func TestRingBufferAttrs(t *testing.T) {
+	buffer := NewRingBuffer(10)
+	log := slog.New(buffer.Handler(slog.LevelDebug)).With("app", "test").WithGroup("req")
+
+	log.Info("request", "method", "GET", slog.Group("user", "id", "u1"), "err", fmt.Errorf("boom"))
+
+	records := buffer.Records(slog.LevelDebug)
+	if len(records) != 1 {
+		t.Fatalf("records = %v", records)
+	}
+	want := map[string]any{"app": "test", "req.method": "GET", "req.user.id": "u1", "req.err": "boom"}
+	if got := records[0].Attrs; fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("attrs = %v, want %v", got, want)
+	}
+	if records[0].Level != "INFO" {
+		t.Errorf("level = %q, want INFO", records[0].Level)
+	}
+}
+
+func TestRingBufferConcurrent(t *testing.T) {
+	buffer := NewRingBuffer(50)
+	log := slog.New(buffer.Handler(slog.LevelDebug))
+
+	var wg sync.WaitGroup
+	for g := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range 100 {
+				log.Info("message", "goroutine", g, "i", i)
+				buffer.Records(slog.LevelInfo)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := len(buffer.Records(slog.LevelDebug)); got != 50 {
+		t.Errorf("records = %d, want the buffer full at 50", got)
+	}
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"time"
+	"wails-template/internal/config"
+
+	"golang.org/x/sync/semaphore"
+)
+
+// RequestQueueStats describes the API requests in flight and those waiting
+// for a free slot
+type RequestQueueStats struct {
+	InFlight int64 `json:"inFlight"`
+	Waiting  int64 `json:"waiting"`
+	Limit    int   `json:"limit"` // 0 = unlimited
+}
+
+// requestSlots bounds the number of API requests in flight
+type requestSlots struct {
+	limit int
+	sem   *semaphore.Weighted // nil when unlimited
+}
+
+// newRequestSlots returns slots for limit concurrent requests, or unlimited
+// slots when limit is 0
+func newRequestSlots(limit int) *requestSlots {
+	slots := &requestSlots{limit: limit}
+	if limit > 0 {
+		slots.sem = semaphore.NewWeighted(int64(limit))
+	}
+	return slots
+}
+
+// acquireSlot waits until a request slot is free or ctx is done. The
+// returned function releases the slot and may be called more than once.
+func (a *App) acquireSlot(ctx context.Context) (func(), error) {
+	slots := a.currentSlots()
+	if slots.sem != nil {
+		a.waiting.Add(1)
+		err := slots.sem.Acquire(ctx, 1)
+		a.waiting.Add(-1)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	a.inFlight.Add(1)
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			a.inFlight.Add(-1)
+			if slots.sem != nil {
+				slots.sem.Release(1)
+			}
+		})
+	}, nil
+}
+
+// rateLimiter is a token bucket holding up to security.rate_limit_burst
+// requests, refilled at security.rate_limit_rps. Once the bucket has
+// refilled after a period of inactivity, rate_limit_grace further requests
+// are allowed before the steady rate applies. The zero value is ready to
+// use and starts idle.
+type rateLimiter struct {
+	mu      sync.Mutex
+	started bool
+	tokens  float64 // negative while requests wait for their turn
+	grace   int
+	last    time.Time
+}
+
+// reserve takes a request from the bucket at now and returns how long the
+// request has to wait for its turn
+func (l *rateLimiter) reserve(security config.SecurityConfig, now time.Time) time.Duration {
+	rps, burst := float64(security.RateLimitRPS), float64(security.RateLimitBurst)
+	if rps <= 0 {
+		return 0
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	tokens := burst
+	if l.started {
+		tokens = l.tokens + now.Sub(l.last).Seconds()*rps
+	}
+	if tokens >= burst {
+		// The bucket is full: the caller has been idle long enough for
+		// the grace to apply again
+		tokens = burst
+		l.grace = security.RateLimitGrace
+	}
+	l.started, l.last = true, now
+
+	switch {
+	case tokens >= 1:
+		l.tokens = tokens - 1
+		return 0
+	case l.grace > 0:
+		l.tokens = tokens
+		l.grace--
+		return 0
+	}
+	l.tokens = tokens - 1
+	return time.Duration(-l.tokens / rps * float64(time.Second))
+}
+
+// waitRateLimit waits until the rate limit allows another API request or
+// ctx is done
+func (a *App) waitRateLimit(ctx context.Context, security config.SecurityConfig) error {
+	if !security.RateLimitEnabled {
+		return nil
+	}
+	if !a.sleep(ctx, a.limiter.reserve(security, a.clock.Now())) {
+		return ctx.Err()
+	}
+	return nil
+}
+
+// GetRequestQueueStats returns the number of API requests in flight and
+// waiting for a slot, for diagnostics
+func (a *App) GetRequestQueueStats() RequestQueueStats {
+	return RequestQueueStats{
+		InFlight: a.inFlight.Load(),
+		Waiting:  a.waiting.Load(),
+		Limit:    a.currentSlots().limit,
+	}
+}
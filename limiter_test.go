@@ -0,0 +1,169 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+	"wails-template/internal/config"
+)
+
+func TestMaxConcurrentRequests(t *testing.T) {
+	const limit = 3
+	var active, peak atomic.Int32
+	app, _ := newTestApp(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := active.Add(1)
+		defer active.Add(-1)
+		for p := peak.Load(); n > p && !peak.CompareAndSwap(p, n); p = peak.Load() {
+		}
+		time.Sleep(20 * time.Millisecond)
+		io.WriteString(w, `{}`)
+	}))
+	app.slots = newRequestSlots(limit)
+
+	// POSTs are not deduplicated, every call reaches the server
+	errs := concurrentRequests(app, 20, func(i int) APIRequest {
+		return APIRequest{Method: http.MethodPost, Path: "/items", Body: map[string]int{"n": i}}
+	})
+	for _, err := range errs {
+		if err != nil {
+			t.Fatalf("Request: %v", err)
+		}
+	}
+	if peak.Load() != limit {
+		t.Errorf("peak concurrency = %d, want %d", peak.Load(), limit)
+	}
+	if stats := app.GetRequestQueueStats(); stats.InFlight != 0 || stats.Waiting != 0 {
+		t.Errorf("stats after the burst = %+v, want nothing in flight", stats)
+	}
+}
+
+func TestRequestWaitsForSlot(t *testing.T) {
+	handler, started := blockingHandler(t)
+	app, srv := newTestApp(t, handler)
+	app.slots = newRequestSlots(1)
+
+	go app.Request(APIRequest{Method: http.MethodPost, Path: "/held"})
+	<-started
+
+	// The second call waits for the slot until its context ends
+	ctx, cancel := context.WithCancel(context.Background())
+	errc := make(chan error, 1)
+	go func() {
+		var out any
+		errc <- app.doJSON(ctx, http.MethodPost, srv.URL+"/queued", nil, &out)
+	}()
+	waitForStats(t, app, RequestQueueStats{InFlight: 1, Waiting: 1, Limit: 1})
+
+	cancel()
+	if err := <-errc; !errors.Is(err, context.Canceled) {
+		t.Errorf("queued request error = %v, want context.Canceled", err)
+	}
+	if stats := app.GetRequestQueueStats(); stats.Waiting != 0 {
+		t.Errorf("Waiting = %d after cancel, want 0", stats.Waiting)
+	}
+}
+
+func TestMaxConcurrentRequestsUnlimited(t *testing.T) {
+	handler, started := blockingHandler(t)
+	app, _ := newTestApp(t, handler)
+	app.slots = newRequestSlots(0)
+
+	for i := range 5 {
+		go app.Request(APIRequest{Method: http.MethodPost, Path: "/held/" + strconv.Itoa(i)})
+		<-started
+	}
+	waitForStats(t, app, RequestQueueStats{InFlight: 5})
+}
+
+// waitForStats waits until the request queue stats of app equal want
+func waitForStats(t *testing.T, app *App, want RequestQueueStats) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for app.GetRequestQueueStats() != want {
+		if time.Now().After(deadline) {
+			t.Fatalf("stats = %+v, want %+v", app.GetRequestQueueStats(), want)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestRateLimiterGraceAfterIdle(t *testing.T) {
+	security := config.SecurityConfig{RateLimitEnabled: true, RateLimitRPS: 10, RateLimitBurst: 5, RateLimitGrace: 3}
+	now := time.Now()
+	var limiter rateLimiter
+
+	// allowed counts the requests at at that don't have to wait
+	allowed := func(at time.Time, n int) int {
+		free := 0
+		for range n {
+			if limiter.reserve(security, at) == 0 {
+				free++
+			}
+		}
+		return free
+	}
+
+	// After inactivity the burst and the grace are allowed at once
+	if got := allowed(now, 10); got != 8 {
+		t.Fatalf("requests allowed when idle = %d, want burst 5 + grace 3", got)
+	}
+
+	// Sustained traffic is held to the steady rate: the queued requests
+	// wait for their turn at 10 per second
+	if wait := limiter.reserve(security, now); wait != 300*time.Millisecond {
+		t.Errorf("wait of the next request = %v, want 300ms behind the 2 queued", wait)
+	}
+	now = now.Add(500 * time.Millisecond)
+	if got := allowed(now, 10); got != 2 {
+		t.Errorf("requests allowed 500ms later = %d, want the 2 refilled beyond the queue and no grace", got)
+	}
+
+	// The grace comes back only once the bucket has refilled
+	now = now.Add(300 * time.Millisecond)
+	if got := allowed(now, 10); got != 0 {
+		t.Errorf("requests allowed after a short pause = %d, want none", got)
+	}
+	now = now.Add(3 * time.Second)
+	if got := allowed(now, 10); got != 8 {
+		t.Errorf("requests allowed after an idle period = %d, want burst 5 + grace 3", got)
+	}
+}
+
+func TestRateLimiterWithoutGrace(t *testing.T) {
+	security := config.SecurityConfig{RateLimitEnabled: true, RateLimitRPS: 100, RateLimitBurst: 2}
+	now := time.Now()
+	var limiter rateLimiter
+
+	var waits []time.Duration
+	for range 4 {
+		waits = append(waits, limiter.reserve(security, now))
+	}
+	want := []time.Duration{0, 0, 10 * time.Millisecond, 20 * time.Millisecond}
+	if !slices.Equal(waits, want) {
+		t.Errorf("waits = %v, want %v", waits, want)
+	}
+}
+
+func TestRequestRateLimited(t *testing.T) {
+	app, _ := newTestApp(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{}`)
+	}))
+	app.config.Security = config.SecurityConfig{RateLimitEnabled: true, RateLimitRPS: 20, RateLimitBurst: 1, RateLimitGrace: 1}
+
+	start := time.Now()
+	for i := range 3 {
+		if _, err := app.Request(APIRequest{Method: http.MethodPost, Path: "/items", Body: map[string]int{"n": i}}); err != nil {
+			t.Fatalf("request %d: %v", i, err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+		t.Errorf("3 requests took %v, want the third to wait for its turn", elapsed)
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"wails-template/internal/config"
+)
+
+// ErrFileLoggingDisabled is returned by OpenLogLocation when logs are not
+// written to a file
+var ErrFileLoggingDisabled = errors.New("file logging is not enabled")
+
+// startCommand starts a command without waiting for it. Tests replace it to
+// capture the command instead of running it.
+var startCommand = func(name string, args ...string) error {
+	cmd := exec.Command(name, args...)
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	// Reap the process; file managers may exit non-zero on success
+	go cmd.Wait()
+	return nil
+}
+
+// OpenLogLocation opens the directory containing the log file in the file
+// manager of the OS, so users can find their logs for support requests
+func (a *App) OpenLogLocation() (err error) {
+	defer a.recoverPanic("OpenLogLocation", &err)
+
+	cfg := a.currentConfig()
+
+	if !cfg.Log.Output.Has(config.LogOutputFile) {
+		return ErrFileLoggingDisabled
+	}
+
+	// An absolute path can't be mistaken for a command line option
+	dir, err := filepath.Abs(filepath.Dir(cfg.Log.FilePath))
+	if err != nil {
+		return fmt.Errorf("failed to resolve log directory: %w", err)
+	}
+	info, err := os.Stat(dir)
+	if err != nil {
+		return fmt.Errorf("failed to open log directory: %w", err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("failed to open log directory: %s is not a directory", dir)
+	}
+
+	name, args := fileManagerCommand(runtime.GOOS, dir)
+	if err := startCommand(name, args...); err != nil {
+		return fmt.Errorf("failed to open log directory: %w", err)
+	}
+	return nil
+}
+
+// fileManagerCommand returns the command that opens dir in the file manager
+// of goos. The directory is passed as a single argument, never through a
+// shell.
+func fileManagerCommand(goos, dir string) (string, []string) {
+	switch goos {
+	case "windows":
+		return "explorer", []string{dir}
+	case "darwin":
+		return "open", []string{dir}
+	default:
+		return "xdg-open", []string{dir}
+	}
+}
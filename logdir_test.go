@@ -0,0 +1,85 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"wails-template/internal/config"
+)
+
+// stubStartCommand replaces startCommand for the test and returns the
+// commands that were started
+func stubStartCommand(t *testing.T) *[][]string {
+	t.Helper()
+
+	var started [][]string
+	old := startCommand
+	startCommand = func(name string, args ...string) error {
+		started = append(started, append([]string{name}, args...))
+		return nil
+	}
+	t.Cleanup(func() { startCommand = old })
+	return &started
+}
+
+func TestOpenLogLocation(t *testing.T) {
+	started := stubStartCommand(t)
+	app, _ := newTestApp(t, http.NotFoundHandler())
+
+	// Shell metacharacters in the path must reach the file manager verbatim
+	dir := filepath.Join(t.TempDir(), "logs; rm -rf $HOME")
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	app.config.Log.Output = config.LogOutputBoth
+	app.config.Log.FilePath = filepath.Join(dir, "app.log")
+
+	if err := app.OpenLogLocation(); err != nil {
+		t.Fatalf("OpenLogLocation: %v", err)
+	}
+	if len(*started) != 1 {
+		t.Fatalf("started %d commands, want 1", len(*started))
+	}
+	if got := (*started)[0]; got[len(got)-1] != dir {
+		t.Errorf("command = %q, want the log directory %q as last argument", got, dir)
+	}
+}
+
+func TestOpenLogLocationErrors(t *testing.T) {
+	started := stubStartCommand(t)
+	app, _ := newTestApp(t, http.NotFoundHandler())
+
+	app.config.Log.Output = config.LogOutputConsole
+	if err := app.OpenLogLocation(); !errors.Is(err, ErrFileLoggingDisabled) {
+		t.Errorf("console logging error = %v, want ErrFileLoggingDisabled", err)
+	}
+
+	app.config.Log.Output = config.LogOutputFile
+	app.config.Log.FilePath = filepath.Join(t.TempDir(), "missing", "app.log")
+	if err := app.OpenLogLocation(); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("missing directory error = %v, want os.ErrNotExist", err)
+	}
+
+	if len(*started) != 0 {
+		t.Errorf("started commands %q, want none", *started)
+	}
+}
+
+func TestFileManagerCommand(t *testing.T) {
+	dir := "/var/log/my app"
+	tests := map[string][]string{
+		"windows": {"explorer", dir},
+		"darwin":  {"open", dir},
+		"linux":   {"xdg-open", dir},
+		"freebsd": {"xdg-open", dir},
+	}
+	for goos, want := range tests {
+		name, args := fileManagerCommand(goos, dir)
+		if got := append([]string{name}, args...); !slices.Equal(got, want) {
+			t.Errorf("fileManagerCommand(%q) = %q, want %q", goos, got, want)
+		}
+	}
+}
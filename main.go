@@ -3,11 +3,11 @@ package main
 import (
 	"embed"
 	"log"
+	"os"
 	"wails-template/internal/config"
 
 	"github.com/wailsapp/wails/v2"
 	"github.com/wailsapp/wails/v2/pkg/options"
-	"github.com/wailsapp/wails/v2/pkg/options/assetserver"
 )
 
 //go:embed all:frontend/dist
@@ -32,14 +32,14 @@ func main() {
 
 	// Create application with options
 	err = wails.Run(&options.App{
-		Title:  appTitle,
-		Width:  windowWidth,
-		Height: windowHeight,
-		AssetServer: &assetserver.Options{
-			Assets: assets,
-		},
+		Title:            appTitle,
+		Width:            windowWidth,
+		Height:           windowHeight,
+		AssetServer:      assetServerOptions(cfg),
 		BackgroundColour: &options.RGBA{R: 27, G: 38, B: 54, A: 1},
 		OnStartup:        app.startup,
+		OnDomReady:       app.domReady,
+		OnShutdown:       app.shutdown,
 		Bind: []any{
 			app,
 		},
@@ -48,4 +48,7 @@ func main() {
 	if err != nil {
 		log.Fatalf("Error starting application: %v", err)
 	}
+	if app.exitCode != 0 {
+		os.Exit(app.exitCode)
+	}
 }
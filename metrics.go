@@ -0,0 +1,138 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// maxMetricEndpoints bounds the endpoints tracked by apiMetrics. Further
+// endpoints, such as paths with IDs, are counted under otherEndpoint.
+const maxMetricEndpoints = 200
+
+// otherEndpoint collects the endpoints beyond maxMetricEndpoints
+const otherEndpoint = "*"
+
+// statusClasses are the labels of retry buckets: transport errors first,
+// then the status classes 1xx to 5xx
+var statusClasses = [...]string{"network", "1xx", "2xx", "3xx", "4xx", "5xx"}
+
+// APIMetrics describes failed and retried API attempts
+type APIMetrics struct {
+	TotalRetries int64 `json:"totalRetries"`
+	// Endpoints are keyed by method and path, e.g. "GET /items"
+	Endpoints map[string]EndpointMetrics `json:"endpoints"`
+}
+
+// EndpointMetrics describes the failed attempts of one endpoint
+type EndpointMetrics struct {
+	Retries int64 `json:"retries"`
+	// RetriesByClass counts retries by the status class of the failed
+	// attempt, such as "5xx", or "network" for transport errors
+	RetriesByClass map[string]int64 `json:"retriesByClass"`
+	LastError      string           `json:"lastError,omitempty"` // without the request URL
+	LastErrorAt    time.Time        `json:"lastErrorAt,omitzero"`
+}
+
+// apiMetrics records failed API attempts per endpoint. The zero value is
+// ready to use.
+type apiMetrics struct {
+	mu        sync.Mutex
+	total     int64
+	endpoints map[endpointKey]*endpointCounters
+}
+
+type endpointKey struct {
+	method, path string
+}
+
+type endpointCounters struct {
+	retries     [len(statusClasses)]int64
+	lastError   string
+	lastErrorAt time.Time
+}
+
+// recordFailure records a failed attempt of method and path, counting it
+// as a retry when another attempt follows
+func (m *apiMetrics) recordFailure(method, path string, failure RetryAttempt, retried bool, now time.Time) {
+	message := redactedError(failure)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := endpointKey{method, path}
+	counters, ok := m.endpoints[key]
+	if !ok {
+		if m.endpoints == nil {
+			m.endpoints = make(map[endpointKey]*endpointCounters)
+		}
+		if len(m.endpoints) >= maxMetricEndpoints {
+			key.path = otherEndpoint
+			counters = m.endpoints[key]
+		}
+		if counters == nil {
+			counters = &endpointCounters{}
+			m.endpoints[key] = counters
+		}
+	}
+
+	counters.lastError, counters.lastErrorAt = message, now
+	if retried {
+		counters.retries[statusClass(failure.StatusCode)]++
+		m.total++
+	}
+}
+
+// snapshot returns a copy of the metrics
+func (m *apiMetrics) snapshot() APIMetrics {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	metrics := APIMetrics{TotalRetries: m.total, Endpoints: make(map[string]EndpointMetrics, len(m.endpoints))}
+	for key, counters := range m.endpoints {
+		endpoint := EndpointMetrics{
+			RetriesByClass: make(map[string]int64),
+			LastError:      counters.lastError,
+			LastErrorAt:    counters.lastErrorAt,
+		}
+		for class, retries := range counters.retries {
+			if retries > 0 {
+				endpoint.RetriesByClass[statusClasses[class]] = retries
+				endpoint.Retries += retries
+			}
+		}
+		metrics.Endpoints[key.method+" "+key.path] = endpoint
+	}
+	return metrics
+}
+
+// statusClass returns the index in statusClasses of a failed attempt's
+// status, 0 for transport errors
+func statusClass(status int) int {
+	if class := status / 100; class >= 1 && class < len(statusClasses) {
+		return class
+	}
+	return 0
+}
+
+// redactedError returns the message of a failed attempt without the
+// request URL, whose query may carry credentials
+func redactedError(failure RetryAttempt) string {
+	if failure.Err == nil {
+		return strconv.Itoa(failure.StatusCode) + " " + http.StatusText(failure.StatusCode)
+	}
+	var urlErr *url.Error
+	if errors.As(failure.Err, &urlErr) {
+		return urlErr.Op + ": " + urlErr.Err.Error()
+	}
+	return failure.Err.Error()
+}
+
+// GetAPIMetrics returns the retries and last errors of the API endpoints
+// since the app started
+func (a *App) GetAPIMetrics() APIMetrics {
+	return a.metrics.snapshot()
+}
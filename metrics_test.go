@@ -0,0 +1,88 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestMetricsCountRetriesByClass(t *testing.T) {
+	var calls atomic.Int32
+	app, _ := newTestApp(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) <= 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		io.WriteString(w, `{}`)
+	}))
+	app.config.API.RetryCount = 2
+
+	if _, err := app.Request(APIRequest{Method: http.MethodGet, Path: "/flaky"}); err != nil {
+		t.Fatalf("Request() error = %v", err)
+	}
+
+	metrics := app.GetAPIMetrics()
+	endpoint := metrics.Endpoints["GET /flaky"]
+	if metrics.TotalRetries != 2 || endpoint.Retries != 2 || endpoint.RetriesByClass["5xx"] != 2 {
+		t.Errorf("metrics = %+v, want 2 retries in 5xx", metrics)
+	}
+	if endpoint.LastError != "503 Service Unavailable" || endpoint.LastErrorAt.IsZero() {
+		t.Errorf("last error = %q at %v, want 503 with a time", endpoint.LastError, endpoint.LastErrorAt)
+	}
+}
+
+func TestMetricsNetworkErrorRedacted(t *testing.T) {
+	app, srv := newTestApp(t, http.NotFoundHandler())
+	app.config.API.RetryCount = 1
+	srv.Close()
+
+	if _, err := app.Request(APIRequest{Method: http.MethodGet, Path: "/items?token=s3cret"}); err == nil {
+		t.Fatal("Request() succeeded against a closed server")
+	}
+
+	endpoint := app.GetAPIMetrics().Endpoints["GET /items"]
+	if endpoint.RetriesByClass["network"] != 1 {
+		t.Errorf("retries = %v, want 1 network retry", endpoint.RetriesByClass)
+	}
+	if endpoint.LastError == "" || strings.Contains(endpoint.LastError, "s3cret") || strings.Contains(endpoint.LastError, srv.URL) {
+		t.Errorf("last error = %q, want a message without the URL", endpoint.LastError)
+	}
+}
+
+func TestMetricsIgnoreClientErrors(t *testing.T) {
+	app, _ := newTestApp(t, http.NotFoundHandler())
+	app.config.API.RetryCount = 2
+
+	app.Request(APIRequest{Method: http.MethodGet, Path: "/missing"})
+
+	if metrics := app.GetAPIMetrics(); metrics.TotalRetries != 0 || len(metrics.Endpoints) != 0 {
+		t.Errorf("metrics = %+v, want none for a 404", metrics)
+	}
+}
+
+func TestMetricsConcurrent(t *testing.T) {
+	app, _ := newTestApp(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	app.config.API.RetryCount = 1
+
+	var wg sync.WaitGroup
+	for i := range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// Distinct queries keep identical GETs from being shared
+			app.Request(APIRequest{Method: http.MethodGet, Path: "/busy?n=" + strconv.Itoa(i)})
+			app.GetAPIMetrics()
+		}()
+	}
+	wg.Wait()
+
+	if got := app.GetAPIMetrics().Endpoints["GET /busy"].RetriesByClass["5xx"]; got != 10 {
+		t.Errorf("5xx retries = %d, want 10", got)
+	}
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"maps"
+	"strconv"
+)
+
+// Query parameters of paged list requests
+const (
+	pageParam     = "page"
+	pageSizeParam = "page_size"
+)
+
+// RequestPaged requests one page of a list endpoint, sending the page and
+// page size as query parameters. A page size of 0 uses api.default_page_size
+// and larger sizes than api.max_page_size are clamped to it. Pages start
+// at 1.
+func (a *App) RequestPaged(req APIRequest, page, pageSize int) (_ any, err error) {
+	defer a.recoverPanic("RequestPaged", &err)
+
+	query := maps.Clone(req.Query)
+	if query == nil {
+		query = make(map[string]string)
+	}
+	query[pageParam] = strconv.Itoa(max(page, 1))
+	query[pageSizeParam] = strconv.Itoa(a.pageSize(req.Path, pageSize))
+	req.Query = query
+
+	return a.Request(req)
+}
+
+// pageSize applies the configured bounds to a requested page size
+func (a *App) pageSize(path string, requested int) int {
+	limits := a.currentConfig().API
+	switch {
+	case requested <= 0:
+		return limits.DefaultPageSize
+	case limits.MaxPageSize > 0 && requested > limits.MaxPageSize:
+		a.logger.Warn("Page size clamped to the maximum", "path", path, "requested", requested, "max", limits.MaxPageSize)
+		return limits.MaxPageSize
+	default:
+		return requested
+	}
+}
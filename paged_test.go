@@ -0,0 +1,64 @@
+package main
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+// queryRecorder answers every request with an empty list and sends the
+// query of each request to queries
+func queryRecorder(queries chan url.Values) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries <- r.URL.Query()
+		w.Write([]byte(`[]`))
+	})
+}
+
+func TestRequestPaged(t *testing.T) {
+	queries := make(chan url.Values, 1)
+	app, _ := newTestApp(t, queryRecorder(queries))
+	app.config.API.DefaultPageSize = 20
+	app.config.API.MaxPageSize = 100
+
+	tests := []struct {
+		name                   string
+		page, pageSize         int
+		wantPage, wantPageSize string
+	}{
+		{"default page size", 2, 0, "2", "20"},
+		{"requested page size", 1, 50, "1", "50"},
+		{"maximum page size", 1, 100, "1", "100"},
+		{"clamped page size", 3, 5000, "3", "100"},
+		{"first page", 0, 10, "1", "10"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := APIRequest{Method: http.MethodGet, Path: "/items", Query: map[string]string{"sort": "name"}}
+			if _, err := app.RequestPaged(req, tt.page, tt.pageSize); err != nil {
+				t.Fatalf("RequestPaged: %v", err)
+			}
+			query := <-queries
+			if query.Get("page") != tt.wantPage || query.Get("page_size") != tt.wantPageSize || query.Get("sort") != "name" {
+				t.Errorf("query = %v, want page %s, page_size %s and sort", query, tt.wantPage, tt.wantPageSize)
+			}
+			if len(req.Query) != 1 {
+				t.Errorf("RequestPaged changed the caller's query: %v", req.Query)
+			}
+		})
+	}
+}
+
+func TestRequestPagedLogsClamping(t *testing.T) {
+	queries := make(chan url.Values, 1)
+	app, _ := newTestApp(t, queryRecorder(queries))
+	app.config.API.MaxPageSize = 100
+
+	if _, err := app.RequestPaged(APIRequest{Method: http.MethodGet, Path: "/items"}, 1, 1000); err != nil {
+		t.Fatalf("RequestPaged: %v", err)
+	}
+	<-queries
+	if !loggedMessage(app, "Page size clamped to the maximum") {
+		t.Error("clamping was not logged")
+	}
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"time"
+)
+
+// ErrInternal is returned by a bound method that panicked. The panic value
+// and stack trace are logged instead of being passed to the frontend.
+var ErrInternal = errors.New("an internal error occurred")
+
+// PanicEvent is the payload of the app:panic event
+type PanicEvent struct {
+	Method string    `json:"method"`
+	Time   time.Time `json:"time"`
+}
+
+// recoverPanic recovers a panic in the bound method named method. The panic
+// is logged with its stack trace, app:panic is emitted and *err is set to
+// ErrInternal. It must be deferred directly by the method. When
+// app.recover_panics is disabled the panic is left to crash the call.
+func (a *App) recoverPanic(method string, err *error) {
+	if !a.currentConfig().App.RecoverPanics {
+		return
+	}
+	r := recover()
+	if r == nil {
+		return
+	}
+
+	a.logger.Error("Bound method panicked",
+		"method", method,
+		"panic", fmt.Sprint(r),
+		"stack", string(debug.Stack()),
+	)
+	a.emitEvent("app:panic", PanicEvent{Method: method, Time: a.clock.Now()})
+	*err = ErrInternal
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// panicHook is a request hook that panics, standing in for a bug in a
+// bound method
+func panicHook(*http.Request) error {
+	panic("hook exploded")
+}
+
+func TestBoundMethodPanicReturnsError(t *testing.T) {
+	app, _ := newTestApp(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	app.hooks.AddRequestHook(panicHook)
+
+	_, err := app.Request(APIRequest{Method: http.MethodPost, Path: "/items"})
+	if !errors.Is(err, ErrInternal) {
+		t.Fatalf("Request error = %v, want ErrInternal", err)
+	}
+	if strings.Contains(err.Error(), "hook exploded") {
+		t.Errorf("error %q exposes the panic value", err)
+	}
+
+	records := app.logger.Recent(slog.LevelError)
+	if len(records) != 1 {
+		t.Fatalf("logged %d errors, want 1", len(records))
+	}
+	attrs := records[0].Attrs
+	if attrs["method"] != "Request" || attrs["panic"] != "hook exploded" {
+		t.Errorf("log attrs = %v, want the method and panic value", attrs)
+	}
+	if stack, _ := attrs["stack"].(string); !strings.Contains(stack, "panicHook") {
+		t.Errorf("logged stack does not include the panicking function:\n%s", stack)
+	}
+}
+
+func TestBoundMethodPanicRecoveryDisabled(t *testing.T) {
+	app, _ := newTestApp(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	app.config.App.RecoverPanics = false
+	app.hooks.AddRequestHook(panicHook)
+
+	defer func() {
+		if r := recover(); r != "hook exploded" {
+			t.Errorf("recovered %v, want the original panic", r)
+		}
+	}()
+	app.Login("user", "password")
+	t.Error("Login returned instead of panicking")
+}
@@ -0,0 +1,270 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+	"wails-template/internal/config"
+)
+
+// maskedValue replaces redacted header and body values in recordings
+const maskedValue = "***MASKED***"
+
+// ErrNotRecorded is returned in replay mode for a request without a
+// recorded response
+var ErrNotRecorded = errors.New("no recorded response")
+
+// secretBodyKeys are the parts of JSON object keys whose values are
+// redacted from recorded bodies, matched regardless of case
+var secretBodyKeys = []string{"password", "secret", "token", "apikey", "api_key", "authorization"}
+
+// recordedExchange is one request and its response, written as a JSON line
+// by the recorder and served by the replay. Secrets are redacted.
+type recordedExchange struct {
+	Time            time.Time   `json:"time"`
+	Method          string      `json:"method"`
+	Path            string      `json:"path"` // without the query
+	RequestHeaders  http.Header `json:"requestHeaders,omitempty"`
+	RequestBody     string      `json:"requestBody,omitempty"`
+	Status          int         `json:"status"`
+	ResponseHeaders http.Header `json:"responseHeaders,omitempty"`
+	ResponseBody    string      `json:"responseBody,omitempty"`
+}
+
+// requestRecorder appends the API exchanges to a file as JSON lines
+type requestRecorder struct {
+	mu   sync.Mutex
+	file *os.File
+
+	// maskedHeaders are the canonical names of the headers whose values
+	// are redacted
+	maskedHeaders map[string]bool
+}
+
+// openRecorder opens the recording file at path for appending, creating it
+// and its directory as needed. The auth, signing and default headers of
+// api and auth are redacted from the recording.
+func openRecorder(path string, api config.APIConfig, auth config.AuthConfig) (*requestRecorder, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create recording directory: %w", err)
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open recording: %w", err)
+	}
+
+	return &requestRecorder{file: file, maskedHeaders: credentialHeaders(api, auth)}, nil
+}
+
+// record wraps the body of resp so the exchange is written once the body
+// has been closed, with the part of it that was read. Streaming responses
+// are passed on as they arrive.
+func (r *requestRecorder) record(req *http.Request, body []byte, resp *http.Response, now time.Time) *http.Response {
+	exchange := recordedExchange{
+		Time:            now,
+		Method:          req.Method,
+		Path:            req.URL.Path,
+		RequestHeaders:  r.redactHeaders(req.Header),
+		RequestBody:     redactBody(body),
+		Status:          resp.StatusCode,
+		ResponseHeaders: r.redactHeaders(resp.Header),
+	}
+	resp.Body = &recordingBody{ReadCloser: resp.Body, done: func(read []byte) {
+		exchange.ResponseBody = redactBody(read)
+		r.write(exchange)
+	}}
+	return resp
+}
+
+// write appends exchange to the recording. Failures are ignored so
+// recording never breaks a request.
+func (r *requestRecorder) write(exchange recordedExchange) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_ = json.NewEncoder(r.file).Encode(exchange)
+}
+
+// Close closes the recording file. It is safe to call on a nil recorder.
+func (r *requestRecorder) Close() error {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.file.Close()
+}
+
+// redactHeaders returns a copy of header with the values of the masked
+// headers redacted
+func (r *requestRecorder) redactHeaders(header http.Header) http.Header {
+	redacted := header.Clone()
+	for name := range redacted {
+		if r.maskedHeaders[name] {
+			redacted[name] = []string{maskedValue}
+		}
+	}
+	return redacted
+}
+
+// redactBody returns body with the values of secret JSON keys redacted.
+// Bodies that aren't JSON are kept as they are.
+func redactBody(body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+	var value any
+	if err := json.Unmarshal(body, &value); err != nil {
+		return string(body)
+	}
+	redacted, err := json.Marshal(redactJSON(value))
+	if err != nil {
+		return string(body)
+	}
+	return string(redacted)
+}
+
+// redactJSON replaces the values of secret keys in a decoded JSON value
+func redactJSON(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, item := range v {
+			if isSecretKey(key) {
+				v[key] = maskedValue
+			} else {
+				v[key] = redactJSON(item)
+			}
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = redactJSON(item)
+		}
+	}
+	return value
+}
+
+// isSecretKey reports whether a JSON key names a secret, such as
+// accessToken or password
+func isSecretKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, secret := range secretBodyKeys {
+		if strings.Contains(key, secret) {
+			return true
+		}
+	}
+	return false
+}
+
+// recordingBody keeps what is read from a response body and hands it to
+// done when the body is closed
+type recordingBody struct {
+	io.ReadCloser
+	read bytes.Buffer
+	once sync.Once
+	done func(read []byte)
+}
+
+func (b *recordingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.read.Write(p[:n])
+	return n, err
+}
+
+func (b *recordingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(func() { b.done(b.read.Bytes()) })
+	return err
+}
+
+// replaySession serves recorded responses instead of sending requests.
+// Requests are matched by method and path; repeated requests get the
+// recorded responses in order, the last one once they run out.
+type replaySession struct {
+	mu        sync.Mutex
+	exchanges map[string][]recordedExchange
+	served    map[string]int
+}
+
+// loadReplay reads the recording at path
+func loadReplay(path string) (*replaySession, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open recording: %w", err)
+	}
+	defer file.Close()
+
+	session := &replaySession{exchanges: make(map[string][]recordedExchange), served: make(map[string]int)}
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, 64<<20)
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var exchange recordedExchange
+		if err := json.Unmarshal(scanner.Bytes(), &exchange); err != nil {
+			return nil, fmt.Errorf("recording %s line %d: %w", path, line, err)
+		}
+		key := exchange.Method + " " + exchange.Path
+		session.exchanges[key] = append(session.exchanges[key], exchange)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read recording: %w", err)
+	}
+	return session, nil
+}
+
+// respond returns the next recorded response for req
+func (s *replaySession) respond(req *http.Request) (*http.Response, error) {
+	key := req.Method + " " + req.URL.Path
+
+	s.mu.Lock()
+	exchanges := s.exchanges[key]
+	if len(exchanges) == 0 {
+		s.mu.Unlock()
+		return nil, fmt.Errorf("%w for %s", ErrNotRecorded, key)
+	}
+	i := min(s.served[key], len(exchanges)-1)
+	s.served[key]++
+	s.mu.Unlock()
+
+	exchange := exchanges[i]
+	header := exchange.ResponseHeaders.Clone()
+	if header == nil {
+		header = make(http.Header)
+	}
+	// Redaction may have changed the length of the body
+	header.Del("Content-Length")
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", exchange.Status, http.StatusText(exchange.Status)),
+		StatusCode:    exchange.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(exchange.ResponseBody)),
+		ContentLength: int64(len(exchange.ResponseBody)),
+		Request:       req,
+	}, nil
+}
+
+// do sends req, or answers it from the replayed recording in replay mode.
+// When recording, the exchange is written once the response body is
+// closed. body is the request body before compression.
+func (a *App) do(req *http.Request, body []byte) (*http.Response, error) {
+	if a.replay != nil {
+		return a.replay.respond(req)
+	}
+	resp, err := a.currentClient().Do(req)
+	if err == nil && a.recorder != nil {
+		resp = a.recorder.record(req, body, resp, a.clock.Now())
+	}
+	return resp, err
+}
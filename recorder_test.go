@@ -0,0 +1,105 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	var listings atomic.Int32
+	app, _ := newTestApp(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		switch r.URL.Path {
+		case "/login":
+			io.WriteString(w, `{"user":"jane","accessToken":"tok-123"}`)
+		case "/items":
+			fmt.Fprintf(w, `{"page":%d}`, listings.Add(1))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			io.WriteString(w, `{"code":"NOT_FOUND","message":"no such thing"}`)
+		}
+	}))
+	app.config.API.DefaultHeaders = map[string]string{"X-Api-Key": "key-456"}
+	path := filepath.Join(t.TempDir(), "session.jsonl")
+	recorder, err := openRecorder(path, app.config.API, app.config.Auth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	app.recorder = recorder
+
+	requests := []APIRequest{
+		{Method: http.MethodPost, Path: "/login", Body: map[string]string{"username": "jane", "password": "hunter2"}},
+		{Method: http.MethodGet, Path: "/items"},
+		{Method: http.MethodGet, Path: "/items", Query: map[string]string{"page": "2"}},
+		{Method: http.MethodGet, Path: "/missing"},
+	}
+	for _, req := range requests {
+		app.Request(req)
+	}
+	if err := recorder.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Count(string(data), "\n"); lines != len(requests) {
+		t.Errorf("recording has %d lines, want %d", lines, len(requests))
+	}
+	for _, secret := range []string{"hunter2", "tok-123", "key-456"} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("recording contains %q", secret)
+		}
+	}
+
+	// The replaying app never reaches its server
+	replayer, _ := newTestApp(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("replay sent %s %s", r.Method, r.URL.Path)
+	}))
+	if replayer.replay, err = loadReplay(path); err != nil {
+		t.Fatal(err)
+	}
+
+	login, err := replayer.Request(requests[0])
+	if err != nil || fmt.Sprint(login) != "map[accessToken:***MASKED*** user:jane]" {
+		t.Errorf("replayed login = %v, %v", login, err)
+	}
+	for i, want := range []string{"map[page:1]", "map[page:2]", "map[page:2]"} {
+		page, err := replayer.Request(APIRequest{Method: http.MethodGet, Path: "/items"})
+		if err != nil || fmt.Sprint(page) != want {
+			t.Errorf("replayed listing %d = %v, %v, want %s", i, page, err, want)
+		}
+	}
+	_, err = replayer.Request(requests[3])
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound || apiErr.Code != "NOT_FOUND" {
+		t.Errorf("replayed error = %v, want the recorded 404", err)
+	}
+	if _, err := replayer.Request(APIRequest{Method: http.MethodDelete, Path: "/items"}); !errors.Is(err, ErrNotRecorded) {
+		t.Errorf("unrecorded request error = %v, want ErrNotRecorded", err)
+	}
+}
+
+func TestRedactBody(t *testing.T) {
+	tests := []struct {
+		body, want string
+	}{
+		{`{"username":"jane","password":"hunter2"}`, `{"password":"***MASKED***","username":"jane"}`},
+		{`{"data":[{"refresh_token":"r","id":1}]}`, `{"data":[{"id":1,"refresh_token":"***MASKED***"}]}`},
+		{`plain text`, `plain text`},
+		{``, ``},
+	}
+	for _, tt := range tests {
+		if got := redactBody([]byte(tt.body)); got != tt.want {
+			t.Errorf("redactBody(%s) = %s, want %s", tt.body, got, tt.want)
+		}
+	}
+}
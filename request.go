@@ -0,0 +1,546 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+	"wails-template/internal/config"
+	"wails-template/internal/logger"
+)
+
+// ErrResponseTooLarge is returned when a response body exceeds the
+// configured maximum size
+var ErrResponseTooLarge = errors.New("response body exceeds the configured size limit")
+
+// volatileHeaders differ between otherwise identical requests and are
+// ignored when deduplicating them
+var volatileHeaders = []string{"X-Request-Id", "Date"}
+
+// APIError is returned when the API responds with an error status
+type APIError struct {
+	StatusCode int    `json:"statusCode"`
+	Code       string `json:"code"`
+	Message    string `json:"message"`
+
+	messages config.MessageCatalog
+	locale   string
+}
+
+func (e *APIError) Error() string {
+	if e.Message != "" {
+		return e.Message
+	}
+	return fmt.Sprintf("API request failed with status %d", e.StatusCode)
+}
+
+// UserMessage returns the message to show for the error: the catalog
+// message of its code in the app's locale, falling back to the message of
+// the API
+func (e *APIError) UserMessage() string {
+	if message, ok := e.messages.Message(e.locale, e.Code); ok {
+		return message
+	}
+	return e.Error()
+}
+
+// newAPIError returns an APIError for status that resolves its user
+// message through the app's message catalog
+func (a *App) newAPIError(status int) *APIError {
+	return &APIError{StatusCode: status, messages: a.currentMessages(), locale: a.currentConfig().App.Locale}
+}
+
+// loadMessages returns the message catalog of cfg. A messages file that
+// can't be loaded is logged and leaves the [messages] sections only.
+func loadMessages(cfg *config.Config, log *logger.Logger) config.MessageCatalog {
+	messages, err := config.LoadMessageCatalog(cfg)
+	if err != nil {
+		log.Warn("Failed to load the message catalog", "error", err)
+	}
+	return messages
+}
+
+// RetryAttempt is the outcome of a failed attempt: the status code when the
+// server answered with an error, otherwise the transport error
+type RetryAttempt struct {
+	StatusCode int
+	Err        error
+}
+
+func (a RetryAttempt) String() string {
+	if a.Err == nil {
+		return strconv.Itoa(a.StatusCode)
+	}
+	var netErr net.Error
+	if errors.As(a.Err, &netErr) && netErr.Timeout() {
+		return "timeout"
+	}
+	return a.Err.Error()
+}
+
+// RetryExhaustedError is returned when every attempt of a request failed
+// with a transport error or a retried error status, listing the attempts in
+// order. A final error status is returned as the response instead, so the
+// last attempt always carries an error.
+type RetryExhaustedError struct {
+	Attempts []RetryAttempt
+}
+
+func (e *RetryExhaustedError) Error() string {
+	history := make([]string, len(e.Attempts))
+	for i, attempt := range e.Attempts {
+		history[i] = attempt.String()
+	}
+	return fmt.Sprintf("failed to send request after %d attempts (%s): %v", len(e.Attempts), strings.Join(history, ", "), e.Unwrap())
+}
+
+// Unwrap returns the error of the last attempt
+func (e *RetryExhaustedError) Unwrap() error {
+	if len(e.Attempts) == 0 {
+		return nil
+	}
+	return e.Attempts[len(e.Attempts)-1].Err
+}
+
+// baseURL returns the API base URL for the current session. When a tenant
+// URL template is configured and the logged-in user has a tenant, the
+// template is used with {tenant} substituted; otherwise the plain base URL.
+func (a *App) baseURL() string {
+	cfg := a.currentConfig()
+	template := cfg.API.TenantURLTemplate
+	if template == "" {
+		return cfg.API.BaseURL
+	}
+
+	a.sessionMu.RLock()
+	defer a.sessionMu.RUnlock()
+	if a.session == nil || a.session.user.CurrentTenantID == "" {
+		return cfg.API.BaseURL
+	}
+	return strings.ReplaceAll(template, "{tenant}", url.PathEscape(string(a.session.user.CurrentTenantID)))
+}
+
+// APIRequest describes a request issued by the frontend through Request
+type APIRequest struct {
+	Method  string            `json:"method"`
+	Path    string            `json:"path"`
+	Query   map[string]string `json:"query,omitempty"`
+	Body    any               `json:"body,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Timeout int               `json:"timeout,omitempty"` // seconds, 0 = api.timeout
+	// Stream decodes the response while it is read rather than buffering
+	// it first, lowering peak memory for large responses. Streamed
+	// requests are neither deduplicated nor cached.
+	Stream bool `json:"stream,omitempty"`
+}
+
+// requestOptions holds per-call settings of the shared request path
+type requestOptions struct {
+	headers map[string]string
+	timeout time.Duration
+}
+
+// requestOption customizes a single call of the shared request path
+type requestOption func(*requestOptions)
+
+// withHeaders adds headers to the request
+func withHeaders(headers map[string]string) requestOption {
+	return func(o *requestOptions) {
+		o.headers = headers
+	}
+}
+
+// withTimeout overrides the timeout of each attempt of the request
+func withTimeout(timeout time.Duration) requestOption {
+	return func(o *requestOptions) {
+		o.timeout = timeout
+	}
+}
+
+// Request sends an authenticated request to the API and returns the decoded
+// JSON response. An X-Request-ID header in req is used as the request ID
+// instead of a generated one.
+func (a *App) Request(req APIRequest) (_ any, err error) {
+	defer a.recoverPanic("Request", &err)
+
+	target := a.baseURL() + req.Path
+	if len(req.Query) > 0 {
+		query := url.Values{}
+		for key, value := range req.Query {
+			query.Set(key, value)
+		}
+		target += "?" + query.Encode()
+	}
+
+	// Honor a request ID chosen by the frontend
+	ctx := a.requestContext()
+	for name, value := range req.Headers {
+		if http.CanonicalHeaderKey(name) == http.CanonicalHeaderKey(requestIDHeader) && value != "" {
+			ctx = a.requestContextWithID(value)
+		}
+	}
+
+	// Response validation needs the whole body, so it disables streaming
+	do := a.doJSON
+	if req.Stream && !a.currentConfig().API.ValidateResponses {
+		do = a.streamJSON
+	}
+
+	var out any
+	err = do(ctx, req.Method, target, req.Body, &out,
+		withHeaders(req.Headers),
+		withTimeout(time.Duration(req.Timeout)*time.Second),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// doJSON sends a request with an optional JSON payload to the API and
+// decodes the JSON response into out. Errors carry the request ID of ctx.
+func (a *App) doJSON(ctx context.Context, method, url string, payload, out any, opts ...requestOption) (err error) {
+	defer func() {
+		err = withRequestIDError(ctx, err)
+	}()
+
+	var body []byte
+	if payload != nil {
+		if body, err = json.Marshal(payload); err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+	}
+
+	options := requestOptions{}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	resp, err := a.fetch(ctx, method, url, body, &options)
+	if err != nil {
+		return err
+	}
+
+	// Report error statuses with the message from the response envelope
+	if resp.statusCode >= http.StatusBadRequest {
+		apiErr := a.newAPIError(resp.statusCode)
+		_ = decodeJSON(resp.body, apiErr)
+		apiErr.StatusCode = resp.statusCode
+		return apiErr
+	}
+
+	if a.currentConfig().API.ValidateResponses {
+		if err := validateResponse(url, resp.body); err != nil {
+			return err
+		}
+	}
+
+	// Parse response
+	if err := decodeJSON(resp.body, out); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	return nil
+}
+
+// rawResponse is a fully read API response
+type rawResponse struct {
+	statusCode int
+	etag       string
+	body       []byte
+}
+
+// fetch sends the request and reads the response body. Concurrent identical
+// GET requests share a single in-flight call: the request runs under the app
+// request context rather than any single caller's and each caller stops
+// waiting when its own context is done.
+func (a *App) fetch(ctx context.Context, method, url string, body []byte, opts *requestOptions) (*rawResponse, error) {
+	if method != http.MethodGet || body != nil {
+		return a.fetchOnce(ctx, method, url, body, opts)
+	}
+
+	key := cacheKey(a.cacheIdentity(), requestKey(method, url, opts))
+	results := a.requestGroup.DoChan(a.dedupKey(method, url, opts), func() (any, error) {
+		// The shared call outlives its callers and carries the request
+		// ID of the first one
+		return a.fetchConditional(a.requestContextWithID(requestIDFrom(ctx)), key, url, opts)
+	})
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case result := <-results:
+		if result.Err != nil {
+			return nil, result.Err
+		}
+		return result.Val.(*rawResponse), nil
+	}
+}
+
+// fetchOnce sends the request and reads the response body
+func (a *App) fetchOnce(ctx context.Context, method, url string, body []byte, opts *requestOptions) (*rawResponse, error) {
+	resp, err := a.send(ctx, method, url, body, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	// Read response body
+	data, err := a.readResponseBody(resp)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	return &rawResponse{statusCode: resp.StatusCode, etag: resp.Header.Get("ETag"), body: data}, nil
+}
+
+// dedupKey identifies identical requests by method, URL, credentials and
+// per-call headers, leaving out headers that differ between calls
+func (a *App) dedupKey(method, url string, opts *requestOptions) string {
+	return a.authHeaderValue() + "\n" + requestKey(method, url, opts)
+}
+
+// requestKey identifies a request by method, URL and per-call headers,
+// leaving out headers that differ between calls
+func requestKey(method, url string, opts *requestOptions) string {
+	var key strings.Builder
+	key.WriteString(method + " " + url)
+
+	names := make([]string, 0, len(opts.headers))
+	for name := range opts.headers {
+		if !slices.Contains(volatileHeaders, http.CanonicalHeaderKey(name)) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		key.WriteString("\n" + http.CanonicalHeaderKey(name) + ": " + opts.headers[name])
+	}
+	return key.String()
+}
+
+// send issues the request with retry logic. A fresh request is built for
+// every attempt so the body is replayed in full on retries. A compressed
+// body is compressed once and the same bytes are replayed.
+//
+// Each attempt runs under a context deadline of the per-call timeout, or
+// api.timeout when none is given. The shared client has no timeout of its
+// own, so the context deadline is what bounds a call and a per-call timeout
+// may extend it beyond api.timeout.
+//
+// Each attempt holds a request slot until its response body is closed, so
+// no more than api.max_concurrent_requests attempts are in flight. Waiting
+// for a slot counts against ctx but not against the timeout.
+func (a *App) send(ctx context.Context, method, url string, body []byte, opts *requestOptions) (*http.Response, error) {
+	cfg := a.currentConfig()
+	timeout := opts.timeout
+	if timeout <= 0 {
+		timeout = cfg.API.Timeout
+	}
+	id := requestIDFrom(ctx)
+	if id == "" {
+		// Background calls such as the token refresh get their own ID
+		id = newRequestID()
+		ctx = withRequestID(ctx, id)
+	}
+	log := a.requestLogger(ctx)
+
+	wireBody, encoding, err := a.compressBody(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compress request body: %w", err)
+	}
+
+	var attempts []RetryAttempt
+	for attempt := 0; attempt <= cfg.API.RetryCount; attempt++ {
+		if err := a.waitRateLimit(ctx, cfg.Security); err != nil {
+			return nil, err
+		}
+		release, err := a.acquireSlot(ctx)
+		if err != nil {
+			return nil, err
+		}
+		attemptCtx, cancelAttempt := context.WithTimeout(ctx, timeout)
+		cancel := func() {
+			cancelAttempt()
+			release()
+		}
+
+		// Connection failures move on to the next endpoint within the
+		// attempt, the request never reached the server
+		var req *http.Request
+		var resp *http.Response
+		for _, target := range a.endpoints.targets(url, cfg.API.BaseURL, cfg.API.BaseURLs, a.clock.Now()) {
+			if req, err = a.newAttemptRequest(attemptCtx, method, target.url, body, wireBody, encoding, id, opts); err != nil {
+				cancel()
+				return nil, err
+			}
+			resp, err = a.do(req, body)
+			if target.base == "" {
+				break
+			}
+			if err == nil {
+				a.endpoints.markUp(target.base)
+				break
+			}
+			if !isConnectionError(err) || attemptCtx.Err() != nil {
+				break
+			}
+			a.endpoints.markDown(target.base, cfg.API.EndpointCooldown, a.clock.Now())
+			log.Warn("API endpoint unreachable", "method", method, "url", req.URL.Redacted(), "error", err)
+		}
+		if err == nil {
+			if err := a.hooks.afterResponse(resp); err != nil {
+				resp.Body.Close()
+				cancel()
+				return nil, fmt.Errorf("response hook failed: %w", err)
+			}
+		}
+		if err == nil && (resp.StatusCode < 500 || attempt == cfg.API.RetryCount) {
+			// Success, client error (don't retry) or final attempt. The
+			// deadline stays active until the body is closed.
+			if resp.StatusCode >= 500 {
+				a.metrics.recordFailure(method, req.URL.Path, RetryAttempt{StatusCode: resp.StatusCode}, false, a.clock.Now())
+			}
+			resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+			log.Debug("API request completed", "method", method, "url", req.URL.Redacted(), "status", resp.StatusCode, "attempt", attempt+1)
+			return resp, nil
+		}
+		failure := RetryAttempt{Err: err}
+		if err == nil {
+			failure.StatusCode = resp.StatusCode
+			resp.Body.Close()
+		}
+		attempts = append(attempts, failure)
+		cancel()
+		if err != nil && (ctx.Err() != nil || !isRetryableError(err)) {
+			a.metrics.recordFailure(method, req.URL.Path, failure, false, a.clock.Now())
+			log.Warn("API request failed", "method", method, "url", req.URL.Redacted(), "error", err)
+			return nil, fmt.Errorf("failed to send request: %w", err)
+		}
+
+		a.metrics.recordFailure(method, req.URL.Path, failure, attempt < cfg.API.RetryCount, a.clock.Now())
+		if attempt < cfg.API.RetryCount {
+			log.Warn("API request failed, retrying", "method", method, "url", req.URL.Redacted(), "attempt", attempt+1, "error", failure.String())
+			// Wait before retry
+			if !a.sleep(ctx, a.retryDelay()) {
+				return nil, ctx.Err()
+			}
+		}
+	}
+
+	return nil, &RetryExhaustedError{Attempts: attempts}
+}
+
+// newAttemptRequest builds the request of one attempt with its headers,
+// running the request hooks and signing it. body is the payload before
+// compression and wireBody the bytes sent.
+func (a *App) newAttemptRequest(ctx context.Context, method, url string, body, wireBody []byte, encoding, id string, opts *requestOptions) (*http.Request, error) {
+	cfg := a.currentConfig()
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(wireBody))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	// Set headers
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if encoding != "" {
+		req.Header.Set("Content-Encoding", encoding)
+	}
+	req.Header.Set("User-Agent", cfg.API.UserAgent)
+	req.Header.Set(requestIDHeader, id)
+	a.setAuthHeader(req)
+	for key, value := range cfg.API.DefaultHeaders {
+		req.Header.Set(key, value)
+	}
+	for key, value := range opts.headers {
+		req.Header.Set(key, value)
+	}
+	if err := a.hooks.beforeRequest(req); err != nil {
+		return nil, fmt.Errorf("request hook failed: %w", err)
+	}
+	// Signed after the hooks, so the signature covers the headers and URL
+	// they set. The signature covers the body as sent, compressed or not.
+	a.signRequest(req, wireBody)
+	return req, nil
+}
+
+// isRetryableError reports whether a transport error is likely transient:
+// DNS failures, failed dials, refused or reset connections and timeouts.
+// Other errors, such as malformed URLs, TLS alerts or certificate
+// verification failures, are not retried.
+func isRetryableError(err error) bool {
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return true
+	}
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return true
+	}
+	if errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// cancelOnClose releases the request context once the body is closed
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (c *cancelOnClose) Close() error {
+	err := c.ReadCloser.Close()
+	c.cancel()
+	return err
+}
+
+// readResponseBody reads the whole response body, enforcing the
+// MaxResponseBytes limit from the API configuration
+func (a *App) readResponseBody(resp *http.Response) ([]byte, error) {
+	limit := a.currentConfig().API.MaxResponseBytes
+	if limit <= 0 {
+		return io.ReadAll(resp.Body)
+	}
+	if resp.ContentLength > limit {
+		return nil, ErrResponseTooLarge
+	}
+
+	// Read one byte past the limit to detect oversized bodies without
+	// buffering them entirely
+	body, err := io.ReadAll(io.LimitReader(resp.Body, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(body)) > limit {
+		return nil, ErrResponseTooLarge
+	}
+	return body, nil
+}
+
+// sleep waits for d on the app clock or until ctx is done, reporting
+// whether the full duration elapsed
+func (a *App) sleep(ctx context.Context, d time.Duration) bool {
+	if d <= 0 {
+		return ctx.Err() == nil
+	}
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-a.clock.After(d):
+		return true
+	}
+}
@@ -0,0 +1,556 @@
+package main
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+	"wails-template/internal/clock"
+	"wails-template/internal/config"
+)
+
+func TestResponseSizeLimit(t *testing.T) {
+	const limit = 1 << 10
+	const bodySize = 64 << 20
+
+	var written atomic.Int64
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("length") == "1" {
+			w.Header().Set("Content-Length", strconv.Itoa(bodySize))
+		}
+		chunk := []byte(strings.Repeat("x", 32<<10))
+		for written.Load() < bodySize {
+			n, err := w.Write(chunk)
+			written.Add(int64(n))
+			if err != nil {
+				return
+			}
+		}
+	})
+
+	for _, withLength := range []bool{true, false} {
+		name := "chunked"
+		query := ""
+		if withLength {
+			name, query = "content-length", "?length=1"
+		}
+		t.Run(name, func(t *testing.T) {
+			app, srv := newTestApp(t, handler)
+			app.config.API.MaxResponseBytes = limit
+			written.Store(0)
+
+			var out any
+			err := app.doJSON(app.requestContext(), http.MethodPost, srv.URL+"/report"+query, nil, &out)
+			if !errors.Is(err, ErrResponseTooLarge) {
+				t.Fatalf("doJSON error = %v, want ErrResponseTooLarge", err)
+			}
+			// The body is abandoned after the limit instead of being read
+			// to the end
+			if n := written.Load(); n >= bodySize {
+				t.Errorf("server wrote the whole %d byte body", n)
+			}
+		})
+	}
+}
+
+func TestResponseSizeUnlimited(t *testing.T) {
+	body := `{"data":"` + strings.Repeat("x", 4<<10) + `"}`
+	app, srv := newTestApp(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, body)
+	}))
+	app.config.API.MaxResponseBytes = 0
+
+	var out map[string]string
+	if err := app.doJSON(app.requestContext(), http.MethodGet, srv.URL, nil, &out); err != nil {
+		t.Fatalf("doJSON: %v", err)
+	}
+	if len(out["data"]) != 4<<10 {
+		t.Errorf("decoded %d bytes, want %d", len(out["data"]), 4<<10)
+	}
+}
+
+// retryTestApp creates an app that retries twice with a one hour delay,
+// driven by a fake clock so the delay passes only when the test advances it
+func retryTestApp(t *testing.T, handler http.Handler) (*App, *clock.Fake) {
+	t.Helper()
+
+	app, _ := newTestApp(t, handler)
+	fake := clock.NewFake(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	app.clock = fake
+	app.config.API.RetryCount = 2
+	app.config.API.RetryDelay = time.Hour
+	return app, fake
+}
+
+func TestRetryWaitsOnClock(t *testing.T) {
+	var hits atomic.Int32
+	app, fake := retryTestApp(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if hits.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		io.WriteString(w, `{"ok":true}`)
+	}))
+
+	done := make(chan error, 1)
+	go func() {
+		var out map[string]any
+		done <- app.doJSON(context.Background(), http.MethodGet, app.config.API.BaseURL+"/items", nil, &out)
+	}()
+
+	for attempt := int32(1); attempt <= 2; attempt++ {
+		waitFor(t, "retry delay", func() bool { return hits.Load() == attempt && fake.Waiters() == 1 })
+		fake.Advance(time.Hour)
+	}
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("doJSON: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("request did not finish after the retry delays passed")
+	}
+	if hits.Load() != 3 {
+		t.Errorf("attempts = %d, want 3", hits.Load())
+	}
+}
+
+func TestRetryGivesUpAfterRetryCount(t *testing.T) {
+	var hits atomic.Int32
+	app, fake := retryTestApp(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+
+	done := make(chan error, 1)
+	go func() {
+		done <- app.doJSON(context.Background(), http.MethodGet, app.config.API.BaseURL+"/items", nil, nil)
+	}()
+	for attempt := int32(1); attempt <= 2; attempt++ {
+		waitFor(t, "retry delay", func() bool { return hits.Load() == attempt && fake.Waiters() == 1 })
+		fake.Advance(time.Hour)
+	}
+
+	var apiErr *APIError
+	if err := <-done; !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadGateway {
+		t.Fatalf("error = %v, want APIError with status 502", err)
+	}
+	if hits.Load() != 3 {
+		t.Errorf("attempts = %d, want RetryCount+1 = 3", hits.Load())
+	}
+}
+
+func TestRetryDelayCancelled(t *testing.T) {
+	var hits atomic.Int32
+	app, fake := retryTestApp(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- app.doJSON(ctx, http.MethodGet, app.config.API.BaseURL+"/items", nil, nil)
+	}()
+	waitFor(t, "retry delay", func() bool { return fake.Waiters() == 1 })
+	cancel()
+
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("error = %v, want context.Canceled", err)
+	}
+	if hits.Load() != 1 {
+		t.Errorf("attempts = %d, want no retry after cancellation", hits.Load())
+	}
+}
+
+func TestBaseURLTenantSubstitution(t *testing.T) {
+	app, _ := newTestApp(t, http.NotFoundHandler())
+	app.config.API.BaseURL = "https://api.example.com"
+
+	tests := []struct {
+		name     string
+		template string
+		tenant   string
+		loggedIn bool
+		want     string
+	}{
+		{"no template", "", "acme", true, "https://api.example.com"},
+		{"logged out", "https://{tenant}.api.example.com", "", false, "https://api.example.com"},
+		{"no tenant", "https://{tenant}.api.example.com", "", true, "https://api.example.com"},
+		{"tenant", "https://{tenant}.api.example.com", "acme", true, "https://acme.api.example.com"},
+		{"escaped", "https://api.example.com/t/{tenant}", "a b/c", true, "https://api.example.com/t/a%20b%2Fc"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app.config.API.TenantURLTemplate = tt.template
+			app.session = nil
+			if tt.loggedIn {
+				app.session = &session{user: User{CurrentTenantID: ID(tt.tenant)}}
+			}
+			if got := app.baseURL(); got != tt.want {
+				t.Errorf("baseURL() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequestUsesTenantURL(t *testing.T) {
+	var paths []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/identity/login", func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"success":true,"data":{"access_token":"a","user":{"id":"u1","current_tenant_id":"acme"}}}`)
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		io.WriteString(w, `{}`)
+	})
+	app, srv := newTestApp(t, mux)
+	app.config.API.TenantURLTemplate = srv.URL + "/tenants/{tenant}"
+
+	if _, err := app.Request(APIRequest{Method: http.MethodGet, Path: "/items"}); err != nil {
+		t.Fatalf("Request before login: %v", err)
+	}
+	if _, err := app.Login("admin", "secret"); err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	if _, err := app.Request(APIRequest{Method: http.MethodGet, Path: "/items"}); err != nil {
+		t.Fatalf("Request after login: %v", err)
+	}
+
+	want := []string{"/items", "/tenants/acme/items"}
+	if strings.Join(paths, " ") != strings.Join(want, " ") {
+		t.Errorf("request paths = %v, want %v", paths, want)
+	}
+}
+
+// slowHandler answers after delay unless the client gives up first
+func slowHandler(delay time.Duration) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(delay):
+			io.WriteString(w, `{}`)
+		case <-r.Context().Done():
+		}
+	})
+}
+
+func TestPerCallTimeoutExtends(t *testing.T) {
+	app, _ := newTestApp(t, slowHandler(300*time.Millisecond))
+	app.config.API.Timeout = 100 * time.Millisecond
+
+	_, err := app.Request(APIRequest{Method: http.MethodGet, Path: "/report"})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Request with the default timeout: error = %v, want a deadline error", err)
+	}
+
+	if _, err := app.Request(APIRequest{Method: http.MethodGet, Path: "/report", Timeout: 2}); err != nil {
+		t.Errorf("Request with an extended timeout: %v", err)
+	}
+}
+
+func TestPerCallTimeoutShortens(t *testing.T) {
+	app, _ := newTestApp(t, slowHandler(time.Second))
+
+	start := time.Now()
+	err := app.doJSON(context.Background(), http.MethodGet, app.config.API.BaseURL+"/report", nil, nil, withTimeout(50*time.Millisecond))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("error = %v, want a deadline error", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("request took %v, want the shortened timeout to apply", elapsed)
+	}
+}
+
+// concurrentRequests issues the n requests built by req at once and returns
+// their errors once all have finished
+func concurrentRequests(app *App, n int, req func(i int) APIRequest) []error {
+	errs := make([]error, n)
+	var wg sync.WaitGroup
+	for i := range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, errs[i] = app.Request(req(i))
+		}()
+	}
+	wg.Wait()
+	return errs
+}
+
+// heldHandler counts requests and answers each with status after a short
+// delay, so concurrent identical calls overlap
+func heldHandler(hits *atomic.Int32, status int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		time.Sleep(200 * time.Millisecond)
+		w.WriteHeader(status)
+		io.WriteString(w, `{"id":"u1"}`)
+	})
+}
+
+func TestConcurrentGETsShareOneRequest(t *testing.T) {
+	var hits atomic.Int32
+	app, _ := newTestApp(t, heldHandler(&hits, http.StatusOK))
+
+	errs := concurrentRequests(app, 10, func(i int) APIRequest {
+		// Request IDs differ per call and must not split the requests
+		return APIRequest{Method: http.MethodGet, Path: "/identity/me", Headers: map[string]string{"X-Request-Id": strconv.Itoa(i)}}
+	})
+	for _, err := range errs {
+		if err != nil {
+			t.Fatalf("Request: %v", err)
+		}
+	}
+	if hits.Load() != 1 {
+		t.Errorf("server saw %d requests, want 1", hits.Load())
+	}
+}
+
+func TestConcurrentGETsShareErrors(t *testing.T) {
+	var hits atomic.Int32
+	app, _ := newTestApp(t, heldHandler(&hits, http.StatusInternalServerError))
+
+	errs := concurrentRequests(app, 10, func(int) APIRequest {
+		return APIRequest{Method: http.MethodGet, Path: "/identity/me"}
+	})
+	for _, err := range errs {
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusInternalServerError {
+			t.Errorf("error = %v, want the shared APIError", err)
+		}
+	}
+	if hits.Load() != 1 {
+		t.Errorf("server saw %d requests, want 1", hits.Load())
+	}
+}
+
+func TestRequestsNotDeduplicated(t *testing.T) {
+	var hits atomic.Int32
+	app, _ := newTestApp(t, heldHandler(&hits, http.StatusOK))
+
+	// Differing headers, differing paths and POSTs each reach the server
+	concurrentRequests(app, 6, func(i int) APIRequest {
+		switch i {
+		case 0, 1:
+			return APIRequest{Method: http.MethodGet, Path: "/items", Headers: map[string]string{"Accept-Language": strconv.Itoa(i)}}
+		case 2, 3:
+			return APIRequest{Method: http.MethodGet, Path: "/items/" + strconv.Itoa(i)}
+		default:
+			return APIRequest{Method: http.MethodPost, Path: "/items", Body: map[string]int{"n": i}}
+		}
+	})
+	if hits.Load() != 6 {
+		t.Errorf("server saw %d requests, want 6", hits.Load())
+	}
+}
+
+func TestDefaultHeaders(t *testing.T) {
+	received := make(chan http.Header, 1)
+	app, _ := newTestApp(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received <- r.Header.Clone()
+		io.WriteString(w, `{}`)
+	}))
+	app.config.API.DefaultHeaders = map[string]string{
+		"X-Client-Version": "1.2.3",
+		"X-Api-Key":        "default-key",
+	}
+
+	if _, err := app.Request(APIRequest{Method: http.MethodGet, Path: "/items"}); err != nil {
+		t.Fatalf("Request: %v", err)
+	}
+	header := <-received
+	if header.Get("X-Client-Version") != "1.2.3" || header.Get("X-Api-Key") != "default-key" {
+		t.Errorf("default headers missing on the wire: %v", header)
+	}
+
+	_, err := app.Request(APIRequest{Method: http.MethodGet, Path: "/other", Headers: map[string]string{"x-api-key": "call-key"}})
+	if err != nil {
+		t.Fatalf("Request: %v", err)
+	}
+	header = <-received
+	if got := header.Values("X-Api-Key"); len(got) != 1 || got[0] != "call-key" {
+		t.Errorf("X-Api-Key = %q, want the per-call value only", got)
+	}
+	if header.Get("X-Client-Version") != "1.2.3" {
+		t.Error("overriding one header dropped the other defaults")
+	}
+}
+
+func TestIsRetryableError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"dns", &net.DNSError{Err: "no such host", Name: "api.invalid"}, true},
+		{"dial", &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("network is unreachable")}, true},
+		{"refused", &net.OpError{Op: "read", Net: "tcp", Err: os.NewSyscallError("read", syscall.ECONNREFUSED)}, true},
+		{"reset", &url.Error{Op: "Get", URL: "http://api", Err: &net.OpError{Op: "read", Net: "tcp", Err: os.NewSyscallError("read", syscall.ECONNRESET)}}, true},
+		{"timeout", &url.Error{Op: "Get", URL: "http://api", Err: context.DeadlineExceeded}, true},
+		{"tls alert", &net.OpError{Op: "remote error", Err: errors.New("tls: handshake failure")}, false},
+		{"certificate", &url.Error{Op: "Get", URL: "https://api", Err: x509.UnknownAuthorityError{}}, false},
+		{"malformed", &url.Error{Op: "parse", URL: "::", Err: errors.New("missing protocol scheme")}, false},
+	}
+	for _, tt := range tests {
+		if got := isRetryableError(tt.err); got != tt.want {
+			t.Errorf("%s: isRetryableError(%v) = %v, want %v", tt.name, tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestRetryUntilServerIsUp(t *testing.T) {
+	app, _ := newTestApp(t, http.NotFoundHandler())
+	fake := clock.NewFake(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	app.clock = fake
+	app.config.API.RetryCount = 3
+	app.config.API.RetryDelay = time.Second
+
+	// Reserve a port and close it so the first attempt is refused
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := listener.Addr().String()
+	listener.Close()
+	app.config.API.BaseURL = "http://" + addr
+
+	done := make(chan error, 1)
+	go func() {
+		var out map[string]any
+		done <- app.doJSON(context.Background(), http.MethodGet, app.config.API.BaseURL+"/health", nil, &out)
+	}()
+
+	// The refused attempt waits for the retry delay
+	waitFor(t, "retry delay", func() bool { return fake.Waiters() == 1 })
+
+	listener, err = net.Listen("tcp", addr)
+	if err != nil {
+		t.Skipf("port %s was taken in the meantime: %v", addr, err)
+	}
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"status":"ok"}`)
+	}))
+	srv.Listener.Close()
+	srv.Listener = listener
+	srv.Start()
+	defer srv.Close()
+
+	fake.Advance(time.Second)
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("request failed after the server came up: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("request did not retry")
+	}
+}
+
+func TestNoRetryOnTLSFailure(t *testing.T) {
+	var handshakes atomic.Int32
+	srv := httptest.NewUnstartedServer(http.NotFoundHandler())
+	srv.Config.ConnState = func(c net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			handshakes.Add(1)
+		}
+	}
+	srv.StartTLS()
+	defer srv.Close()
+
+	app, _ := newTestApp(t, http.NotFoundHandler())
+	app.client = &http.Client{}
+	app.config.API.RetryCount = 3
+	app.config.API.RetryDelay = time.Hour
+
+	err := app.doJSON(context.Background(), http.MethodGet, srv.URL, nil, nil)
+	if err == nil {
+		t.Fatal("request to an untrusted server succeeded")
+	}
+	if handshakes.Load() != 1 {
+		t.Errorf("connections = %d, want a certificate failure not retried", handshakes.Load())
+	}
+}
+
+func TestRetryExhaustedErrorHistory(t *testing.T) {
+	var hits atomic.Int32
+	app, _ := newTestApp(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The server only notices the client giving up once the body has
+		// been read
+		io.Copy(io.Discard, r.Body)
+
+		switch hits.Add(1) {
+		case 1:
+			w.WriteHeader(http.StatusBadGateway)
+		case 2:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			<-r.Context().Done()
+		}
+	}))
+	app.config.API.RetryCount = 2
+	app.config.API.Timeout = 100 * time.Millisecond
+
+	_, err := app.Login("admin", "password")
+	var exhausted *RetryExhaustedError
+	if !errors.As(err, &exhausted) {
+		t.Fatalf("Login error = %v, want a RetryExhaustedError", err)
+	}
+	if len(exhausted.Attempts) != 3 {
+		t.Fatalf("attempts = %d, want 3", len(exhausted.Attempts))
+	}
+	if got := []int{exhausted.Attempts[0].StatusCode, exhausted.Attempts[1].StatusCode}; got[0] != 502 || got[1] != 503 {
+		t.Errorf("status codes = %v, want [502 503]", got)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Login error = %v, want it to unwrap to the last attempt's deadline error", err)
+	}
+	if !strings.Contains(err.Error(), "(502, 503, timeout)") {
+		t.Errorf("error = %q, want the attempt history", err)
+	}
+}
+
+func TestAPIErrorUserMessage(t *testing.T) {
+	app, _ := newTestApp(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		io.WriteString(w, `{"code":"`+strings.TrimPrefix(r.URL.Path, "/")+`","message":"raw message"}`)
+	}))
+	app.messages = config.MessageCatalog{
+		"default": {"LOCKED": "Account locked"},
+		"vi":      {"LOCKED": "Tài khoản bị khóa"},
+	}
+
+	tests := []struct {
+		locale, code, want string
+	}{
+		{"en", "LOCKED", "Account locked"},
+		{"vi-VN", "LOCKED", "Tài khoản bị khóa"},
+		{"vi-VN", "UNMAPPED", "raw message"},
+	}
+	for _, tt := range tests {
+		app.config.App.Locale = tt.locale
+		_, err := app.Request(APIRequest{Method: http.MethodGet, Path: "/" + tt.code})
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) {
+			t.Fatalf("error = %v, want an APIError", err)
+		}
+		if got := apiErr.UserMessage(); got != tt.want {
+			t.Errorf("%s in %s: UserMessage = %q, want %q", tt.code, tt.locale, got, tt.want)
+		}
+		if apiErr.Error() != "raw message" {
+			t.Errorf("Error() = %q, want the API message", apiErr.Error())
+		}
+	}
+}
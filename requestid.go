@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/google/uuid"
+)
+
+// requestIDHeader carries the request ID on outbound API calls
+const requestIDHeader = "X-Request-ID"
+
+type requestIDKey struct{}
+
+// withRequestID returns a copy of ctx carrying the request ID id
+func withRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// requestIDFrom returns the request ID carried by ctx, if any
+func requestIDFrom(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// newRequestID generates a request ID
+func newRequestID() string {
+	return uuid.NewString()
+}
+
+// RequestError annotates an error returned to the frontend with the ID of
+// the request that failed, so users can quote it in bug reports
+type RequestError struct {
+	RequestID string
+	Err       error
+}
+
+func (e *RequestError) Error() string {
+	return fmt.Sprintf("%v (request ID %s)", e.Err, e.RequestID)
+}
+
+func (e *RequestError) Unwrap() error {
+	return e.Err
+}
+
+// withRequestIDError wraps err in a RequestError carrying the request ID of
+// ctx. Errors without a request ID or already carrying one are returned
+// unchanged.
+func withRequestIDError(ctx context.Context, err error) error {
+	id := requestIDFrom(ctx)
+	if err == nil || id == "" {
+		return err
+	}
+	if _, ok := err.(*RequestError); ok {
+		return err
+	}
+	return &RequestError{RequestID: id, Err: err}
+}
+
+// requestLogger returns the app logger with the request ID of ctx attached,
+// so all records of a call share it
+func (a *App) requestLogger(ctx context.Context) *slog.Logger {
+	if id := requestIDFrom(ctx); id != "" {
+		return a.logger.With("request_id", id)
+	}
+	return a.logger.Logger
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+// requestIDHandler answers with status and records the X-Request-ID of
+// each request
+func requestIDHandler(ids chan<- string, status int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ids <- r.Header.Get(requestIDHeader)
+		w.WriteHeader(status)
+		io.WriteString(w, `{"success":true,"data":{"access_token":"access-1","expires_in":3600}}`)
+	})
+}
+
+func TestRequestIDHeader(t *testing.T) {
+	ids := make(chan string, 2)
+	app, _ := newTestApp(t, requestIDHandler(ids, http.StatusOK))
+
+	for range 2 {
+		if _, err := app.Request(APIRequest{Method: http.MethodPost, Path: "/items"}); err != nil {
+			t.Fatalf("Request: %v", err)
+		}
+	}
+	first, second := <-ids, <-ids
+	if uuid.Validate(first) != nil || uuid.Validate(second) != nil {
+		t.Errorf("request IDs %q and %q are not UUIDs", first, second)
+	}
+	if first == second {
+		t.Error("two calls share a request ID")
+	}
+}
+
+func TestRequestIDFromFrontend(t *testing.T) {
+	ids := make(chan string, 1)
+	app, _ := newTestApp(t, requestIDHandler(ids, http.StatusOK))
+
+	headers := map[string]string{"x-request-id": "frontend-1"}
+	if _, err := app.Request(APIRequest{Method: http.MethodPost, Path: "/items", Headers: headers}); err != nil {
+		t.Fatalf("Request: %v", err)
+	}
+	if id := <-ids; id != "frontend-1" {
+		t.Errorf("X-Request-ID = %q, want frontend-1", id)
+	}
+
+	if _, err := app.LoginWithID("login-7", "admin", "secret"); err != nil {
+		t.Fatalf("LoginWithID: %v", err)
+	}
+	if id := <-ids; id != "login-7" {
+		t.Errorf("X-Request-ID = %q, want login-7", id)
+	}
+}
+
+func TestRequestIDInLogs(t *testing.T) {
+	ids := make(chan string, 1)
+	app, _ := newTestApp(t, requestIDHandler(ids, http.StatusOK))
+
+	if _, err := app.Request(APIRequest{Method: http.MethodPost, Path: "/items"}); err != nil {
+		t.Fatalf("Request: %v", err)
+	}
+	id := <-ids
+	for _, record := range app.logger.Recent(slog.LevelDebug) {
+		if record.Message == "API request completed" && record.Attrs["request_id"] == id {
+			return
+		}
+	}
+	t.Errorf("no log record of the call with request_id %s", id)
+}
+
+func TestRequestIDInErrors(t *testing.T) {
+	ids := make(chan string, 1)
+	app, _ := newTestApp(t, requestIDHandler(ids, http.StatusBadRequest))
+
+	_, err := app.Request(APIRequest{Method: http.MethodPost, Path: "/items"})
+	id := <-ids
+
+	var requestErr *RequestError
+	if !errors.As(err, &requestErr) || requestErr.RequestID != id {
+		t.Fatalf("error = %v, want a RequestError with ID %s", err, id)
+	}
+	if !strings.Contains(err.Error(), id) {
+		t.Errorf("error message %q does not include the request ID", err)
+	}
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadRequest {
+		t.Errorf("error = %v, want it to wrap the APIError", err)
+	}
+}
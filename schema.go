@@ -0,0 +1,138 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// ErrResponseSchema is returned when response validation is enabled and an
+// API response doesn't have the expected shape
+var ErrResponseSchema = errors.New("response does not match the expected schema")
+
+// jsonKind is the JSON type expected for a response field
+type jsonKind string
+
+const (
+	jsonString jsonKind = "string"
+	jsonNumber jsonKind = "number"
+	jsonBool   jsonKind = "boolean"
+	jsonObject jsonKind = "object"
+	jsonArray  jsonKind = "array"
+)
+
+// schemaField is a required response field, addressed by its dotted path
+// in the JSON document
+type schemaField struct {
+	path string
+	kind jsonKind
+}
+
+// responseSchema lists the required fields of a response
+type responseSchema []schemaField
+
+var (
+	schemasMu       sync.RWMutex
+	responseSchemas = map[string]responseSchema{
+		"/identity/login": {
+			{path: "data.access_token", kind: jsonString},
+			{path: "data.expires_in", kind: jsonNumber},
+		},
+	}
+)
+
+// registerResponseSchema registers the expected shape of the responses of
+// an endpoint, identified by its path relative to the API base URL
+func registerResponseSchema(endpoint string, schema responseSchema) {
+	schemasMu.Lock()
+	defer schemasMu.Unlock()
+	responseSchemas[endpoint] = schema
+}
+
+// validateResponse checks body against the schema registered for the
+// endpoint of target. Responses of endpoints without a schema pass.
+func validateResponse(target string, body []byte) error {
+	schema := lookupResponseSchema(target)
+	if schema == nil {
+		return nil
+	}
+
+	var doc any
+	if err := decodeJSON(body, &doc); err != nil {
+		return fmt.Errorf("%w: %v", ErrResponseSchema, err)
+	}
+
+	// Unsuccessful envelopes carry no data and are reported by the caller
+	if success, ok := lookupJSONPath(doc, "success"); ok && success == false {
+		return nil
+	}
+
+	var problems []string
+	for _, field := range schema {
+		value, ok := lookupJSONPath(doc, field.path)
+		switch {
+		case !ok:
+			problems = append(problems, fmt.Sprintf("%s is missing", field.path))
+		case kindOf(value) != field.kind:
+			problems = append(problems, fmt.Sprintf("%s must be a %s, got %s", field.path, field.kind, kindOf(value)))
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%w: %s", ErrResponseSchema, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// lookupResponseSchema finds the schema whose endpoint the path of target
+// ends with
+func lookupResponseSchema(target string) responseSchema {
+	u, err := url.Parse(target)
+	if err != nil {
+		return nil
+	}
+
+	schemasMu.RLock()
+	defer schemasMu.RUnlock()
+	for endpoint, schema := range responseSchemas {
+		if strings.HasSuffix(u.Path, endpoint) {
+			return schema
+		}
+	}
+	return nil
+}
+
+// lookupJSONPath resolves a dotted path in a decoded JSON document
+func lookupJSONPath(doc any, path string) (any, bool) {
+	current := doc
+	for _, key := range strings.Split(path, ".") {
+		object, ok := current.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if current, ok = object[key]; !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// kindOf returns the JSON type of a decoded value
+func kindOf(value any) jsonKind {
+	switch value.(type) {
+	case string:
+		return jsonString
+	case json.Number:
+		return jsonNumber
+	case bool:
+		return jsonBool
+	case map[string]any:
+		return jsonObject
+	case []any:
+		return jsonArray
+	default:
+		return "null"
+	}
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// loginServer answers logins with body
+func loginServer(body string) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/identity/login", func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, body)
+	})
+	return mux
+}
+
+func TestLoginResponseValidation(t *testing.T) {
+	app, _ := newTestApp(t, loginServer(loginResponseJSON))
+	app.config.API.ValidateResponses = true
+
+	if _, err := app.Login("admin", "secret"); err != nil {
+		t.Errorf("Login with a valid response: %v", err)
+	}
+}
+
+func TestLoginResponseMissingField(t *testing.T) {
+	const body = `{"success":true,"data":{"access_token":"access-1","token_type":"Bearer"}}`
+
+	app, _ := newTestApp(t, loginServer(body))
+	app.config.API.ValidateResponses = true
+
+	_, err := app.Login("admin", "secret")
+	if !errors.Is(err, ErrResponseSchema) {
+		t.Fatalf("error = %v, want ErrResponseSchema", err)
+	}
+	if !strings.Contains(err.Error(), "data.expires_in") {
+		t.Errorf("error %q does not name the missing field", err)
+	}
+
+	// Without validation the same response is accepted
+	app.config.API.ValidateResponses = false
+	if _, err := app.Login("admin", "secret"); err != nil {
+		t.Errorf("Login without validation: %v", err)
+	}
+}
+
+func TestValidateResponse(t *testing.T) {
+	registerResponseSchema("/test/schema", responseSchema{
+		{path: "data.id", kind: jsonString},
+		{path: "data.count", kind: jsonNumber},
+		{path: "data.tags", kind: jsonArray},
+		{path: "data.active", kind: jsonBool},
+	})
+	t.Cleanup(func() {
+		schemasMu.Lock()
+		delete(responseSchemas, "/test/schema")
+		schemasMu.Unlock()
+	})
+
+	tests := []struct {
+		name    string
+		body    string
+		wantErr string
+	}{
+		{"valid", `{"data":{"id":"x","count":2,"tags":[],"active":false}}`, ""},
+		{"wrong type", `{"data":{"id":1,"count":2,"tags":[],"active":false}}`, "data.id"},
+		{"null", `{"data":{"id":"x","count":null,"tags":[],"active":false}}`, "data.count"},
+		{"missing", `{"data":{"id":"x","count":2,"active":false}}`, "data.tags"},
+		{"not json", `<html>`, "invalid"},
+		{"unsuccessful envelope", `{"success":false,"message":"invalid credentials"}`, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateResponse("https://api.example.com/v1/test/schema?page=2", []byte(tt.body))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("validateResponse: %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrResponseSchema) || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want ErrResponseSchema containing %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	if err := validateResponse("https://api.example.com/unregistered", []byte(`{}`)); err != nil {
+		t.Errorf("endpoint without a schema: %v", err)
+	}
+}
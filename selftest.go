@@ -0,0 +1,252 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"time"
+	"wails-template/internal/config"
+	"wails-template/internal/db"
+)
+
+// selfTestTimeout bounds each connectivity check of the self-test
+const selfTestTimeout = 5 * time.Second
+
+// SelfTestCheck is the result of a single startup check. An abandoned
+// check ran out of startup time; it neither passed nor failed. A failed
+// check that isn't critical is a warning and doesn't fail the self-test.
+type SelfTestCheck struct {
+	Name      string `json:"name"`
+	Critical  bool   `json:"critical"`
+	Passed    bool   `json:"passed"`
+	Abandoned bool   `json:"abandoned,omitempty"`
+	Message   string `json:"message,omitempty"`
+}
+
+// SelfTestResult aggregates the startup checks
+type SelfTestResult struct {
+	Passed bool            `json:"passed"`
+	Checks []SelfTestCheck `json:"checks"`
+}
+
+// StartupTimeoutEvent is the payload of the startup:timeout event, emitted
+// when checks were abandoned because app.startup_timeout was spent
+type StartupTimeoutEvent struct {
+	Abandoned []string `json:"abandoned"`
+}
+
+// selfTestCheck is a check run by the self-test. Remote checks depend on
+// other hosts and are abandoned once the startup budget is spent, local
+// checks always run to completion.
+type selfTestCheck struct {
+	name     string
+	enabled  bool
+	remote   bool
+	critical bool
+	run      func() error
+}
+
+// SelfTest checks the preconditions the app needs to run: a valid
+// configuration, a writable log file and, when enabled, API and database
+// connectivity. Each check is emitted as a selftest:check event and the
+// aggregated result as selftest:complete.
+func (a *App) SelfTest() *SelfTestResult {
+	return a.selfTest(context.Background(), a.selfTestChecks())
+}
+
+// selfTestChecks returns the checks of the self-test in the order they run
+func (a *App) selfTestChecks() []selfTestCheck {
+	cfg := a.currentConfig()
+	return []selfTestCheck{
+		{"config", true, false, true, a.checkConfig},
+		{"log", true, false, true, a.checkLogWritable},
+		{"base_url", cfg.API.VerifyBaseURLOnStart, true, cfg.App.FailFast, a.checkBaseURLReachable},
+		{"api", cfg.App.SelfTestAPI, true, true, a.checkAPIReachable},
+		{"database", cfg.App.SelfTestDatabase, true, true, a.checkDatabaseReachable},
+	}
+}
+
+// selfTest runs checks like SelfTest. Remote checks still running when ctx
+// is done are abandoned, which is logged and reported as startup:timeout
+// but doesn't fail the self-test.
+func (a *App) selfTest(ctx context.Context, checks []selfTestCheck) *SelfTestResult {
+	result := &SelfTestResult{Passed: true}
+	var abandoned []string
+	for _, c := range checks {
+		if !c.enabled {
+			continue
+		}
+
+		check := SelfTestCheck{Name: c.name, Critical: c.critical, Passed: true}
+		run := c.run
+		if c.remote {
+			run = func() error { return runUntil(ctx, c.run) }
+		}
+		switch err := run(); {
+		case errors.Is(err, errCheckAbandoned):
+			check.Passed = false
+			check.Abandoned = true
+			check.Message = err.Error()
+			abandoned = append(abandoned, c.name)
+			a.logger.Warn("Self-test check abandoned after the startup timeout", "check", c.name, "timeout", a.currentConfig().App.StartupTimeout)
+		case err != nil && !c.critical:
+			check.Passed = false
+			check.Message = err.Error()
+			a.logger.Warn("Self-test check failed", "check", c.name, "message", check.Message)
+		case err != nil:
+			check.Passed = false
+			check.Message = err.Error()
+			result.Passed = false
+		}
+		result.Checks = append(result.Checks, check)
+		a.emitEvent("selftest:check", check)
+	}
+
+	if len(abandoned) > 0 {
+		a.emitEvent("startup:timeout", StartupTimeoutEvent{Abandoned: abandoned})
+	}
+	a.emitEvent("selftest:complete", result)
+	return result
+}
+
+// runBackgroundChecks runs the enabled checks concurrently in the
+// background, emitting each result as a diagnostics:result event as it
+// completes. Checks still running when the app is closed are abandoned.
+func (a *App) runBackgroundChecks(checks []selfTestCheck) {
+	for _, c := range checks {
+		if !c.enabled {
+			continue
+		}
+		a.goBackground(func(ctx context.Context) {
+			check := SelfTestCheck{Name: c.name, Critical: c.critical, Passed: true}
+			err := runUntil(ctx, c.run)
+			switch {
+			case errors.Is(err, errCheckAbandoned):
+				return
+			case err != nil:
+				check.Passed = false
+				check.Message = err.Error()
+				a.logger.Warn("Background self-test check failed", "check", c.name, "message", check.Message)
+			}
+			a.emitEvent("diagnostics:result", check)
+		})
+	}
+}
+
+// errCheckAbandoned is returned by runUntil for a check that was still
+// running when its context was done
+var errCheckAbandoned = errors.New("check abandoned after the startup timeout")
+
+// runUntil runs check and returns its error, or errCheckAbandoned once ctx
+// is done. An abandoned check keeps running in the background until its
+// own timeout ends it.
+func runUntil(ctx context.Context, check func() error) error {
+	if ctx.Err() != nil {
+		return errCheckAbandoned
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- check()
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return errCheckAbandoned
+	}
+}
+
+// checkConfig validates the loaded configuration
+func (a *App) checkConfig() error {
+	return config.Validate(a.currentConfig())
+}
+
+// checkLogWritable verifies the log file can be opened for writing when
+// file logging is enabled
+func (a *App) checkLogWritable() error {
+	cfg := a.currentConfig()
+	if !cfg.Log.Output.Has(config.LogOutputFile) {
+		return nil
+	}
+
+	file, err := os.OpenFile(cfg.Log.FilePath, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return fmt.Errorf("log file is not writable: %w", err)
+	}
+	return file.Close()
+}
+
+// checkAPIReachable verifies the API answers HTTP requests. Without an
+// api.health_path any response from the base URL, regardless of status,
+// counts as reachable. With one, the health endpoint must answer with one
+// of api.health_expected_status.
+func (a *App) checkAPIReachable() error {
+	ctx, cancel := context.WithTimeout(a.requestContext(), selfTestTimeout)
+	defer cancel()
+
+	api := a.currentConfig().API
+	target := api.BaseURL
+	if api.HealthPath != "" {
+		target = strings.TrimSuffix(api.BaseURL, "/") + "/" + strings.TrimPrefix(api.HealthPath, "/")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	resp, err := a.currentClient().Do(req)
+	if err != nil {
+		return fmt.Errorf("API is not reachable: %w", err)
+	}
+	resp.Body.Close()
+
+	if api.HealthPath != "" && !slices.Contains(api.HealthExpectedStatus, resp.StatusCode) {
+		return fmt.Errorf("API health check %s returned status %d, want one of %v", api.HealthPath, resp.StatusCode, api.HealthExpectedStatus)
+	}
+	return nil
+}
+
+// checkBaseURLReachable verifies the host of the API base URL resolves and
+// accepts TCP connections, without sending a request
+func (a *App) checkBaseURLReachable() error {
+	ctx, cancel := context.WithTimeout(a.requestContext(), selfTestTimeout)
+	defer cancel()
+
+	base, err := url.Parse(a.currentConfig().API.BaseURL)
+	if err != nil {
+		return fmt.Errorf("invalid API base URL: %w", err)
+	}
+	host, port := base.Hostname(), base.Port()
+	if port == "" {
+		port = "443"
+		if base.Scheme == "http" {
+			port = "80"
+		}
+	}
+
+	if _, err := net.DefaultResolver.LookupHost(ctx, host); err != nil {
+		return fmt.Errorf("API host does not resolve: %w", err)
+	}
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(host, port))
+	if err != nil {
+		return fmt.Errorf("API host is not reachable: %w", err)
+	}
+	return conn.Close()
+}
+
+// checkDatabaseReachable opens the database pool, which logs in and pings
+// the database
+func (a *App) checkDatabaseReachable() error {
+	pool, err := db.OpenDatabase(a.currentConfig().Database)
+	if err != nil {
+		return err
+	}
+	return pool.Close()
+}
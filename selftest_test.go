@@ -0,0 +1,306 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"wails-template/internal/config"
+)
+
+func TestSelfTestPass(t *testing.T) {
+	app, _ := newTestApp(t, http.NotFoundHandler())
+	app.config.App.FailFast = true
+	app.config.App.SelfTestAPI = true
+	app.config.App.SelfTestDatabase = false
+	app.config.Log.Output = config.LogOutputFile
+	app.config.Log.FilePath = filepath.Join(t.TempDir(), "app.log")
+
+	result := app.SelfTest()
+	if !result.Passed {
+		t.Fatalf("SelfTest failed: %+v", result.Checks)
+	}
+	names := make([]string, 0, len(result.Checks))
+	for _, check := range result.Checks {
+		names = append(names, check.Name)
+	}
+	if len(names) != 3 || names[0] != "config" || names[1] != "log" || names[2] != "api" {
+		t.Errorf("checks = %v, want config, log and api", names)
+	}
+
+	if !app.runSelfTest() || app.exitCode != 0 {
+		t.Errorf("runSelfTest aborted a passing self-test, exit code %d", app.exitCode)
+	}
+}
+
+func TestSelfTestFailFast(t *testing.T) {
+	app, srv := newTestApp(t, http.NotFoundHandler())
+	app.config.App.SelfTestAPI = true
+	app.config.App.SelfTestDatabase = false
+	srv.Close()
+
+	result := app.SelfTest()
+	if result.Passed {
+		t.Fatal("SelfTest passed with the API down")
+	}
+	for _, check := range result.Checks {
+		if check.Name == "api" && (check.Passed || check.Message == "") {
+			t.Errorf("api check = %+v, want a failure with a message", check)
+		}
+	}
+
+	app.config.App.FailFast = false
+	if !app.runSelfTest() || app.exitCode != 0 {
+		t.Errorf("runSelfTest aborted without fail-fast, exit code %d", app.exitCode)
+	}
+
+	app.config.App.FailFast = true
+	if app.runSelfTest() {
+		t.Error("runSelfTest did not abort with fail-fast")
+	}
+	if app.exitCode != 1 {
+		t.Errorf("exit code = %d, want 1", app.exitCode)
+	}
+}
+
+func TestSelfTestStartupTimeout(t *testing.T) {
+	handler, started := blockingHandler(t)
+	app, _ := newTestApp(t, handler)
+	events := recordEvents(app)
+	app.config.App.FailFast = true
+	app.config.App.SelfTestAPI = true
+	app.config.App.SelfTestDatabase = false
+	app.config.App.StartupTimeout = 50 * time.Millisecond
+
+	if !app.runSelfTest() || app.exitCode != 0 {
+		t.Fatalf("runSelfTest aborted on an abandoned check, exit code %d", app.exitCode)
+	}
+	<-started
+
+	if !loggedMessage(app, "Self-test check abandoned after the startup timeout") {
+		t.Error("abandoned check was not logged")
+	}
+	var timeout *StartupTimeoutEvent
+	for _, event := range *events {
+		if event.name == "startup:timeout" {
+			e := event.data[0].(StartupTimeoutEvent)
+			timeout = &e
+		}
+	}
+	if timeout == nil || len(timeout.Abandoned) != 1 || timeout.Abandoned[0] != "api" {
+		t.Errorf("startup:timeout = %+v, want the api check abandoned", timeout)
+	}
+}
+
+func TestSelfTestStartupTimeoutKeepsCriticalFailures(t *testing.T) {
+	handler, _ := blockingHandler(t)
+	app, _ := newTestApp(t, handler)
+	app.config.App.FailFast = true
+	app.config.App.SelfTestAPI = true
+	app.config.App.SelfTestDatabase = false
+	app.config.App.StartupTimeout = 50 * time.Millisecond
+	app.config.Window.Width = 100
+
+	if app.runSelfTest() || app.exitCode != 1 {
+		t.Errorf("runSelfTest with an invalid config = true, exit code %d, want an abort", app.exitCode)
+	}
+}
+
+func TestRunUntil(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	wantErr := errors.New("unreachable")
+	if err := runUntil(ctx, func() error { return wantErr }); err != wantErr {
+		t.Errorf("runUntil = %v, want the check error", err)
+	}
+
+	cancel()
+	if err := runUntil(ctx, func() error { t.Error("check ran after the deadline"); return nil }); err != errCheckAbandoned {
+		t.Errorf("runUntil after the deadline = %v, want errCheckAbandoned", err)
+	}
+}
+
+func TestSelfTestHealthPath(t *testing.T) {
+	app, _ := newTestApp(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/healthz" {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	app.config.API.HealthPath = "/healthz"
+
+	app.config.API.HealthExpectedStatus = []int{http.StatusOK, http.StatusNoContent}
+	if err := app.checkAPIReachable(); err != nil {
+		t.Errorf("health check with 204 expected: %v", err)
+	}
+
+	app.config.API.HealthExpectedStatus = []int{http.StatusOK}
+	err := app.checkAPIReachable()
+	if err == nil || !strings.Contains(err.Error(), "204") {
+		t.Errorf("health check with only 200 expected = %v, want a failure naming 204", err)
+	}
+
+	app.config.API.HealthPath = "/status"
+	if err := app.checkAPIReachable(); err == nil {
+		t.Error("health check passed on a 404")
+	}
+}
+
+// baseURLCheck returns the base_url check of result, or nil
+func baseURLCheck(result *SelfTestResult) *SelfTestCheck {
+	for i := range result.Checks {
+		if result.Checks[i].Name == "base_url" {
+			return &result.Checks[i]
+		}
+	}
+	return nil
+}
+
+func TestSelfTestVerifyBaseURLReachable(t *testing.T) {
+	app, _ := newTestApp(t, http.NotFoundHandler())
+	app.config.API.VerifyBaseURLOnStart = true
+	app.config.App.SelfTestAPI = false
+	app.config.App.SelfTestDatabase = false
+
+	result := app.SelfTest()
+	check := baseURLCheck(result)
+	if !result.Passed || check == nil || !check.Passed {
+		t.Errorf("SelfTest = %+v, want a passing base_url check", result)
+	}
+	if loggedMessage(app, "Self-test check failed") {
+		t.Error("reachable base URL logged a warning")
+	}
+}
+
+func TestSelfTestVerifyBaseURLUnreachable(t *testing.T) {
+	for name, baseURL := range map[string]func(srv *httptest.Server) string{
+		"closed port":     func(srv *httptest.Server) string { srv.Close(); return srv.URL },
+		"unresolved host": func(*httptest.Server) string { return "https://api.example.invalid" },
+	} {
+		t.Run(name, func(t *testing.T) {
+			app, srv := newTestApp(t, http.NotFoundHandler())
+			app.config.API.VerifyBaseURLOnStart = true
+			app.config.App.SelfTestAPI = false
+			app.config.App.SelfTestDatabase = false
+			app.config.API.BaseURL = baseURL(srv)
+
+			app.config.App.FailFast = false
+			result := app.SelfTest()
+			check := baseURLCheck(result)
+			if !result.Passed || check == nil || check.Passed || check.Critical || check.Message == "" {
+				t.Errorf("SelfTest = %+v, want a failed non-critical base_url check", result)
+			}
+			if !loggedMessage(app, "Self-test check failed") {
+				t.Error("unreachable base URL was not logged as a warning")
+			}
+			if !app.runSelfTest() || app.exitCode != 0 {
+				t.Errorf("runSelfTest aborted without fail-fast, exit code %d", app.exitCode)
+			}
+
+			app.config.App.FailFast = true
+			if app.runSelfTest() || app.exitCode != 1 {
+				t.Errorf("runSelfTest with fail-fast = true, exit code %d, want an abort", app.exitCode)
+			}
+		})
+	}
+}
+
+// checkResults captures diagnostics:result events on a channel and returns
+// the names of the other events emitted
+func checkResults(app *App) (<-chan SelfTestCheck, func() []string) {
+	results := make(chan SelfTestCheck, 10)
+	var mu sync.Mutex
+	var others []string
+	app.ctx = context.Background()
+	app.emit = func(ctx context.Context, name string, data ...any) {
+		if name == "diagnostics:result" {
+			results <- data[0].(SelfTestCheck)
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		others = append(others, name)
+	}
+	return results, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return slices.Clone(others)
+	}
+}
+
+func TestRunSelfTestBackgroundChecks(t *testing.T) {
+	handler, started := blockingHandler(t)
+	app, _ := newTestApp(t, handler)
+	results, events := checkResults(app)
+	app.config.App.FailFast = false
+	app.config.App.SelfTestAPI = true
+	app.config.App.SelfTestDatabase = false
+	app.config.App.StartupTimeout = 0
+
+	done := make(chan bool, 1)
+	go func() { done <- app.runSelfTest() }()
+	select {
+	case ok := <-done:
+		if !ok {
+			t.Error("runSelfTest aborted startup")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("runSelfTest waited for the API check")
+	}
+	if got := events(); !slices.Equal(got, []string{"selftest:check", "selftest:check", "selftest:complete"}) {
+		t.Errorf("events = %v, want the config and log checks only", got)
+	}
+
+	<-started
+	app.Close()
+	select {
+	case check := <-results:
+		t.Errorf("abandoned check reported %+v", check)
+	default:
+	}
+}
+
+func TestRunSelfTestBackgroundResult(t *testing.T) {
+	app, srv := newTestApp(t, http.NotFoundHandler())
+	results, _ := checkResults(app)
+	app.config.App.FailFast = false
+	app.config.App.SelfTestAPI = true
+	app.config.App.SelfTestDatabase = false
+	srv.Close()
+
+	if !app.runSelfTest() {
+		t.Fatal("runSelfTest aborted startup")
+	}
+	select {
+	case check := <-results:
+		if check.Name != "api" || check.Passed || check.Message == "" {
+			t.Errorf("diagnostics:result = %+v, want a failed api check", check)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no diagnostics:result for the api check")
+	}
+}
+
+func TestRunSelfTestFailFastBlocks(t *testing.T) {
+	app, srv := newTestApp(t, http.NotFoundHandler())
+	results, _ := checkResults(app)
+	app.config.App.FailFast = true
+	app.config.App.SelfTestAPI = true
+	app.config.App.SelfTestDatabase = false
+	srv.Close()
+
+	if app.runSelfTest() {
+		t.Error("runSelfTest did not abort on a critical API failure")
+	}
+	select {
+	case check := <-results:
+		t.Errorf("critical check ran in the background: %+v", check)
+	default:
+	}
+}
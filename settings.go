@@ -0,0 +1,142 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"wails-template/internal/config"
+)
+
+var (
+	// ErrConfigReadOnly is returned when a change to the config file is
+	// requested in production or with app.readonly set
+	ErrConfigReadOnly = errors.New("configuration file is read-only")
+	// ErrConfigOverridden is returned when a value written to the config
+	// file would be shadowed by an environment variable or the secrets file
+	ErrConfigOverridden = errors.New("configuration value is overridden")
+)
+
+// csrfSecretLength is the length of generated CSRF secrets
+const csrfSecretLength = 64
+
+// configFileForWrite returns the config file the app may write, or an
+// error when changes must not be written
+func (a *App) configFileForWrite() (string, error) {
+	cfg := a.currentConfig()
+	if cfg.App.Environment.IsProduction() || cfg.App.ReadOnly {
+		return "", ErrConfigReadOnly
+	}
+	path, ok := config.SourceFile()
+	if !ok {
+		return "", fmt.Errorf("%w: configuration is loaded from %s", ErrConfigReadOnly, config.ConfigSource())
+	}
+	return path, nil
+}
+
+// GenerateAndPersistCSRFSecret generates a new CSRF secret, writes it to
+// the config file and reloads the configuration. It returns the new secret.
+func (a *App) GenerateAndPersistCSRFSecret() (_ string, err error) {
+	defer a.recoverPanic("GenerateAndPersistCSRFSecret", &err)
+
+	path, err := a.configFileForWrite()
+	if err != nil {
+		return "", err
+	}
+
+	// Writing the file is pointless when another source wins
+	source, err := config.OverrideSource("security", "csrf_secret")
+	if err != nil {
+		return "", err
+	}
+	if source != "" {
+		return "", fmt.Errorf("%w: security.csrf_secret is set by the %s", ErrConfigOverridden, source)
+	}
+
+	secret, err := config.GenerateSecureSecret(csrfSecretLength)
+	if err != nil {
+		return "", err
+	}
+
+	values := config.Values{"security": {"csrf_secret": secret}}
+	if err := config.WriteValues(path, values); err != nil {
+		return "", fmt.Errorf("failed to persist CSRF secret: %w", err)
+	}
+	if err := a.reloadConfig(auditRotateCSRFSecret, triggerUser); err != nil {
+		return "", err
+	}
+
+	if a.currentConfig().Security.CSRFSecret != secret {
+		return "", errors.New("CSRF secret was written but the reloaded configuration has a different value")
+	}
+
+	a.logger.Info("Generated new CSRF secret", "secret", "***MASKED***")
+	return secret, nil
+}
+
+// ResetConfigToDefaults reverts the config file to the built-in defaults and
+// reloads the configuration. The API base URL and the client credentials
+// and other secrets are kept.
+func (a *App) ResetConfigToDefaults() (err error) {
+	defer a.recoverPanic("ResetConfigToDefaults", &err)
+
+	return a.resetConfig(false)
+}
+
+// ResetConfigAndSecretsToDefaults resets the configuration like
+// ResetConfigToDefaults, but also clears the client credentials and the
+// database password and generates a new CSRF secret
+func (a *App) ResetConfigAndSecretsToDefaults() (err error) {
+	defer a.recoverPanic("ResetConfigAndSecretsToDefaults", &err)
+
+	return a.resetConfig(true)
+}
+
+// resetConfig writes the defaults to the config file, keeping the API base
+// URL, which has no usable default, and the secrets unless resetSecrets is
+// set. Client ID and secret are kept or reset together.
+func (a *App) resetConfig(resetSecrets bool) error {
+	cfg := a.currentConfig()
+	path, err := a.configFileForWrite()
+	if err != nil {
+		return err
+	}
+
+	defaults, values := config.Defaults()
+	defaults.API.BaseURL = cfg.API.BaseURL
+	delete(values["api"], "base_url")
+
+	if resetSecrets {
+		secret, err := config.GenerateSecureSecret(csrfSecretLength)
+		if err != nil {
+			return err
+		}
+		defaults.Security.CSRFSecret = secret
+		values["security"]["csrf_secret"] = secret
+	} else {
+		defaults.Auth.ClientID = cfg.Auth.ClientID
+		defaults.Auth.ClientSecret = cfg.Auth.ClientSecret
+		defaults.Auth.APIKey = cfg.Auth.APIKey
+		defaults.API.SigningSecret = cfg.API.SigningSecret
+		defaults.Database.Password = cfg.Database.Password
+		defaults.Security.CSRFSecret = cfg.Security.CSRFSecret
+		delete(values["auth"], "client_id")
+		for section, keys := range values {
+			for key := range keys {
+				if config.IsSecretKey(section, key) {
+					delete(keys, key)
+				}
+			}
+		}
+	}
+
+	if err := config.Validate(defaults); err != nil {
+		return fmt.Errorf("default configuration is invalid: %w", err)
+	}
+	if err := config.WriteValues(path, values); err != nil {
+		return fmt.Errorf("failed to write default configuration: %w", err)
+	}
+	action := auditReset
+	if resetSecrets {
+		action = auditResetSecrets
+	}
+	return a.reloadConfig(action, triggerUser)
+}
@@ -0,0 +1,238 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"wails-template/internal/config"
+)
+
+// useConfigCopy runs the rest of the test in a temporary directory holding
+// a copy of config.ini, so the test may write to it. It returns the path of
+// the copy.
+func useConfigCopy(t *testing.T) string {
+	t.Helper()
+
+	data, err := os.ReadFile(config.ConfigFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, config.ConfigFile), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		os.Chdir(wd)
+		config.ReloadConfig()
+	})
+	return filepath.Join(dir, config.ConfigFile)
+}
+
+func TestGenerateAndPersistCSRFSecret(t *testing.T) {
+	path := useConfigCopy(t)
+	app, _ := newTestApp(t, http.NotFoundHandler())
+
+	secret, err := app.GenerateAndPersistCSRFSecret()
+	if err != nil {
+		t.Fatalf("GenerateAndPersistCSRFSecret: %v", err)
+	}
+	if len(secret) != csrfSecretLength {
+		t.Errorf("secret length = %d, want %d", len(secret), csrfSecretLength)
+	}
+	if app.config.Security.CSRFSecret != secret {
+		t.Error("live config does not hold the new secret")
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "csrf_secret = "+secret+"\n") {
+		t.Error("secret was not written to the config file")
+	}
+	if !strings.Contains(string(data), "# Prevent the app from writing changes back to this file") {
+		t.Error("comments were not preserved")
+	}
+}
+
+func TestGenerateAndPersistCSRFSecretReadOnly(t *testing.T) {
+	app, _ := newTestApp(t, http.NotFoundHandler())
+	app.config.App.ReadOnly = true
+
+	if _, err := app.GenerateAndPersistCSRFSecret(); !errors.Is(err, ErrConfigReadOnly) {
+		t.Errorf("error = %v, want ErrConfigReadOnly", err)
+	}
+
+	app.config.App.ReadOnly = false
+	app.config.App.Environment = config.Production
+	if _, err := app.GenerateAndPersistCSRFSecret(); !errors.Is(err, ErrConfigReadOnly) {
+		t.Errorf("production error = %v, want ErrConfigReadOnly", err)
+	}
+
+	// There is no file to write when the config comes from stdin
+	app.config.App.Environment = config.Development
+	t.Setenv("CONFIG_SOURCE", "-")
+	if _, err := app.GenerateAndPersistCSRFSecret(); !errors.Is(err, ErrConfigReadOnly) {
+		t.Errorf("stdin source error = %v, want ErrConfigReadOnly", err)
+	}
+}
+
+func TestGenerateAndPersistCSRFSecretOverridden(t *testing.T) {
+	tests := []struct {
+		name   string
+		setup  func(t *testing.T, app *App)
+		source string
+	}{
+		{
+			name: "environment variable",
+			setup: func(t *testing.T, app *App) {
+				t.Setenv("CSMART_SECURITY_CSRF_SECRET", "from-env")
+			},
+			source: "environment variable CSMART_SECURITY_CSRF_SECRET",
+		},
+		{
+			name: "custom prefix",
+			setup: func(t *testing.T, app *App) {
+				t.Setenv("CONFIG_ENV_PREFIX", "MYAPP_")
+				t.Setenv("MYAPP_SECURITY_CSRF_SECRET", "from-env")
+			},
+			source: "environment variable MYAPP_SECURITY_CSRF_SECRET",
+		},
+		{
+			name: "secrets file",
+			setup: func(t *testing.T, app *App) {
+				os.WriteFile("secrets.ini", []byte("[security]\ncsrf_secret = from-secrets\n"), 0600)
+				if err := config.WriteValues(config.ConfigFile, config.Values{"secrets": {"file": "secrets.ini"}}); err != nil {
+					t.Fatal(err)
+				}
+				if err := app.ReloadConfig(); err != nil {
+					t.Fatal(err)
+				}
+			},
+			source: "secrets file secrets.ini",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := useConfigCopy(t)
+			app, _ := newTestApp(t, http.NotFoundHandler())
+			tt.setup(t, app)
+			before, _ := os.ReadFile(path)
+
+			_, err := app.GenerateAndPersistCSRFSecret()
+			if !errors.Is(err, ErrConfigOverridden) || !strings.Contains(err.Error(), tt.source) {
+				t.Fatalf("error = %v, want ErrConfigOverridden naming the %s", err, tt.source)
+			}
+			if after, _ := os.ReadFile(path); string(after) != string(before) {
+				t.Error("config file was written although the value is overridden")
+			}
+		})
+	}
+}
+
+// customizeConfig writes non-default values and credentials to the config
+// file at path and reloads it
+func customizeConfig(t *testing.T, path string) {
+	t.Helper()
+
+	values := config.Values{
+		"window":   {"width": "1500"},
+		"api":      {"timeout": "45"},
+		"auth":     {"client_id": "client", "client_secret": strings.Repeat("s", 32)},
+		"security": {"csrf_secret": "old-secret"},
+	}
+	if err := config.WriteValues(path, values); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := config.ReloadConfig(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestResetConfigToDefaults(t *testing.T) {
+	path := useConfigCopy(t)
+	customizeConfig(t, path)
+	app, _ := newTestApp(t, http.NotFoundHandler())
+
+	if err := app.ResetConfigToDefaults(); err != nil {
+		t.Fatalf("ResetConfigToDefaults: %v", err)
+	}
+
+	// The documented defaults, see docs/CONFIGURATION.md
+	cfg := app.config
+	if cfg.Window.Width != 1200 || cfg.Window.Height != 800 {
+		t.Errorf("window = %dx%d, want 1200x800", cfg.Window.Width, cfg.Window.Height)
+	}
+	if cfg.API.Timeout != 30*time.Second || cfg.API.RetryCount != 3 || cfg.API.RetryDelay != time.Second {
+		t.Errorf("API = %v, %d retries, %v delay, want 30s, 3 retries, 1s delay", cfg.API.Timeout, cfg.API.RetryCount, cfg.API.RetryDelay)
+	}
+	if cfg.App.Name != "CSmart Wails App" || cfg.Log.Level != config.LogLevelDebug {
+		t.Errorf("name = %q, log level = %q, want the defaults", cfg.App.Name, cfg.Log.Level)
+	}
+
+	// The base URL and the credentials are kept
+	if cfg.API.BaseURL != "https://your-api-domain.com/api/v3.1" {
+		t.Errorf("BaseURL = %q, want it kept", cfg.API.BaseURL)
+	}
+	if cfg.Auth.ClientID != "client" || cfg.Auth.ClientSecret != strings.Repeat("s", 32) || cfg.Security.CSRFSecret != "old-secret" {
+		t.Error("credentials were not kept")
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "# Prevent the app from writing changes back to this file") {
+		t.Error("comments were not preserved")
+	}
+}
+
+func TestResetConfigAndSecretsToDefaults(t *testing.T) {
+	path := useConfigCopy(t)
+	customizeConfig(t, path)
+	app, _ := newTestApp(t, http.NotFoundHandler())
+
+	if err := app.ResetConfigAndSecretsToDefaults(); err != nil {
+		t.Fatalf("ResetConfigAndSecretsToDefaults: %v", err)
+	}
+
+	cfg := app.config
+	if cfg.Auth.ClientID != "" || cfg.Auth.ClientSecret != "" {
+		t.Errorf("client credentials = %q, %q, want them cleared", cfg.Auth.ClientID, cfg.Auth.ClientSecret)
+	}
+	if cfg.Security.CSRFSecret == "old-secret" || len(cfg.Security.CSRFSecret) != csrfSecretLength {
+		t.Errorf("CSRF secret = %q, want a new %d character secret", cfg.Security.CSRFSecret, csrfSecretLength)
+	}
+	if cfg.Window.Width != 1200 {
+		t.Errorf("window width = %d, want 1200", cfg.Window.Width)
+	}
+}
+
+func TestResetConfigToDefaultsReadOnly(t *testing.T) {
+	path := useConfigCopy(t)
+	before, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	app, _ := newTestApp(t, http.NotFoundHandler())
+	app.config.App.ReadOnly = true
+
+	if err := app.ResetConfigToDefaults(); !errors.Is(err, ErrConfigReadOnly) {
+		t.Errorf("error = %v, want ErrConfigReadOnly", err)
+	}
+	if after, _ := os.ReadFile(path); string(after) != string(before) {
+		t.Error("config file was changed")
+	}
+}
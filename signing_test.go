@@ -0,0 +1,97 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"wails-template/internal/clock"
+)
+
+func TestSignRequest(t *testing.T) {
+	var gotSignature, gotTimestamp, want string
+	app, _ := newTestApp(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		gotSignature = r.Header.Get("X-Hub-Signature")
+		gotTimestamp = r.Header.Get(signatureTimestampHeader)
+
+		mac := hmac.New(sha256.New, []byte("signing-secret"))
+		mac.Write([]byte(r.Method + "\n" + r.URL.RequestURI() + "\n" + gotTimestamp + "\n"))
+		mac.Write(body)
+		want = hex.EncodeToString(mac.Sum(nil))
+		w.Write([]byte(`{}`))
+	}))
+	app.clock = clock.NewFake(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	app.config.API.SigningEnabled = true
+	app.config.API.SigningSecret = "signing-secret"
+	app.config.API.SigningHeader = "X-Hub-Signature"
+
+	_, err := app.Request(APIRequest{
+		Method: http.MethodPost,
+		Path:   "/items",
+		Query:  map[string]string{"q": "a b"},
+		Body:   map[string]string{"name": "item"},
+	})
+	if err != nil {
+		t.Fatalf("Request: %v", err)
+	}
+	if gotTimestamp != "1704067200" {
+		t.Errorf("timestamp = %q, want 1704067200", gotTimestamp)
+	}
+	if gotSignature == "" || gotSignature != want {
+		t.Errorf("signature = %q, want %q", gotSignature, want)
+	}
+}
+
+func TestSignRequestSkipped(t *testing.T) {
+	tests := []struct {
+		name    string
+		enabled bool
+		secret  string
+	}{
+		{"disabled", false, "signing-secret"},
+		{"no secret", true, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var signed bool
+			app, _ := newTestApp(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				signed = r.Header.Get("X-Signature") != "" || r.Header.Get(signatureTimestampHeader) != ""
+				w.Write([]byte(`{}`))
+			}))
+			app.config.API.SigningEnabled = tt.enabled
+			app.config.API.SigningSecret = tt.secret
+
+			if _, err := app.Request(APIRequest{Method: http.MethodGet, Path: "/items"}); err != nil {
+				t.Fatalf("Request: %v", err)
+			}
+			if signed {
+				t.Error("request was signed")
+			}
+		})
+	}
+}
+
+func TestVerifySignature(t *testing.T) {
+	body := []byte(`{"name":"item"}`)
+	signature := requestSignature("secret", http.MethodPost, "/items?q=1", "1704067200", body)
+
+	if !verifySignature("secret", http.MethodPost, "/items?q=1", "1704067200", body, signature) {
+		t.Error("valid signature rejected")
+	}
+	for name, ok := range map[string]bool{
+		"body":      verifySignature("secret", http.MethodPost, "/items?q=1", "1704067200", []byte(`{}`), signature),
+		"uri":       verifySignature("secret", http.MethodPost, "/items?q=2", "1704067200", body, signature),
+		"timestamp": verifySignature("secret", http.MethodPost, "/items?q=1", "1704067201", body, signature),
+		"secret":    verifySignature("other", http.MethodPost, "/items?q=1", "1704067200", body, signature),
+		"not hex":   verifySignature("secret", http.MethodPost, "/items?q=1", "1704067200", body, "zz"),
+	} {
+		if ok {
+			t.Errorf("signature with a different %s accepted", name)
+		}
+	}
+}
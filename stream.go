@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// streamJSON sends a request like doJSON but decodes the response body as
+// it is read, without buffering it or sharing it with identical requests.
+// The api.max_response_bytes limit still applies.
+func (a *App) streamJSON(ctx context.Context, method, url string, payload, out any, opts ...requestOption) (err error) {
+	defer func() {
+		err = withRequestIDError(ctx, err)
+	}()
+
+	var body []byte
+	if payload != nil {
+		if body, err = json.Marshal(payload); err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+	}
+
+	options := requestOptions{}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	resp, err := a.send(ctx, method, url, body, &options)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// Error responses are small, read them like doJSON does
+	if resp.StatusCode >= http.StatusBadRequest {
+		apiErr := a.newAPIError(resp.StatusCode)
+		if data, err := a.readResponseBody(resp); err == nil {
+			_ = decodeJSON(data, apiErr)
+		}
+		apiErr.StatusCode = resp.StatusCode
+		return apiErr
+	}
+
+	limit := a.currentConfig().API.MaxResponseBytes
+	if limit > 0 && resp.ContentLength > limit {
+		return ErrResponseTooLarge
+	}
+	var r io.Reader = resp.Body
+	if limit > 0 {
+		r = &maxBytesReader{r: resp.Body, remaining: limit}
+	}
+	if err := decodeJSONFrom(r, out); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	return nil
+}
+
+// maxBytesReader reads from r, failing with ErrResponseTooLarge once more
+// than remaining bytes have been read
+type maxBytesReader struct {
+	r         io.Reader
+	remaining int64
+}
+
+func (m *maxBytesReader) Read(p []byte) (int, error) {
+	if m.remaining < 0 {
+		return 0, ErrResponseTooLarge
+	}
+	// Read one byte past the limit to detect oversized bodies
+	if int64(len(p)) > m.remaining+1 {
+		p = p[:m.remaining+1]
+	}
+	n, err := m.r.Read(p)
+	m.remaining -= int64(n)
+	if m.remaining < 0 {
+		return n, ErrResponseTooLarge
+	}
+	return n, err
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// listHandler serves a JSON array of n items, with a Content-Length when
+// the request asks for it with ?length=1
+func listHandler(n int) http.Handler {
+	var body strings.Builder
+	body.WriteString("[")
+	for i := range n {
+		if i > 0 {
+			body.WriteString(",")
+		}
+		fmt.Fprintf(&body, `{"id":%d,"name":"item %d","tags":["a","b"]}`, i, i)
+	}
+	body.WriteString("]")
+	data := body.String()
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("length") == "1" {
+			w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+		}
+		io.WriteString(w, data)
+	})
+}
+
+func TestStreamRequestMatchesBuffered(t *testing.T) {
+	app, _ := newTestApp(t, listHandler(1000))
+
+	buffered, err := app.Request(APIRequest{Method: http.MethodGet, Path: "/items"})
+	if err != nil {
+		t.Fatalf("buffered Request: %v", err)
+	}
+	streamed, err := app.Request(APIRequest{Method: http.MethodGet, Path: "/items", Stream: true})
+	if err != nil {
+		t.Fatalf("streamed Request: %v", err)
+	}
+	if !reflect.DeepEqual(buffered, streamed) {
+		t.Error("streamed response differs from the buffered one")
+	}
+}
+
+func TestStreamRequestSizeLimit(t *testing.T) {
+	for _, query := range map[string]string{"chunked": "", "content-length": "?length=1"} {
+		app, _ := newTestApp(t, listHandler(1000))
+		app.config.API.MaxResponseBytes = 1 << 10
+
+		_, err := app.Request(APIRequest{Method: http.MethodGet, Path: "/items" + query, Stream: true})
+		if !errors.Is(err, ErrResponseTooLarge) {
+			t.Errorf("Request%s error = %v, want ErrResponseTooLarge", query, err)
+		}
+	}
+
+	// A response of exactly the limit passes
+	app, srv := newTestApp(t, listHandler(1))
+	resp, err := srv.Client().Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	app.config.API.MaxResponseBytes = resp.ContentLength
+	if _, err := app.Request(APIRequest{Method: http.MethodGet, Path: "/items", Stream: true}); err != nil {
+		t.Errorf("Request at the limit: %v", err)
+	}
+}
+
+func TestStreamRequestAPIError(t *testing.T) {
+	app, _ := newTestApp(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		io.WriteString(w, `{"code":"not_found","message":"no such item"}`)
+	}))
+
+	_, err := app.Request(APIRequest{Method: http.MethodGet, Path: "/items/1", Stream: true})
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound || apiErr.Message != "no such item" {
+		t.Errorf("error = %v, want the API error", err)
+	}
+}
+
+func TestStreamRequestSkipsCache(t *testing.T) {
+	var hits, conditional atomic.Int32
+	app, _ := etagTestApp(t, etagHandler(&hits, &conditional))
+
+	for range 2 {
+		if _, err := app.Request(APIRequest{Method: http.MethodGet, Path: "/identity/me", Stream: true}); err != nil {
+			t.Fatalf("Request: %v", err)
+		}
+	}
+	if conditional.Load() != 0 || app.cache.Len() != 0 {
+		t.Errorf("streamed response was cached: %d conditional requests, %d entries", conditional.Load(), app.cache.Len())
+	}
+}
+
+// BenchmarkDecodeLargeResponse compares the memory used to decode a large
+// list response buffered and streamed. Run with -benchmem.
+func BenchmarkDecodeLargeResponse(b *testing.B) {
+	for _, stream := range []bool{false, true} {
+		name := "buffered"
+		if stream {
+			name = "streaming"
+		}
+		b.Run(name, func(b *testing.B) {
+			app, _ := newTestApp(b, listHandler(20000))
+			b.ReportAllocs()
+			for range b.N {
+				if _, err := app.Request(APIRequest{Method: http.MethodGet, Path: "/items", Stream: stream}); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// OS themes reported by GetSystemTheme
+const (
+	ThemeDark    = "dark"
+	ThemeLight   = "light"
+	ThemeUnknown = "unknown"
+)
+
+// ThemeChangedEvent is the payload of the theme:changed event, emitted when
+// the OS switches between dark and light
+type ThemeChangedEvent struct {
+	Theme string `json:"theme"`
+}
+
+// commandOutput runs a command and returns its standard output. Tests
+// replace it to fake the platform tools.
+var commandOutput = func(name string, args ...string) (string, error) {
+	out, err := exec.Command(name, args...).Output()
+	return string(out), err
+}
+
+// GetSystemTheme returns the theme of the OS: dark, light or unknown when
+// it can't be detected. The Wails v2 runtime doesn't expose the theme, so
+// it is read with the platform tools.
+func (a *App) GetSystemTheme() string {
+	return systemTheme(runtime.GOOS)
+}
+
+// systemTheme detects the theme of goos
+func systemTheme(goos string) string {
+	name, args := themeCommand(goos)
+	out, err := commandOutput(name, args...)
+	return parseTheme(goos, out, err)
+}
+
+// themeCommand returns the command that reads the theme setting of goos.
+// On Linux only GNOME-compatible desktops are supported.
+func themeCommand(goos string) (string, []string) {
+	switch goos {
+	case "windows":
+		return "reg", []string{"query", `HKCU\Software\Microsoft\Windows\CurrentVersion\Themes\Personalize`, "/v", "AppsUseLightTheme"}
+	case "darwin":
+		return "defaults", []string{"read", "-g", "AppleInterfaceStyle"}
+	default:
+		return "gsettings", []string{"get", "org.gnome.desktop.interface", "color-scheme"}
+	}
+}
+
+// parseTheme interprets the output of the themeCommand of goos
+func parseTheme(goos, out string, err error) string {
+	if goos == "darwin" {
+		// The key only exists in dark mode; defaults exits non-zero without it
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return ThemeLight
+		}
+		if err == nil && strings.TrimSpace(out) == "Dark" {
+			return ThemeDark
+		}
+		return ThemeUnknown
+	}
+	if err != nil {
+		return ThemeUnknown
+	}
+
+	switch goos {
+	case "windows":
+		fields := strings.Fields(out)
+		switch {
+		case len(fields) == 0:
+			return ThemeUnknown
+		case fields[len(fields)-1] == "0x0":
+			return ThemeDark
+		case fields[len(fields)-1] == "0x1":
+			return ThemeLight
+		}
+	default:
+		switch strings.Trim(strings.TrimSpace(out), "'") {
+		case "prefer-dark":
+			return ThemeDark
+		case "default", "prefer-light":
+			return ThemeLight
+		}
+	}
+	return ThemeUnknown
+}
+
+// watchTheme starts polling the OS theme every app.theme_poll_interval. It
+// does nothing when polling is disabled.
+func (a *App) watchTheme() {
+	interval := a.currentConfig().App.ThemePollInterval
+	if interval <= 0 {
+		return
+	}
+	a.goBackground(func(ctx context.Context) {
+		a.runThemeWatcher(ctx, interval)
+	})
+}
+
+// runThemeWatcher emits theme:changed whenever the detected theme differs
+// from the previous check, until ctx is done
+func (a *App) runThemeWatcher(ctx context.Context, interval time.Duration) {
+	last := a.GetSystemTheme()
+	for a.sleep(ctx, interval) {
+		current := a.GetSystemTheme()
+		if current == last {
+			continue
+		}
+		last = current
+		a.logger.Debug("System theme changed", "theme", current)
+		a.emitEvent("theme:changed", ThemeChangedEvent{Theme: current})
+	}
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os/exec"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"wails-template/internal/clock"
+)
+
+// themeOutput returns what the themeCommand of each platform prints for
+// theme, and the error it fails with
+func themeOutput(name, theme string) (string, error) {
+	switch name {
+	case "reg":
+		value := "0x1"
+		if theme == ThemeDark {
+			value = "0x0"
+		}
+		return "\r\nHKEY_CURRENT_USER\\...\\Personalize\r\n    AppsUseLightTheme    REG_DWORD    " + value + "\r\n", nil
+	case "defaults":
+		if theme == ThemeDark {
+			return "Dark\n", nil
+		}
+		return "", &exec.ExitError{}
+	default:
+		if theme == ThemeDark {
+			return "'prefer-dark'\n", nil
+		}
+		return "'default'\n", nil
+	}
+}
+
+// stubTheme replaces commandOutput for the test with the platform tools
+// reporting the theme stored in the returned value
+func stubTheme(t *testing.T, theme string) *atomic.Value {
+	t.Helper()
+
+	var current atomic.Value
+	current.Store(theme)
+	old := commandOutput
+	commandOutput = func(name string, args ...string) (string, error) {
+		return themeOutput(name, current.Load().(string))
+	}
+	t.Cleanup(func() { commandOutput = old })
+	return &current
+}
+
+func TestSystemTheme(t *testing.T) {
+	for _, goos := range []string{"windows", "darwin", "linux"} {
+		for _, theme := range []string{ThemeDark, ThemeLight} {
+			t.Run(goos+"/"+theme, func(t *testing.T) {
+				stubTheme(t, theme)
+				if got := systemTheme(goos); got != theme {
+					t.Errorf("systemTheme = %q, want %q", got, theme)
+				}
+			})
+		}
+	}
+}
+
+func TestSystemThemeUnknown(t *testing.T) {
+	notFound := &exec.Error{Name: "tool", Err: exec.ErrNotFound}
+	tests := []struct {
+		name string
+		goos string
+		out  string
+		err  error
+	}{
+		{"windows without the value", "windows", "", &exec.ExitError{}},
+		{"windows unexpected output", "windows", "AppsUseLightTheme REG_DWORD 0x2", nil},
+		{"darwin without defaults", "darwin", "", notFound},
+		{"darwin unexpected output", "darwin", "Light\n", nil},
+		{"linux without gsettings", "linux", "", notFound},
+		{"linux unknown scheme", "linux", "'custom'\n", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			old := commandOutput
+			commandOutput = func(string, ...string) (string, error) { return tt.out, tt.err }
+			t.Cleanup(func() { commandOutput = old })
+
+			if got := systemTheme(tt.goos); got != ThemeUnknown {
+				t.Errorf("systemTheme = %q, want unknown", got)
+			}
+		})
+	}
+}
+
+func TestThemeCommandIsRead(t *testing.T) {
+	var commands []string
+	old := commandOutput
+	commandOutput = func(name string, args ...string) (string, error) {
+		commands = append(commands, name)
+		return "", errors.New("not available")
+	}
+	t.Cleanup(func() { commandOutput = old })
+
+	for _, goos := range []string{"windows", "darwin", "linux"} {
+		systemTheme(goos)
+	}
+	if want := []string{"reg", "defaults", "gsettings"}; !slices.Equal(commands, want) {
+		t.Errorf("commands = %q, want %q", commands, want)
+	}
+}
+
+func TestThemeWatcherEmitsChanges(t *testing.T) {
+	current := stubTheme(t, ThemeLight)
+	app, _ := newTestApp(t, http.NotFoundHandler())
+	events := recordEvents(app)
+	fake := clock.NewFake(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	app.clock = fake
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		app.runThemeWatcher(ctx, time.Second)
+		close(stopped)
+	}()
+	defer func() {
+		cancel()
+		<-stopped
+	}()
+
+	// poll lets the watcher check the theme once
+	poll := func() {
+		t.Helper()
+		waitFor(t, "watcher to sleep", func() bool { return fake.Waiters() == 1 })
+		fake.Advance(time.Second)
+		waitFor(t, "watcher to check the theme", func() bool { return fake.Waiters() == 1 })
+	}
+
+	poll()
+	if len(*events) != 0 {
+		t.Errorf("events without a change = %+v, want none", *events)
+	}
+
+	current.Store(ThemeDark)
+	poll()
+	poll()
+	if len(*events) != 1 || (*events)[0].name != "theme:changed" {
+		t.Fatalf("events = %+v, want one theme:changed", *events)
+	}
+	if got := (*events)[0].data[0].(ThemeChangedEvent); got.Theme != ThemeDark {
+		t.Errorf("theme:changed payload = %+v, want dark", got)
+	}
+}
@@ -0,0 +1,245 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+	"wails-template/internal/clock"
+	"wails-template/internal/config"
+
+	"golang.org/x/net/http2"
+)
+
+// ErrTooManyRedirects is returned when a request is redirected more than
+// api.max_redirects times
+var ErrTooManyRedirects = errors.New("too many redirects")
+
+// newHTTPClient creates the HTTP client shared by all API requests. Its TLS
+// connections use the certificates in certs.
+func newHTTPClient(cfg *config.Config, certs *certificateStore) (*http.Client, error) {
+	tlsConfig, err := newTLSConfig(cfg.TLS, certs)
+	if err != nil {
+		return nil, err
+	}
+
+	dial := newDialer(cfg.API).DialContext
+	if cfg.API.DNSCacheTTL > 0 {
+		dial = newDNSCache(cfg.API.DNSCacheTTL, clock.New(), net.DefaultResolver, dial).DialContext
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConnsPerHost = cfg.API.MaxIdleConn
+	transport.IdleConnTimeout = cfg.API.IdleConnTimeout
+	transport.TLSClientConfig = tlsConfig
+	transport.DialContext = dial
+
+	switch {
+	case cfg.API.ForceHTTP1:
+		// A non-nil, empty TLSNextProto disables HTTP/2
+		transport.ForceAttemptHTTP2 = false
+		transport.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+	case cfg.API.AllowHTTP2Cleartext:
+		transport.RegisterProtocol("http", newH2CTransport(dial))
+	}
+
+	// Timeouts are applied per call through the request context, see send
+	return &http.Client{
+		Transport:     transport,
+		CheckRedirect: redirectPolicy(cfg.API, credentialHeaders(cfg.API, cfg.Auth)),
+	}, nil
+}
+
+// newDialer returns the dialer of API connections, with the connect timeout
+// of http.DefaultTransport and the configured keep-alive interval
+func newDialer(api config.APIConfig) *net.Dialer {
+	keepAlive := api.KeepAlive
+	if keepAlive == 0 {
+		// A zero KeepAlive enables probes at the default interval
+		keepAlive = -1
+	}
+	return &net.Dialer{Timeout: 30 * time.Second, KeepAlive: keepAlive}
+}
+
+// credentialHeaders returns the canonical names of the headers that may
+// carry credentials: the standard ones, the configured auth and signing
+// headers and the default headers
+func credentialHeaders(api config.APIConfig, auth config.AuthConfig) map[string]bool {
+	headers := map[string]bool{"Authorization": true, "Proxy-Authorization": true, "Cookie": true, "Set-Cookie": true}
+	for _, name := range []string{auth.HeaderName, api.SigningHeader, "X-Signature"} {
+		if name != "" {
+			headers[http.CanonicalHeaderKey(name)] = true
+		}
+	}
+	for name := range api.DefaultHeaders {
+		headers[http.CanonicalHeaderKey(name)] = true
+	}
+	return headers
+}
+
+// redirectPolicy returns the CheckRedirect function applying the redirect
+// settings of api. The credential headers are dropped when a redirect
+// leaves the host and port of the original request; the standard policy
+// compares host names only and knows only the standard headers.
+func redirectPolicy(api config.APIConfig, credentials map[string]bool) func(*http.Request, []*http.Request) error {
+	return func(req *http.Request, via []*http.Request) error {
+		if !api.FollowRedirects {
+			return http.ErrUseLastResponse
+		}
+		if len(via) > api.MaxRedirects {
+			return fmt.Errorf("%w: stopped after %d", ErrTooManyRedirects, api.MaxRedirects)
+		}
+		if req.URL.Host != via[0].URL.Host {
+			for name := range credentials {
+				req.Header.Del(name)
+			}
+		}
+		return nil
+	}
+}
+
+// newH2CTransport returns a round tripper speaking HTTP/2 over plain TCP
+// with prior knowledge, for servers that support h2c
+func newH2CTransport(dial dialFunc) http.RoundTripper {
+	return &http2.Transport{
+		AllowHTTP: true,
+		DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+			return dial(ctx, network, addr)
+		},
+	}
+}
+
+// tlsVersions maps the values of [tls] min_version to TLS versions
+var tlsVersions = map[string]uint16{
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// newTLSConfig builds the TLS configuration for outbound connections. The
+// certificates are looked up in certs on every handshake.
+func newTLSConfig(cfg config.TLSConfig, certs *certificateStore) (*tls.Config, error) {
+	minVersion := cfg.MinVersion
+	if minVersion == "" {
+		minVersion = "1.2"
+	}
+	version, ok := tlsVersions[minVersion]
+	if !ok {
+		return nil, fmt.Errorf("unsupported TLS version %q", cfg.MinVersion)
+	}
+
+	return &tls.Config{
+		MinVersion: version,
+		// RootCAs can't change once connections are made, so the server
+		// certificate is verified against the current roots by
+		// VerifyConnection instead of the default verification
+		InsecureSkipVerify:   true,
+		VerifyConnection:     certs.verifyConnection,
+		GetClientCertificate: certs.clientCertificate,
+	}, nil
+}
+
+// certificateStore holds the certificates of outbound TLS connections. It
+// is shared by every client built for the app and read on each handshake,
+// so certificates reloaded into it apply to all new connections. The zero
+// value trusts the system roots and presents no client certificate.
+type certificateStore struct {
+	mu     sync.RWMutex
+	roots  *x509.CertPool   // nil = system roots
+	client *tls.Certificate // nil = none
+}
+
+// newCertificateStore returns a store holding the certificates of cfg
+func newCertificateStore(cfg config.TLSConfig) (*certificateStore, error) {
+	certs := &certificateStore{}
+	if err := certs.load(cfg); err != nil {
+		return nil, err
+	}
+	return certs, nil
+}
+
+// load reads the certificate files of cfg and replaces the stored
+// certificates. Nothing is replaced when a file can't be loaded.
+func (s *certificateStore) load(cfg config.TLSConfig) error {
+	roots, err := loadRootCAs(cfg.CACertPath)
+	if err != nil {
+		return err
+	}
+	var client *tls.Certificate
+	if cfg.ClientCertPath != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.ClientCertPath, cfg.ClientKeyPath)
+		if err != nil {
+			return fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		client = &cert
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.roots, s.client = roots, client
+	return nil
+}
+
+// clientCertificate implements tls.Config.GetClientCertificate. Without a
+// client certificate an empty one is returned, which sends none.
+func (s *certificateStore) clientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.client == nil {
+		return &tls.Certificate{}, nil
+	}
+	return s.client, nil
+}
+
+// verifyConnection verifies the server certificate chain and host name the
+// way crypto/tls does, against the stored roots
+func (s *certificateStore) verifyConnection(cs tls.ConnectionState) error {
+	if len(cs.PeerCertificates) == 0 {
+		return errors.New("server presented no certificate")
+	}
+	s.mu.RLock()
+	roots := s.roots
+	s.mu.RUnlock()
+
+	opts := x509.VerifyOptions{
+		Roots:         roots,
+		DNSName:       cs.ServerName,
+		Intermediates: x509.NewCertPool(),
+	}
+	for _, cert := range cs.PeerCertificates[1:] {
+		opts.Intermediates.AddCert(cert)
+	}
+	_, err := cs.PeerCertificates[0].Verify(opts)
+	return err
+}
+
+// loadRootCAs returns the system roots plus the CA at path, or nil for
+// just the system roots when path is empty
+func loadRootCAs(path string) (*x509.CertPool, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	caCert, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA certificate: %w", err)
+	}
+
+	// Append the extra CA to a copy of the system pool so the system roots
+	// stay trusted. Fall back to an empty pool where the system pool is
+	// unavailable.
+	pool, err := x509.SystemCertPool()
+	if err != nil || pool == nil {
+		fmt.Printf("System certificate pool unavailable, trusting only %s\n", path)
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(caCert) {
+		return nil, fmt.Errorf("no valid certificates found in %s", path)
+	}
+	return pool, nil
+}
@@ -0,0 +1,482 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"wails-template/internal/config"
+
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+// writeCertPEM writes the certificate of a TLS test server to a PEM file
+func writeCertPEM(t *testing.T, srv *httptest.Server) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "ca.pem")
+	data := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// newTestHTTPClient builds the client for cfg with its own certificate
+// store
+func newTestHTTPClient(t *testing.T, cfg *config.Config) *http.Client {
+	t.Helper()
+
+	certs, err := newCertificateStore(cfg.TLS)
+	if err != nil {
+		t.Fatalf("newCertificateStore: %v", err)
+	}
+	client, err := newHTTPClient(cfg, certs)
+	if err != nil {
+		t.Fatalf("newHTTPClient: %v", err)
+	}
+	return client
+}
+
+func TestCustomCATrusted(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	cfg := &config.Config{API: config.APIConfig{MaxIdleConn: 1}}
+	client := newTestHTTPClient(t, cfg)
+	if _, err := client.Get(srv.URL); err == nil {
+		t.Fatal("server with an unknown CA was trusted without ca_cert_path")
+	}
+
+	cfg.TLS.CACertPath = writeCertPEM(t, srv)
+	client = newTestHTTPClient(t, cfg)
+	resp, err := client.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("server signed by the extra CA was not trusted: %v", err)
+	}
+	resp.Body.Close()
+}
+
+func TestCustomCAKeepsSystemPool(t *testing.T) {
+	system, err := x509.SystemCertPool()
+	if err != nil || system.Equal(x509.NewCertPool()) {
+		t.Skip("no system certificate pool on this machine")
+	}
+
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	certs, err := newCertificateStore(config.TLSConfig{CACertPath: writeCertPEM(t, srv)})
+	if err != nil {
+		t.Fatalf("newCertificateStore: %v", err)
+	}
+
+	onlyCustom := x509.NewCertPool()
+	onlyCustom.AddCert(srv.Certificate())
+	if certs.roots.Equal(onlyCustom) {
+		t.Error("root pool holds only the custom CA, system roots were dropped")
+	}
+	system.AddCert(srv.Certificate())
+	if !certs.roots.Equal(system) {
+		t.Error("root pool is not the system pool plus the custom CA")
+	}
+}
+
+func TestCustomCAInvalidFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ca.pem")
+	os.WriteFile(path, []byte("not a certificate"), 0600)
+
+	if _, err := newCertificateStore(config.TLSConfig{CACertPath: path}); err == nil {
+		t.Error("newCertificateStore accepted a file without certificates")
+	}
+	if _, err := newCertificateStore(config.TLSConfig{CACertPath: path + ".missing"}); err == nil {
+		t.Error("newCertificateStore accepted a missing file")
+	}
+}
+
+// protoHandler answers with the protocol version of the request
+var protoHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	io.WriteString(w, r.Proto)
+})
+
+// negotiatedProto sends a GET to url with a client built from cfg and
+// returns the protocol of the response
+func negotiatedProto(t *testing.T, cfg *config.Config, url string) string {
+	t.Helper()
+
+	client := newTestHTTPClient(t, cfg)
+	resp, err := client.Get(url)
+	if err != nil {
+		t.Fatalf("GET: %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if resp.Proto != string(body) {
+		t.Errorf("client saw %s, server saw %s", resp.Proto, body)
+	}
+	return resp.Proto
+}
+
+func TestProtocolNegotiationTLS(t *testing.T) {
+	srv := httptest.NewUnstartedServer(protoHandler)
+	srv.EnableHTTP2 = true
+	srv.StartTLS()
+	defer srv.Close()
+
+	cfg := &config.Config{
+		API: config.APIConfig{MaxIdleConn: 1},
+		TLS: config.TLSConfig{CACertPath: writeCertPEM(t, srv)},
+	}
+	if proto := negotiatedProto(t, cfg, srv.URL); proto != "HTTP/2.0" {
+		t.Errorf("default protocol = %s, want HTTP/2.0", proto)
+	}
+
+	cfg.API.ForceHTTP1 = true
+	if proto := negotiatedProto(t, cfg, srv.URL); proto != "HTTP/1.1" {
+		t.Errorf("protocol with force_http1 = %s, want HTTP/1.1", proto)
+	}
+}
+
+func TestProtocolNegotiationCleartext(t *testing.T) {
+	srv := httptest.NewServer(h2c.NewHandler(protoHandler, &http2.Server{}))
+	defer srv.Close()
+
+	cfg := &config.Config{API: config.APIConfig{MaxIdleConn: 1}}
+	if proto := negotiatedProto(t, cfg, srv.URL); proto != "HTTP/1.1" {
+		t.Errorf("default cleartext protocol = %s, want HTTP/1.1", proto)
+	}
+
+	cfg.API.AllowHTTP2Cleartext = true
+	if proto := negotiatedProto(t, cfg, srv.URL); proto != "HTTP/2.0" {
+		t.Errorf("protocol with allow_http2_cleartext = %s, want HTTP/2.0", proto)
+	}
+}
+
+func TestDNSCacheWiredIntoTransport(t *testing.T) {
+	srv := httptest.NewServer(h2c.NewHandler(protoHandler, &http2.Server{}))
+	defer srv.Close()
+	url := strings.Replace(srv.URL, "127.0.0.1", "localhost", 1)
+
+	cfg := &config.Config{API: config.APIConfig{MaxIdleConn: 1, DNSCacheTTL: time.Minute}}
+	negotiatedProto(t, cfg, url)
+
+	cfg.API.AllowHTTP2Cleartext = true
+	if proto := negotiatedProto(t, cfg, url); proto != "HTTP/2.0" {
+		t.Errorf("protocol with the DNS cache = %s, want HTTP/2.0", proto)
+	}
+}
+
+// newLegacyTLSServer starts a TLS server that speaks at most TLS 1.1
+func newLegacyTLSServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	srv.TLS = &tls.Config{MinVersion: tls.VersionTLS10, MaxVersion: tls.VersionTLS11}
+	srv.StartTLS()
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestTLSMinVersionRefusesOlderServer(t *testing.T) {
+	srv := newLegacyTLSServer(t)
+	caPath := writeCertPEM(t, srv)
+
+	for _, version := range []string{"", "1.2", "1.3"} {
+		cfg := &config.Config{
+			API: config.APIConfig{MaxIdleConn: 1},
+			TLS: config.TLSConfig{CACertPath: caPath, MinVersion: version},
+		}
+		client := newTestHTTPClient(t, cfg)
+		if resp, err := client.Get(srv.URL); err == nil {
+			resp.Body.Close()
+			t.Errorf("min_version %q: handshake with a TLS 1.1 server succeeded", version)
+		}
+	}
+}
+
+func TestTLSMinVersionRejectsLegacyVersions(t *testing.T) {
+	for _, version := range []string{"1.0", "1.1"} {
+		if _, err := newTLSConfig(config.TLSConfig{MinVersion: version}, nil); err == nil {
+			t.Errorf("newTLSConfig accepted min_version %s", version)
+		}
+	}
+}
+
+func TestCustomCAChecksHostName(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	cfg := &config.Config{
+		API: config.APIConfig{MaxIdleConn: 1},
+		TLS: config.TLSConfig{CACertPath: writeCertPEM(t, srv)},
+	}
+	client := newTestHTTPClient(t, cfg)
+
+	// The test certificate is valid for 127.0.0.1 and example.com only
+	url := strings.Replace(srv.URL, "127.0.0.1", "localhost", 1)
+	resp, err := client.Get(url)
+	if err == nil {
+		resp.Body.Close()
+		t.Fatal("certificate was accepted for a host it doesn't name")
+	}
+	if !strings.Contains(err.Error(), "not localhost") {
+		t.Errorf("error = %v, want a host name mismatch", err)
+	}
+}
+
+// writeClientCert writes a self-signed client certificate with the given
+// common name and its key to certPath and keyPath
+func writeClientCert(t *testing.T, certPath, keyPath, commonName string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReloadConfigSwapsClientCertificate(t *testing.T) {
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, r.TLS.PeerCertificates[0].Subject.CommonName)
+	}))
+	srv.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	srv.StartTLS()
+	defer srv.Close()
+
+	dir := t.TempDir()
+	certPath, keyPath := filepath.Join(dir, "client.pem"), filepath.Join(dir, "client.key")
+	writeClientCert(t, certPath, keyPath, "client-1")
+
+	path := useConfigCopy(t)
+	values := config.Values{
+		"api": {"base_url": srv.URL},
+		"tls": {
+			"ca_cert_path":     writeCertPEM(t, srv),
+			"client_cert_path": certPath,
+			"client_key_path":  keyPath,
+		},
+	}
+	if err := config.WriteValues(path, values); err != nil {
+		t.Fatal(err)
+	}
+	app, _ := newTestApp(t, http.NotFoundHandler())
+	app.customClient = false
+	app.certs = &certificateStore{}
+
+	// commonName returns the name of the client certificate the server saw
+	commonName := func(client httpClient) string {
+		t.Helper()
+		req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("GET: %v", err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return string(body)
+	}
+
+	if err := app.ReloadConfig(); err != nil {
+		t.Fatalf("ReloadConfig: %v", err)
+	}
+	first := app.client
+	if got := commonName(first); got != "client-1" {
+		t.Fatalf("client certificate = %q, want client-1", got)
+	}
+
+	writeClientCert(t, certPath, keyPath, "client-2")
+	if err := app.ReloadConfig(); err != nil {
+		t.Fatalf("ReloadConfig: %v", err)
+	}
+	if got := commonName(app.client); got != "client-2" {
+		t.Errorf("client certificate after reload = %q, want client-2", got)
+	}
+	// New connections of the previous client use the reloaded certificate
+	if got := commonName(first); got != "client-2" {
+		t.Errorf("previous client certificate after reload = %q, want client-2", got)
+	}
+}
+
+// redirectTestClient returns a client following up to maxRedirects
+func redirectTestClient(t *testing.T, maxRedirects int) *http.Client {
+	t.Helper()
+	return newTestHTTPClient(t, &config.Config{API: config.APIConfig{MaxIdleConn: 1, FollowRedirects: true, MaxRedirects: maxRedirects}})
+}
+
+// getWithAuth sends a GET to url with an Authorization header
+func getWithAuth(t *testing.T, client *http.Client, url string) (*http.Response, error) {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", "Bearer token")
+	return client.Do(req)
+}
+
+func TestRedirectStripsAuthorizationCrossHost(t *testing.T) {
+	var crossHostAuth string
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		crossHostAuth = r.Header.Get("Authorization")
+	}))
+	defer target.Close()
+
+	var sameHostAuth string
+	origin := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/cross":
+			// Same host name, different port: the standard policy would
+			// forward the header
+			http.Redirect(w, r, target.URL+"/landing", http.StatusFound)
+		case "/same":
+			http.Redirect(w, r, "/landing", http.StatusFound)
+		default:
+			sameHostAuth = r.Header.Get("Authorization")
+		}
+	}))
+	defer origin.Close()
+
+	client := redirectTestClient(t, 5)
+	for _, path := range []string{"/cross", "/same"} {
+		resp, err := getWithAuth(t, client, origin.URL+path)
+		if err != nil {
+			t.Fatalf("GET %s: %v", path, err)
+		}
+		resp.Body.Close()
+	}
+	if crossHostAuth != "" {
+		t.Errorf("Authorization after a cross-host redirect = %q, want it dropped", crossHostAuth)
+	}
+	if sameHostAuth != "Bearer token" {
+		t.Errorf("Authorization after a same-host redirect = %q, want it kept", sameHostAuth)
+	}
+}
+
+func TestRedirectStripsConfiguredCredentialsCrossHost(t *testing.T) {
+	var forwarded http.Header
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		forwarded = r.Header.Clone()
+	}))
+	defer target.Close()
+	origin := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, target.URL+"/landing", http.StatusFound)
+	}))
+	defer origin.Close()
+
+	client := newTestHTTPClient(t, &config.Config{
+		API: config.APIConfig{
+			MaxIdleConn:     1,
+			FollowRedirects: true,
+			MaxRedirects:    5,
+			SigningHeader:   "X-Request-Signature",
+			DefaultHeaders:  map[string]string{"x-tenant-key": "tenant-secret"},
+		},
+		Auth: config.AuthConfig{HeaderName: "X-Api-Token"},
+	})
+	req, err := http.NewRequest(http.MethodGet, origin.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("X-Api-Token", "Bearer token")
+	req.Header.Set("X-Request-Signature", "signature")
+	req.Header.Set("X-Tenant-Key", "tenant-secret")
+	req.Header.Set("Accept", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("GET: %v", err)
+	}
+	resp.Body.Close()
+
+	for _, name := range []string{"X-Api-Token", "X-Request-Signature", "X-Tenant-Key"} {
+		if got := forwarded.Get(name); got != "" {
+			t.Errorf("%s after a cross-host redirect = %q, want it dropped", name, got)
+		}
+	}
+	if got := forwarded.Get("Accept"); got != "application/json" {
+		t.Errorf("Accept after a cross-host redirect = %q, want it kept", got)
+	}
+}
+
+func TestRedirectCap(t *testing.T) {
+	hits := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		http.Redirect(w, r, "/next", http.StatusFound)
+	}))
+	defer srv.Close()
+
+	_, err := getWithAuth(t, redirectTestClient(t, 2), srv.URL)
+	if !errors.Is(err, ErrTooManyRedirects) {
+		t.Fatalf("error = %v, want ErrTooManyRedirects", err)
+	}
+	if hits != 3 {
+		t.Errorf("requests = %d, want the original and 2 redirects", hits)
+	}
+}
+
+func TestRedirectNotFollowed(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/next", http.StatusFound)
+	}))
+	defer srv.Close()
+
+	client := newTestHTTPClient(t, &config.Config{API: config.APIConfig{MaxIdleConn: 1}})
+	resp, err := getWithAuth(t, client, srv.URL)
+	if err != nil {
+		t.Fatalf("GET: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusFound {
+		t.Errorf("status = %d, want the redirect response 302", resp.StatusCode)
+	}
+}
+
+func TestTransportIdleAndKeepAlive(t *testing.T) {
+	cfg := &config.Config{API: config.APIConfig{MaxIdleConn: 1, IdleConnTimeout: 15 * time.Second, KeepAlive: 10 * time.Second}}
+	transport := newTestHTTPClient(t, cfg).Transport.(*http.Transport)
+	if transport.IdleConnTimeout != 15*time.Second {
+		t.Errorf("IdleConnTimeout = %v, want 15s", transport.IdleConnTimeout)
+	}
+
+	if got := newDialer(cfg.API).KeepAlive; got != 10*time.Second {
+		t.Errorf("dialer KeepAlive = %v, want 10s", got)
+	}
+	cfg.API.KeepAlive = 0
+	if got := newDialer(cfg.API).KeepAlive; got >= 0 {
+		t.Errorf("dialer KeepAlive with keep_alive = 0 is %v, want probes disabled", got)
+	}
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// defaultUserCacheTTL is how long the current user stays fresh when the
+// cache is disabled
+const defaultUserCacheTTL = time.Minute
+
+// UserResponse represents the API response for the current user
+type UserResponse struct {
+	Code       string `json:"code"`
+	Success    bool   `json:"success"`
+	StatusCode int    `json:"statusCode"`
+	Message    string `json:"message"`
+	Data       User   `json:"data"`
+}
+
+// GetCurrentUser returns the logged-in user, fetching it from the API when
+// the stored copy is older than the cache TTL
+func (a *App) GetCurrentUser() (_ *User, err error) {
+	defer a.recoverPanic("GetCurrentUser", &err)
+
+	a.sessionMu.RLock()
+	if a.session == nil {
+		a.sessionMu.RUnlock()
+		return nil, ErrNotAuthenticated
+	}
+	user, fetched := a.session.user, a.session.userFetched
+	a.sessionMu.RUnlock()
+
+	if user.ID != "" && a.clock.Now().Sub(fetched) < a.userCacheTTL() {
+		return &user, nil
+	}
+	return a.RefreshCurrentUser()
+}
+
+// RefreshCurrentUser fetches the logged-in user from the API, bypassing the
+// stored copy. An unauthorized response triggers one token refresh before
+// giving up.
+func (a *App) RefreshCurrentUser() (_ *User, err error) {
+	defer a.recoverPanic("RefreshCurrentUser", &err)
+
+	ctx := a.requestContext()
+
+	user, err := a.fetchCurrentUser(ctx)
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusUnauthorized {
+		if refreshErr := a.refreshSession(ctx); refreshErr != nil {
+			return nil, fmt.Errorf("failed to refresh session: %w", refreshErr)
+		}
+		user, err = a.fetchCurrentUser(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	a.sessionMu.Lock()
+	defer a.sessionMu.Unlock()
+	if a.session == nil {
+		return nil, ErrNotAuthenticated
+	}
+	a.session.user = *user
+	a.session.userFetched = a.clock.Now()
+	return user, nil
+}
+
+// fetchCurrentUser requests the current user from the API
+func (a *App) fetchCurrentUser(ctx context.Context) (*User, error) {
+	var userResp UserResponse
+	if err := a.doJSON(ctx, http.MethodGet, a.baseURL()+"/identity/me", nil, &userResp); err != nil {
+		return nil, err
+	}
+	if !userResp.Success {
+		return nil, fmt.Errorf("failed to fetch current user: %s", userResp.Message)
+	}
+	return &userResp.Data, nil
+}
+
+// userCacheTTL returns how long the current user stays fresh
+func (a *App) userCacheTTL() time.Duration {
+	cfg := a.currentConfig()
+	if cfg.Cache.Enabled {
+		return cfg.Cache.TTL
+	}
+	return defaultUserCacheTTL
+}
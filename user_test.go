@@ -0,0 +1,126 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+	"wails-template/internal/clock"
+)
+
+// userHandler serves logins, refreshes returning access-2, and
+// /identity/me, which answers 401 for tokens other than validToken
+func userHandler(validToken string, meHits, refreshHits *atomic.Int32) http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/identity/login", loginHandler())
+	mux.HandleFunc("/identity/refresh", func(w http.ResponseWriter, r *http.Request) {
+		refreshHits.Add(1)
+		io.WriteString(w, `{"success":true,"data":{"access_token":"access-2","expires_in":3600}}`)
+	})
+	mux.HandleFunc("/identity/me", func(w http.ResponseWriter, r *http.Request) {
+		meHits.Add(1)
+		if r.Header.Get("Authorization") != "Bearer "+validToken {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		io.WriteString(w, `{"success":true,"data":{"id":"u1","username":"admin","name":"Fetched"}}`)
+	})
+	return mux
+}
+
+// newUserTestApp creates a logged-in app with a fake clock and the user
+// cache TTL at its one minute default
+func newUserTestApp(t *testing.T, handler http.Handler) (*App, *clock.Fake) {
+	t.Helper()
+
+	app, _ := newTestApp(t, handler)
+	fake := clock.NewFake(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	app.clock = fake
+	app.config.Cache.Enabled = false
+	if _, err := app.Login("admin", "secret"); err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	return app, fake
+}
+
+func TestGetCurrentUserCached(t *testing.T) {
+	var meHits, refreshHits atomic.Int32
+	app, _ := newUserTestApp(t, userHandler("access-1", &meHits, &refreshHits))
+
+	user, err := app.GetCurrentUser()
+	if err != nil {
+		t.Fatalf("GetCurrentUser: %v", err)
+	}
+	if user.ID != "u1" || user.Name != "" {
+		t.Errorf("user = %+v, want the user from the login response", user)
+	}
+	if meHits.Load() != 0 {
+		t.Errorf("/identity/me hits = %d, want the cached user", meHits.Load())
+	}
+}
+
+func TestGetCurrentUserFetchesWhenStale(t *testing.T) {
+	var meHits, refreshHits atomic.Int32
+	app, fake := newUserTestApp(t, userHandler("access-1", &meHits, &refreshHits))
+
+	fake.Advance(2 * time.Minute)
+	user, err := app.GetCurrentUser()
+	if err != nil {
+		t.Fatalf("GetCurrentUser: %v", err)
+	}
+	if user.Name != "Fetched" || meHits.Load() != 1 {
+		t.Errorf("user = %+v after %d fetches, want one fetch", user, meHits.Load())
+	}
+
+	// The fetched user is fresh again
+	if _, err := app.GetCurrentUser(); err != nil {
+		t.Fatalf("GetCurrentUser: %v", err)
+	}
+	if meHits.Load() != 1 {
+		t.Errorf("/identity/me hits = %d, want the refetched user cached", meHits.Load())
+	}
+
+	if _, err := app.RefreshCurrentUser(); err != nil {
+		t.Fatalf("RefreshCurrentUser: %v", err)
+	}
+	if meHits.Load() != 2 {
+		t.Errorf("/identity/me hits = %d, RefreshCurrentUser must bypass the cache", meHits.Load())
+	}
+}
+
+func TestRefreshCurrentUserRetriesAfterUnauthorized(t *testing.T) {
+	var meHits, refreshHits atomic.Int32
+	app, _ := newUserTestApp(t, userHandler("access-2", &meHits, &refreshHits))
+
+	user, err := app.RefreshCurrentUser()
+	if err != nil {
+		t.Fatalf("RefreshCurrentUser: %v", err)
+	}
+	if user.Name != "Fetched" {
+		t.Errorf("user = %+v", user)
+	}
+	if meHits.Load() != 2 || refreshHits.Load() != 1 {
+		t.Errorf("me hits = %d, refresh hits = %d, want 2 and 1", meHits.Load(), refreshHits.Load())
+	}
+}
+
+func TestRefreshCurrentUserGivesUpAfterOneRefresh(t *testing.T) {
+	var meHits, refreshHits atomic.Int32
+	app, _ := newUserTestApp(t, userHandler("never", &meHits, &refreshHits))
+
+	if _, err := app.RefreshCurrentUser(); err == nil {
+		t.Fatal("RefreshCurrentUser succeeded although every request is unauthorized")
+	}
+	if meHits.Load() != 2 || refreshHits.Load() != 1 {
+		t.Errorf("me hits = %d, refresh hits = %d, want 2 and 1", meHits.Load(), refreshHits.Load())
+	}
+}
+
+func TestGetCurrentUserLoggedOut(t *testing.T) {
+	app, _ := newTestApp(t, http.NotFoundHandler())
+	if _, err := app.GetCurrentUser(); !errors.Is(err, ErrNotAuthenticated) {
+		t.Errorf("error = %v, want ErrNotAuthenticated", err)
+	}
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"wails-template/internal/config"
+)
+
+// HealthResponse represents the API health endpoint response. The version
+// may be reported at the top level or inside the data envelope.
+type HealthResponse struct {
+	Status  string `json:"status"`
+	Version string `json:"version"`
+	Data    struct {
+		Status  string `json:"status"`
+		Version string `json:"version"`
+	} `json:"data"`
+}
+
+// VersionCheck is the result of comparing the app and API versions
+type VersionCheck struct {
+	Compatible    bool   `json:"compatible"`
+	AppVersion    string `json:"appVersion"`
+	APIVersion    string `json:"apiVersion"`
+	MinAPIVersion string `json:"minApiVersion,omitempty"`
+	Message       string `json:"message"`
+}
+
+// CheckVersionCompatibility fetches the API version from the health endpoint
+// and compares it against the app version. The versions are compatible when
+// they share the same major version and the API meets the configured
+// minimum version.
+func (a *App) CheckVersionCompatibility() (_ *VersionCheck, err error) {
+	defer a.recoverPanic("CheckVersionCompatibility", &err)
+
+	cfg := a.currentConfig()
+
+	var health HealthResponse
+	if err := a.doJSON(a.requestContext(), http.MethodGet, cfg.API.BaseURL+"/health", nil, &health); err != nil {
+		return nil, fmt.Errorf("failed to fetch API version: %w", err)
+	}
+
+	apiVersion := health.Version
+	if apiVersion == "" {
+		apiVersion = health.Data.Version
+	}
+	if apiVersion == "" {
+		return nil, fmt.Errorf("health endpoint did not report a version")
+	}
+
+	return compareAPIVersion(cfg.App.Version, apiVersion, cfg.API.MinVersion)
+}
+
+// compareAPIVersion decides whether the API version is compatible with the
+// app version and the minimum supported API version
+func compareAPIVersion(appVersion, apiVersion, minVersion string) (*VersionCheck, error) {
+	check := &VersionCheck{
+		AppVersion:    appVersion,
+		APIVersion:    apiVersion,
+		MinAPIVersion: minVersion,
+	}
+
+	app, err := config.ParseSemver(appVersion)
+	if err != nil {
+		return nil, err
+	}
+	api, err := config.ParseSemver(apiVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	if minVersion != "" {
+		minimum, err := config.ParseSemver(minVersion)
+		if err != nil {
+			return nil, err
+		}
+		if api.Compare(minimum) < 0 {
+			check.Message = fmt.Sprintf("API version %s is older than the minimum supported version %s", apiVersion, minVersion)
+			return check, nil
+		}
+	}
+
+	if app.Major != api.Major {
+		check.Message = fmt.Sprintf("API version %s is not compatible with app version %s", apiVersion, appVersion)
+		return check, nil
+	}
+
+	check.Compatible = true
+	switch cmp := api.Compare(app); {
+	case cmp > 0:
+		check.Message = fmt.Sprintf("API version %s is newer than app version %s, consider updating the app", apiVersion, appVersion)
+	case cmp < 0:
+		check.Message = fmt.Sprintf("API version %s is older than app version %s, some features may be unavailable", apiVersion, appVersion)
+	default:
+		check.Message = "App and API versions match"
+	}
+	return check, nil
+}
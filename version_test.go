@@ -0,0 +1,85 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"testing"
+)
+
+func TestCompareAPIVersion(t *testing.T) {
+	tests := []struct {
+		name       string
+		app        string
+		api        string
+		minVersion string
+		compatible bool
+		message    string
+	}{
+		{"equal", "1.2.0", "1.2.0", "", true, "App and API versions match"},
+		{"newer backend", "1.2.0", "1.4.1", "", true, "API version 1.4.1 is newer than app version 1.2.0, consider updating the app"},
+		{"older backend", "1.2.0", "1.1.9", "", true, "API version 1.1.9 is older than app version 1.2.0, some features may be unavailable"},
+		{"major mismatch", "1.2.0", "2.0.0", "", false, "API version 2.0.0 is not compatible with app version 1.2.0"},
+		{"below minimum", "1.2.0", "1.1.0", "1.1.5", false, "API version 1.1.0 is older than the minimum supported version 1.1.5"},
+		{"meets minimum", "1.2.0", "1.1.5", "1.1.5", true, "API version 1.1.5 is older than app version 1.2.0, some features may be unavailable"},
+		{"pre-release", "1.2.0", "1.2.0-rc.1", "", true, "API version 1.2.0-rc.1 is older than app version 1.2.0, some features may be unavailable"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			check, err := compareAPIVersion(tt.app, tt.api, tt.minVersion)
+			if err != nil {
+				t.Fatalf("compareAPIVersion: %v", err)
+			}
+			if check.Compatible != tt.compatible || check.Message != tt.message {
+				t.Errorf("got compatible=%v %q, want compatible=%v %q", check.Compatible, check.Message, tt.compatible, tt.message)
+			}
+		})
+	}
+}
+
+func TestCompareAPIVersionInvalid(t *testing.T) {
+	for _, versions := range [][3]string{
+		{"1.2", "1.2.0", ""},
+		{"1.2.0", "latest", ""},
+		{"1.2.0", "1.2.0", "v1"},
+	} {
+		if _, err := compareAPIVersion(versions[0], versions[1], versions[2]); err == nil {
+			t.Errorf("compareAPIVersion(%q, %q, %q) accepted an invalid version", versions[0], versions[1], versions[2])
+		}
+	}
+}
+
+func TestCheckVersionCompatibility(t *testing.T) {
+	for name, body := range map[string]string{
+		"top level": `{"status":"ok","version":"1.3.0"}`,
+		"envelope":  `{"data":{"status":"ok","version":"1.3.0"}}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			app, _ := newTestApp(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/health" {
+					http.NotFound(w, r)
+					return
+				}
+				io.WriteString(w, body)
+			}))
+			app.config.App.Version = "1.2.0"
+			app.config.API.MinVersion = ""
+
+			check, err := app.CheckVersionCompatibility()
+			if err != nil {
+				t.Fatalf("CheckVersionCompatibility: %v", err)
+			}
+			if !check.Compatible || check.APIVersion != "1.3.0" {
+				t.Errorf("check = %+v", check)
+			}
+		})
+	}
+}
+
+func TestCheckVersionCompatibilityNoVersion(t *testing.T) {
+	app, _ := newTestApp(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"status":"ok"}`)
+	}))
+	if _, err := app.CheckVersionCompatibility(); err == nil {
+		t.Error("CheckVersionCompatibility succeeded without a reported version")
+	}
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"wails-template/internal/config"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// windowRuntime is the part of the Wails runtime used to place the window
+type windowRuntime interface {
+	ScreenGetAll() ([]runtime.Screen, error)
+	WindowGetSize() (int, int)
+	WindowSetPosition(x, y int)
+	WindowCenter()
+}
+
+// wailsWindow calls the Wails runtime for the window of ctx
+type wailsWindow struct {
+	ctx context.Context
+}
+
+func (w wailsWindow) ScreenGetAll() ([]runtime.Screen, error) { return runtime.ScreenGetAll(w.ctx) }
+func (w wailsWindow) WindowGetSize() (int, int)               { return runtime.WindowGetSize(w.ctx) }
+func (w wailsWindow) WindowSetPosition(x, y int)              { runtime.WindowSetPosition(w.ctx, x, y) }
+func (w wailsWindow) WindowCenter()                           { runtime.WindowCenter(w.ctx) }
+
+// placeWindow centers the window on the monitor selected by window.monitor
+// when window.center is enabled. Wails reports screens without their
+// offsets and positions the window relative to the monitor it is on, so
+// the position is derived from the size of the selected monitor. The
+// current monitor is left to the platform's own centering.
+func (a *App) placeWindow(rt windowRuntime, cfg config.WindowConfig) error {
+	if !cfg.Center || cfg.Fullscreen || cfg.Maximized || cfg.Minimized {
+		return nil
+	}
+
+	screens, err := rt.ScreenGetAll()
+	if err != nil {
+		return fmt.Errorf("failed to list screens: %w", err)
+	}
+	if cfg.Monitor >= len(screens) {
+		a.logger.Warn("Window monitor not found, using the primary monitor", "monitor", cfg.Monitor, "screens", len(screens))
+	}
+	screen, ok := selectScreen(screens, cfg.Monitor)
+	if !ok || screen.IsCurrent {
+		rt.WindowCenter()
+		return nil
+	}
+
+	width, height := rt.WindowGetSize()
+	rt.WindowSetPosition(max(screen.Size.Width-width, 0)/2, max(screen.Size.Height-height, 0)/2)
+	return nil
+}
+
+// selectScreen returns the screen with the given index, or the primary
+// screen when there is no such screen. It reports false when there is no
+// primary screen to fall back to either.
+func selectScreen(screens []runtime.Screen, index int) (runtime.Screen, bool) {
+	if index >= 0 && index < len(screens) {
+		return screens[index], true
+	}
+	for _, screen := range screens {
+		if screen.IsPrimary {
+			return screen, true
+		}
+	}
+	return runtime.Screen{}, false
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"wails-template/internal/config"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// fakeWindow records how the window was placed
+type fakeWindow struct {
+	screens  []runtime.Screen
+	err      error
+	centered bool
+	position []int
+}
+
+func (w *fakeWindow) ScreenGetAll() ([]runtime.Screen, error) { return w.screens, w.err }
+func (w *fakeWindow) WindowGetSize() (int, int)               { return 1200, 800 }
+func (w *fakeWindow) WindowSetPosition(x, y int)              { w.position = []int{x, y} }
+func (w *fakeWindow) WindowCenter()                           { w.centered = true }
+
+// newScreen returns a screen of the given size
+func newScreen(width, height int, current, primary bool) runtime.Screen {
+	screen := runtime.Screen{IsCurrent: current, IsPrimary: primary}
+	screen.Size.Width, screen.Size.Height = width, height
+	return screen
+}
+
+// testScreens returns a current screen, a primary one and a third one
+func testScreens() []runtime.Screen {
+	return []runtime.Screen{
+		newScreen(1920, 1080, true, false),
+		newScreen(2560, 1440, false, true),
+		newScreen(1600, 900, false, false),
+	}
+}
+
+func TestSelectScreen(t *testing.T) {
+	screens := testScreens()
+	tests := []struct {
+		index int
+		want  int // index into screens, -1 for none
+	}{
+		{0, 0},
+		{2, 2},
+		{3, 1},  // out of range falls back to the primary screen
+		{-1, 1}, // so does a negative index
+	}
+	for _, tt := range tests {
+		got, ok := selectScreen(screens, tt.index)
+		if !ok || got != screens[tt.want] {
+			t.Errorf("selectScreen(%d) = %+v, %v, want screen %d", tt.index, got, ok, tt.want)
+		}
+	}
+
+	if _, ok := selectScreen(screens[2:], 5); ok {
+		t.Error("selectScreen without a primary screen reported a screen")
+	}
+}
+
+func TestPlaceWindow(t *testing.T) {
+	app, _ := newTestApp(t, http.NotFoundHandler())
+	tests := []struct {
+		name         string
+		cfg          config.WindowConfig
+		wantCentered bool
+		wantPosition []int
+	}{
+		{"disabled", config.WindowConfig{Monitor: 2}, false, nil},
+		{"current monitor", config.WindowConfig{Center: true}, true, nil},
+		{"other monitor", config.WindowConfig{Center: true, Monitor: 2}, false, []int{200, 50}},
+		{"unknown monitor", config.WindowConfig{Center: true, Monitor: 7}, false, []int{680, 320}},
+		{"maximized", config.WindowConfig{Center: true, Monitor: 2, Maximized: true}, false, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rt := &fakeWindow{screens: testScreens()}
+			if err := app.placeWindow(rt, tt.cfg); err != nil {
+				t.Fatalf("placeWindow: %v", err)
+			}
+			if rt.centered != tt.wantCentered || len(rt.position) != len(tt.wantPosition) ||
+				(tt.wantPosition != nil && (rt.position[0] != tt.wantPosition[0] || rt.position[1] != tt.wantPosition[1])) {
+				t.Errorf("centered = %v, position = %v, want %v, %v", rt.centered, rt.position, tt.wantCentered, tt.wantPosition)
+			}
+		})
+	}
+}
+
+func TestPlaceWindowScreenError(t *testing.T) {
+	app, _ := newTestApp(t, http.NotFoundHandler())
+	rt := &fakeWindow{err: errors.New("no display")}
+
+	if err := app.placeWindow(rt, config.WindowConfig{Center: true}); err == nil {
+		t.Error("placeWindow succeeded without screens")
+	}
+	if rt.centered || rt.position != nil {
+		t.Error("window was moved without screens")
+	}
+}